@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// localBackend örnekleri bir depo üzerinde kurulan motorla denetler. Sinyal ve
+// yeniden deneme örneği bu süreçte ilerletir; ilerleyen adımların fonksiyonları
+// eklentilerle kaydedilmiş olmalıdır.
+type localBackend struct {
+	engine *engine.WorkflowEngine
+}
+
+// newLocalBackend depo üzerinde bir motor kurar ve adım fonksiyonlarını kaydeder
+func newLocalBackend(store engine.WorkflowStore, registrars []registrar) *localBackend {
+	e := engine.NewWorkflowEngine(engine.WithStore(store))
+	for _, register := range registrars {
+		register(e)
+	}
+	return &localBackend{engine: e}
+}
+
+func (b *localBackend) list(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error) {
+	return b.engine.Store().ListInstances(ctx, filter)
+}
+
+func (b *localBackend) get(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	return b.engine.Store().GetInstance(ctx, id)
+}
+
+func (b *localBackend) definition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error) {
+	return b.engine.Store().GetDefinition(ctx, id, version)
+}
+
+func (b *localBackend) cancel(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	return b.after(ctx, id, b.engine.Cancel(ctx, id))
+}
+
+func (b *localBackend) signal(ctx context.Context, id, name string, payload interface{}) (*engine.InstanceRecord, error) {
+	return b.after(ctx, id, b.engine.Signal(ctx, id, name, payload))
+}
+
+func (b *localBackend) retry(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	return b.after(ctx, id, b.engine.Retry(ctx, id))
+}
+
+// after işlemden sonra örneğin kaydını döndürür; işlem hatası önceliklidir
+func (b *localBackend) after(ctx context.Context, id string, err error) (*engine.InstanceRecord, error) {
+	if err != nil {
+		return nil, err
+	}
+	return b.get(ctx, id)
+}
+
+// apiBackend örnekleri maestro HTTP API'si üzerinden denetler
+type apiBackend struct {
+	base   string
+	client *http.Client
+}
+
+// apiPageSize API'den tek istekte istenen kayıt sayısıdır; sunucunun sayfa sınırıdır
+const apiPageSize = 500
+
+// newAPIBackend base adresindeki API için bir istemci oluşturur; client nil ise
+// http.DefaultClient kullanılır
+func newAPIBackend(base string, client *http.Client) *apiBackend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &apiBackend{base: strings.TrimRight(base, "/"), client: client}
+}
+
+// apiInstanceList GET /instances yanıtıdır
+type apiInstanceList struct {
+	Instances  []*engine.InstanceRecord `json:"instances"`
+	NextOffset *int                     `json:"next_offset"`
+}
+
+func (b *apiBackend) list(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error) {
+	query := url.Values{}
+	if filter.WorkflowID != "" {
+		query.Set("workflow_id", filter.WorkflowID)
+	}
+	if filter.IdempotencyKey != "" {
+		query.Set("idempotency_key", filter.IdempotencyKey)
+	}
+	for _, status := range filter.Statuses {
+		query.Add("status", string(status))
+	}
+	query.Set("limit", strconv.Itoa(apiPageSize))
+
+	records := make([]*engine.InstanceRecord, 0)
+	for offset := 0; ; {
+		query.Set("offset", strconv.Itoa(offset))
+		var page apiInstanceList
+		if err := b.do(ctx, http.MethodGet, "/instances?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		records = append(records, page.Instances...)
+		if page.NextOffset == nil {
+			return records, nil
+		}
+		offset = *page.NextOffset
+	}
+}
+
+func (b *apiBackend) get(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	var record engine.InstanceRecord
+	if err := b.do(ctx, http.MethodGet, "/instances/"+url.PathEscape(id), nil, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (b *apiBackend) definition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error) {
+	var definition engine.WorkflowDefinition
+	path := "/definitions/" + url.PathEscape(id) + "?version=" + strconv.Itoa(version)
+	if err := b.do(ctx, http.MethodGet, path, nil, &definition); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+func (b *apiBackend) cancel(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	return b.control(ctx, id, "cancel", nil)
+}
+
+func (b *apiBackend) signal(ctx context.Context, id, name string, payload interface{}) (*engine.InstanceRecord, error) {
+	return b.control(ctx, id, "signals/"+url.PathEscape(name), payload)
+}
+
+func (b *apiBackend) retry(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	return b.control(ctx, id, "retry", nil)
+}
+
+// control örnek üzerinde bir POST işlemi yapar ve dönen kaydı çözer
+func (b *apiBackend) control(ctx context.Context, id, action string, body interface{}) (*engine.InstanceRecord, error) {
+	var record engine.InstanceRecord
+	if err := b.do(ctx, http.MethodPost, "/instances/"+url.PathEscape(id)+"/"+action, body, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// apiError API'nin hata yanıtıdır; 404 yanıtları engine.ErrNotFound ile eşleşir
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.message, e.status)
+}
+
+func (e *apiError) Unwrap() error {
+	if e.status == http.StatusNotFound {
+		return engine.ErrNotFound
+	}
+	return nil
+}
+
+// do isteği gönderir ve başarılı yanıtı out'a çözer; hata yanıtları
+// {"error": "..."} gövdesinden apiError olarak okunur
+func (b *apiBackend) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.base+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) != nil || failure.Error == "" {
+			failure.Error = resp.Status
+		}
+		return &apiError{status: resp.StatusCode, message: failure.Error}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("API yanıtı çözülemedi: %w", err)
+	}
+	return nil
+}
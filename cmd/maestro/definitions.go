@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// loadDefinition tanımı JSON dosyasından okur; "-" standart girdidir
+func loadDefinition(path string) (*engine.WorkflowDefinition, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var definition engine.WorkflowDefinition
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, fmt.Errorf("%s: tanım okunamadı: %w", path, err)
+	}
+	if definition.ID == "" {
+		return nil, fmt.Errorf("%s: tanım kimliği boş", path)
+	}
+	if definition.Version == 0 {
+		definition.Version = 1
+	}
+	return &definition, nil
+}
+
+// readInput dosyanın içeriğini okur; "-" standart girdidir
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// validateCommand tanımları Validate ile doğrular; hatalı tanım varsa başarısız olur
+func validateCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("validate", "<tanım.json>...", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, -1); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, path := range fs.Args() {
+		definition, err := loadDefinition(path)
+		if err == nil {
+			if verr := definition.Validate(); verr != nil {
+				err = fmt.Errorf("%s: %w", path, verr)
+			}
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintln(stdout, indentErrors(err))
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: geçerli (%s, sürüm %d, %d adım)\n", path, definition.ID, definition.Version, len(definition.Steps))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d tanım geçersiz", failed)
+	}
+	return nil
+}
+
+// lintCommand tanımları doğrular ve Validate'in reddetmediği olası hataları
+// uyarı olarak yazar; hata veya uyarı varsa başarısız olur
+func lintCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("lint", "<tanım.json>...", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, -1); err != nil {
+		return err
+	}
+
+	problems := 0
+	for _, path := range fs.Args() {
+		definition, err := loadDefinition(path)
+		if err != nil {
+			problems++
+			fmt.Fprintln(stdout, indentErrors(err))
+			continue
+		}
+		if err := definition.Validate(); err != nil {
+			problems++
+			fmt.Fprintln(stdout, indentErrors(fmt.Errorf("%s: hata: %w", path, err)))
+		}
+		for _, warning := range lint(definition) {
+			problems++
+			fmt.Fprintf(stdout, "%s: uyarı: %s\n", path, warning)
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d sorun bulundu", problems)
+	}
+	return nil
+}
+
+// graphCommand tanımın adım grafiğini DOT veya Mermaid biçiminde yazar. -instance
+// verildiğinde düğümler örneğin adım durumlarına göre boyanır; tanım dosyası
+// verilmemişse örneğin çalıştığı tanım sürümü kaynaktan okunur.
+func graphCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("graph", "[-format dot|mermaid] [-instance id -store dizin | -api URL] [tanım.json]", stderr)
+	format := fs.String("format", "dot", "çıktı biçimi: dot veya mermaid")
+	instanceID := fs.String("instance", "", "düğümleri bu örneğin adım durumlarına göre boyar")
+	var source backendFlags
+	source.registerSource(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	minArgs := 1
+	if *instanceID != "" {
+		minArgs = 0
+	}
+	if err := requireArgs(fs, minArgs, 1); err != nil {
+		return err
+	}
+	if *format != "dot" && *format != "mermaid" {
+		return fmt.Errorf("bilinmeyen biçim: %s", *format)
+	}
+
+	var definition *engine.WorkflowDefinition
+	if fs.NArg() == 1 {
+		var err error
+		if definition, err = loadDefinition(fs.Arg(0)); err != nil {
+			return err
+		}
+	}
+
+	var opts []engine.GraphOption
+	if *instanceID != "" {
+		backend, err := source.open()
+		if err != nil {
+			return err
+		}
+		record, err := backend.get(ctx, *instanceID)
+		if err != nil {
+			return err
+		}
+		if definition == nil {
+			if definition, err = backend.definition(ctx, record.WorkflowID, record.Version); err != nil {
+				return err
+			}
+		} else if definition.ID != record.WorkflowID {
+			return fmt.Errorf("örnek %s tanımına ait, dosyadaki tanım %s", record.WorkflowID, definition.ID)
+		}
+		opts = append(opts, engine.WithStatusOverlay(record.State))
+	}
+
+	graph := definition.ToDOT(opts...)
+	if *format == "mermaid" {
+		graph = definition.ToMermaid(opts...)
+	}
+	_, err := io.WriteString(stdout, graph)
+	return err
+}
+
+// indentErrors errors.Join ile birleşmiş hataları satır satır girintili yazar
+func indentErrors(err error) string {
+	return strings.ReplaceAll(err.Error(), "\n", "\n  ")
+}
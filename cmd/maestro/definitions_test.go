@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+func TestValidateValidDefinition(t *testing.T) {
+	path := writeDefinition(t, orderDefinition())
+
+	code, stdout, stderr := execute(t, "validate", path)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "geçerli") || !strings.Contains(stdout, "3 adım") {
+		t.Errorf("Expected a success line, got %q", stdout)
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	// Bilinmeyen iki adıma bağlanan tanım
+	definition := engine.NewWorkflowDefinition("broken", "Broken", "")
+	definition.AddStep(engine.NewStepDefinition("a", "A", engine.StepTypeTask).WithNextSteps("missing"))
+	definition.AddStep(engine.NewStepDefinition("b", "B", engine.StepTypeTask).WithNextSteps("gone"))
+	broken := writeDefinition(t, definition)
+	valid := writeDefinition(t, orderDefinition())
+
+	code, stdout, _ := execute(t, "validate", valid, broken)
+	if code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	for _, want := range []string{"missing", "gone", "geçerli"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in output, got %q", want, stdout)
+		}
+	}
+}
+
+func TestValidateRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "typo.json")
+	data := `{"id": "typo", "steps": [{"id": "a", "type": "task", "nextSteps": ["b"]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	code, stdout, _ := execute(t, "validate", path)
+	if code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout, "nextSteps") {
+		t.Errorf("Expected the unknown field in the error, got %q", stdout)
+	}
+}
+
+func TestLintReportsWarnings(t *testing.T) {
+	definition := orderDefinition()
+	definition.AddStep(engine.NewStepDefinition("orphan", "", engine.StepTypeTask))
+	path := writeDefinition(t, definition)
+
+	code, stdout, _ := execute(t, "lint", path)
+	if code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	for _, want := range []string{"orphan ilk adımdan ulaşılamıyor", "adım orphan: adı yok"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in output, got %q", want, stdout)
+		}
+	}
+}
+
+func TestLintCleanDefinition(t *testing.T) {
+	path := writeDefinition(t, orderDefinition())
+
+	code, stdout, _ := execute(t, "lint", path)
+	if code != 0 {
+		t.Errorf("Expected exit code 0, got %d: %s", code, stdout)
+	}
+	if stdout != "" {
+		t.Errorf("Expected no output, got %q", stdout)
+	}
+}
+
+func TestGraphWritesDOT(t *testing.T) {
+	path := writeDefinition(t, orderDefinition())
+
+	code, stdout, stderr := execute(t, "graph", path)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{`digraph "order"`, `"prepare" -> "approval"`, `"approval" -> "ship"`, "shape=cds"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in output, got %q", want, stdout)
+		}
+	}
+
+	code, stdout, _ = execute(t, "graph", "-format", "mermaid", path)
+	if code != 0 || !strings.HasPrefix(stdout, "flowchart TD") {
+		t.Errorf("Expected a Mermaid flowchart, got %d: %q", code, stdout)
+	}
+
+	if code, _, _ = execute(t, "graph", "-format", "svg", path); code != 1 {
+		t.Errorf("Expected exit code 1 for an unknown format, got %d", code)
+	}
+}
+
+func TestGraphInstanceOverlay(t *testing.T) {
+	dir := t.TempDir()
+	code, stdout, stderr := execute(t, "run", "-store", dir, writeDefinition(t, approvalDefinition()))
+	if code != 0 {
+		t.Fatalf("run failed with %d: %s", code, stderr)
+	}
+	id := decodeRecord(t, stdout).ID
+
+	// Tanım dosyası verilmediğinde örneğin tanımı depodan okunur
+	code, stdout, stderr = execute(t, "graph", "-store", dir, "-instance", id)
+	if code != 0 {
+		t.Fatalf("graph failed with %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, `"approval" [label="Approval\n(approval)", shape=cds, style=filled, fillcolor="#fff3cd"`) {
+		t.Errorf("Expected the waiting step to be colored, got %q", stdout)
+	}
+
+	// Başka bir tanımın dosyası reddedilir
+	code, _, stderr = execute(t, "graph", "-store", dir, "-instance", id, writeDefinition(t, orderDefinition()))
+	if code != 1 || !strings.Contains(stderr, "approval") {
+		t.Errorf("Expected a definition mismatch error, got %d: %q", code, stderr)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/filestore"
+)
+
+// instanceBackend örneklerin okunduğu ve denetlendiği kaynaktır: yerel bir depo
+// dizini veya çalışan bir sunucunun HTTP API'si. Denetim işlemleri örneğin
+// işlemden sonraki kaydını döndürür.
+type instanceBackend interface {
+	list(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error)
+	get(ctx context.Context, id string) (*engine.InstanceRecord, error)
+	definition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error)
+	cancel(ctx context.Context, id string) (*engine.InstanceRecord, error)
+	signal(ctx context.Context, id, name string, payload interface{}) (*engine.InstanceRecord, error)
+	retry(ctx context.Context, id string) (*engine.InstanceRecord, error)
+}
+
+// instanceCommands instances altındaki komutlardır
+var instanceCommands = []command{
+	{name: "list", summary: "örnekleri listeler", run: listInstancesCommand},
+	{name: "show", summary: "örneğin kaydını yazar", run: showInstanceCommand},
+	{name: "cancel", summary: "örneği iptal eder", run: cancelInstanceCommand},
+	{name: "signal", summary: "örneğe sinyal gönderir", run: signalInstanceCommand},
+	{name: "retry", summary: "başarısız örneği başarısız adımından yeniden dener", run: retryInstanceCommand},
+}
+
+// instancesCommand örnek komutunu çalıştırır
+func instancesCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		for _, cmd := range instanceCommands {
+			if cmd.name == args[0] {
+				return cmd.run(ctx, args[1:], stdout, stderr)
+			}
+		}
+		if args[0] != "-h" && args[0] != "-help" {
+			fmt.Fprintf(stderr, "maestro instances: bilinmeyen komut: %s\n\n", args[0])
+		}
+	}
+
+	fmt.Fprintln(stderr, "Kullanım: maestro instances <komut> [-store dizin | -api URL] [argümanlar]")
+	fmt.Fprintln(stderr)
+	fmt.Fprintln(stderr, "Komutlar:")
+	for _, cmd := range instanceCommands {
+		fmt.Fprintf(stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	return errUsage
+}
+
+// backendFlags örnek komutlarının ortak seçenekleridir
+type backendFlags struct {
+	store   string
+	api     string
+	plugins stringList
+}
+
+// register seçenekleri kümeye ekler
+func (f *backendFlags) register(fs *flag.FlagSet) {
+	f.registerSource(fs)
+	fs.Var(&f.plugins, "plugin", "-store ile ilerletilen örneklerin adım fonksiyonlarını dışa açan Go eklentisi; tekrarlanabilir")
+}
+
+// registerSource yalnızca kaynak seçeneklerini kümeye ekler; örneği ilerletmeyen
+// komutlar eklenti almaz
+func (f *backendFlags) registerSource(fs *flag.FlagSet) {
+	fs.StringVar(&f.store, "store", "", "örneklerin bulunduğu filestore dizini")
+	fs.StringVar(&f.api, "api", "", "maestro HTTP API'sinin adresi (ör. http://localhost:8080/api)")
+}
+
+// open seçeneklere göre örneklerin kaynağını açar
+func (f *backendFlags) open() (instanceBackend, error) {
+	switch {
+	case f.store != "" && f.api != "":
+		return nil, fmt.Errorf("-store ve -api birlikte verilemez")
+	case f.api != "":
+		if len(f.plugins) > 0 {
+			return nil, fmt.Errorf("-plugin yalnızca -store ile kullanılabilir")
+		}
+		return newAPIBackend(f.api, nil), nil
+	case f.store != "":
+		store, err := filestore.New(f.store)
+		if err != nil {
+			return nil, err
+		}
+		registrars, err := loadPlugins(f.plugins)
+		if err != nil {
+			return nil, err
+		}
+		return newLocalBackend(store, registrars), nil
+	default:
+		return nil, fmt.Errorf("-store veya -api verilmelidir")
+	}
+}
+
+// listInstancesCommand filtreye uyan örnekleri tablo veya JSON olarak yazar
+func listInstancesCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("instances list", "[-store dizin | -api URL] [-workflow id] [-status durum,...]", stderr)
+	var backendFlags backendFlags
+	backendFlags.register(fs)
+	workflowID := fs.String("workflow", "", "yalnızca bu tanımın örnekleri")
+	statuses := fs.String("status", "", "virgülle ayrılmış durumlar (ör. failed,waiting)")
+	asJSON := fs.Bool("json", false, "kayıtları JSON olarak yazar")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 0, 0); err != nil {
+		return err
+	}
+	backend, err := backendFlags.open()
+	if err != nil {
+		return err
+	}
+
+	filter := engine.InstanceFilter{WorkflowID: *workflowID}
+	for _, status := range strings.Split(*statuses, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, engine.WorkflowStatus(status))
+		}
+	}
+	records, err := backend.list(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(stdout, records)
+	}
+	return printInstances(stdout, records)
+}
+
+// printInstances kayıtları hizalı bir tablo olarak yazar
+func printInstances(w io.Writer, records []*engine.InstanceRecord) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tWORKFLOW\tVERSION\tSTATUS\tSTEP\tUPDATED")
+	for _, record := range records {
+		step := record.State.CurrentStepID
+		if step == "" {
+			step = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			record.ID, record.WorkflowID, record.Version, record.State.Status, step,
+			record.UpdatedAt.Local().Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+// showInstanceCommand örneğin kaydını JSON olarak yazar
+func showInstanceCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	return instanceAction(ctx, "show", "<örnek>", args, 1, 1, stdout, stderr,
+		func(backend instanceBackend, args []string) (*engine.InstanceRecord, error) {
+			return backend.get(ctx, args[0])
+		})
+}
+
+// cancelInstanceCommand örneği iptal eder ve kaydını yazar
+func cancelInstanceCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	return instanceAction(ctx, "cancel", "<örnek>", args, 1, 1, stdout, stderr,
+		func(backend instanceBackend, args []string) (*engine.InstanceRecord, error) {
+			return backend.cancel(ctx, args[0])
+		})
+}
+
+// signalInstanceCommand örneğe sinyal gönderir; verilmişse üçüncü argüman
+// sinyalin JSON verisidir
+func signalInstanceCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	return instanceAction(ctx, "signal", "<örnek> <sinyal> [JSON veri]", args, 2, 3, stdout, stderr,
+		func(backend instanceBackend, args []string) (*engine.InstanceRecord, error) {
+			var payload interface{}
+			if len(args) > 2 {
+				if err := json.Unmarshal([]byte(args[2]), &payload); err != nil {
+					return nil, fmt.Errorf("sinyal verisi JSON değil: %w", err)
+				}
+			}
+			return backend.signal(ctx, args[0], args[1], payload)
+		})
+}
+
+// retryInstanceCommand başarısız örneği yeniden dener ve kaydını yazar
+func retryInstanceCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	return instanceAction(ctx, "retry", "<örnek>", args, 1, 1, stdout, stderr,
+		func(backend instanceBackend, args []string) (*engine.InstanceRecord, error) {
+			return backend.retry(ctx, args[0])
+		})
+}
+
+// instanceAction tek bir örnek üzerinde çalışan komutların ortak akışıdır:
+// seçenekleri okur, kaynağı açar, işlemi uygular ve dönen kaydı yazar. Komut
+// en az min, en fazla max argüman alır.
+func instanceAction(ctx context.Context, name, synopsis string, args []string, min, max int, stdout, stderr io.Writer,
+	action func(backend instanceBackend, args []string) (*engine.InstanceRecord, error)) error {
+	fs := newFlagSet("instances "+name, "[-store dizin | -api URL] "+synopsis, stderr)
+	var backendFlags backendFlags
+	backendFlags.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, min, max); err != nil {
+		return err
+	}
+	backend, err := backendFlags.open()
+	if err != nil {
+		return err
+	}
+	record, err := action(backend, fs.Args())
+	if err != nil {
+		return err
+	}
+	return printJSON(stdout, record)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/server"
+)
+
+// approvalDefinition yalnızca onay sinyalini bekler; adım fonksiyonu gerektirmez
+func approvalDefinition() *engine.WorkflowDefinition {
+	definition := engine.NewWorkflowDefinition("approval", "Approval", "")
+	definition.AddStep(engine.NewStepDefinition("approval", "Approval", engine.StepTypeSignal).
+		WithConfig(map[string]interface{}{engine.SignalConfigName: "approval"}))
+	return definition
+}
+
+// decodeRecord komut çıktısındaki örnek kaydını çözer
+func decodeRecord(t *testing.T, output string) engine.InstanceRecord {
+	t.Helper()
+	var record engine.InstanceRecord
+	if err := json.Unmarshal([]byte(output), &record); err != nil {
+		t.Fatalf("Expected a record as JSON, got %q: %v", output, err)
+	}
+	return record
+}
+
+func TestInstancesAgainstStore(t *testing.T) {
+	dir := t.TempDir()
+	path := writeDefinition(t, approvalDefinition())
+
+	code, stdout, stderr := execute(t, "run", "-store", dir, path)
+	if code != 0 {
+		t.Fatalf("run failed with %d: %s", code, stderr)
+	}
+	id := decodeRecord(t, stdout).ID
+
+	code, stdout, stderr = execute(t, "instances", "list", "-store", dir, "-status", "waiting")
+	if code != 0 {
+		t.Fatalf("list failed with %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, id) || !strings.Contains(stdout, "waiting") {
+		t.Errorf("Expected the waiting instance in the list, got %q", stdout)
+	}
+
+	code, stdout, stderr = execute(t, "instances", "signal", "-store", dir, id, "approval", `{"approved": true}`)
+	if code != 0 {
+		t.Fatalf("signal failed with %d: %s", code, stderr)
+	}
+	record := decodeRecord(t, stdout)
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed after the signal, got %s", record.State.Status)
+	}
+
+	code, stdout, _ = execute(t, "instances", "show", "-store", dir, id)
+	if code != 0 || decodeRecord(t, stdout).State.Status != engine.StatusCompleted {
+		t.Errorf("Expected show to return the completed record, got %d: %q", code, stdout)
+	}
+
+	// Tamamlanmış örnek iptal edilemez
+	code, _, stderr = execute(t, "instances", "cancel", "-store", dir, id)
+	if code != 1 || stderr == "" {
+		t.Errorf("Expected cancel of a completed instance to fail, got %d: %q", code, stderr)
+	}
+}
+
+func TestInstancesRequireBackend(t *testing.T) {
+	code, _, stderr := execute(t, "instances", "list")
+	if code != 1 || !strings.Contains(stderr, "-store veya -api") {
+		t.Errorf("Expected a missing backend error, got %d: %q", code, stderr)
+	}
+
+	code, _, _ = execute(t, "instances", "show", "-store", t.TempDir())
+	if code != 2 {
+		t.Errorf("Expected exit code 2 without an instance ID, got %d", code)
+	}
+}
+
+func TestAPIBackend(t *testing.T) {
+	e := engine.NewWorkflowEngine()
+	orderSteps(e)
+	ctx := context.Background()
+	if err := e.RegisterDefinition(ctx, orderDefinition()); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	api := httptest.NewServer(server.NewHandler(e))
+	t.Cleanup(api.Close)
+
+	runtime, err := e.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	backend := newAPIBackend(api.URL+"/", nil)
+
+	records, err := backend.list(ctx, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusWaiting}})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(records) != 1 || records[0].ID != runtime.ID() {
+		t.Fatalf("Expected the waiting instance, got %v", records)
+	}
+
+	record, err := backend.signal(ctx, runtime.ID(), "approval", map[string]interface{}{"approved": true})
+	if err != nil {
+		t.Fatalf("signal failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed after the signal, got %s", record.State.Status)
+	}
+
+	if _, err := backend.retry(ctx, runtime.ID()); err == nil || !strings.Contains(err.Error(), "409") {
+		t.Errorf("Expected a conflict for retrying a completed instance, got %v", err)
+	}
+	definition, err := backend.definition(ctx, record.WorkflowID, record.Version)
+	if err != nil || len(definition.Steps) != 3 {
+		t.Errorf("Expected the instance's definition, got %v: %v", definition, err)
+	}
+	if _, err := backend.get(ctx, "missing"); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestInstancesCommandAgainstAPI(t *testing.T) {
+	e := engine.NewWorkflowEngine()
+	ctx := context.Background()
+	if err := e.RegisterDefinition(ctx, approvalDefinition()); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	api := httptest.NewServer(server.NewHandler(e))
+	t.Cleanup(api.Close)
+
+	runtime, err := e.StartWorkflow(ctx, "approval", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	code, stdout, stderr := execute(t, "instances", "cancel", "-api", api.URL, runtime.ID())
+	if code != 0 {
+		t.Fatalf("cancel failed with %d: %s", code, stderr)
+	}
+	if status := decodeRecord(t, stdout).State.Status; status != engine.StatusCanceled {
+		t.Errorf("Expected canceled, got %s", status)
+	}
+
+	code, stdout, _ = execute(t, "instances", "list", "-api", api.URL, "-json")
+	if code != 0 {
+		t.Fatalf("list failed with %d", code)
+	}
+	var records []engine.InstanceRecord
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil || len(records) != 1 {
+		t.Errorf("Expected one record as JSON, got %q: %v", stdout, err)
+	}
+}
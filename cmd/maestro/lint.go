@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// knownStepTypes motorun tanıdığı adım tipleridir
+var knownStepTypes = map[engine.StepType]bool{
+	engine.StepTypeTask:     true,
+	engine.StepTypeApproval: true,
+	engine.StepTypeDecision: true,
+	engine.StepTypeProcess:  true,
+	engine.StepTypeTimer:    true,
+	engine.StepTypeSignal:   true,
+	engine.StepTypeMap:      true,
+}
+
+// lint Validate'in kabul ettiği ancak çalışma sırasında başarısız olacak veya
+// büyük olasılıkla yanlış olan yapılandırmaları uyarı olarak döndürür
+func lint(definition *engine.WorkflowDefinition) []string {
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	if definition.Name == "" {
+		warn("tanımın adı yok")
+	}
+	for _, id := range unreachableSteps(definition) {
+		warn("adım %s ilk adımdan ulaşılamıyor", id)
+	}
+
+	for i := range definition.Steps {
+		step := &definition.Steps[i]
+		if step.Name == "" {
+			warn("adım %s: adı yok", step.ID)
+		}
+		if !knownStepTypes[step.Type] {
+			warn("adım %s: bilinmeyen adım tipi %q", step.ID, step.Type)
+		}
+		if step.Timeout < 0 {
+			warn("adım %s: zaman aşımı negatif: %s", step.ID, step.Timeout)
+		}
+		if policy := step.RetryPolicy; policy != nil {
+			if policy.MaxAttempts < 2 {
+				warn("adım %s: yeniden deneme politikası hiç yeniden denemez (max_attempts %d)", step.ID, policy.MaxAttempts)
+			}
+			if policy.MaxInterval > 0 && policy.MaxInterval < policy.InitialInterval {
+				warn("adım %s: en uzun bekleme ilk beklemeden kısa: %s < %s", step.ID, policy.MaxInterval, policy.InitialInterval)
+			}
+		}
+
+		switch step.Type {
+		case engine.StepTypeTimer:
+			_, duration := step.Config[engine.TimerConfigDuration]
+			_, until := step.Config[engine.TimerConfigUntil]
+			if !duration && !until {
+				warn("adım %s: zamanlayıcı adımında %s veya %s yok", step.ID, engine.TimerConfigDuration, engine.TimerConfigUntil)
+			}
+		case engine.StepTypeSignal:
+			if name, _ := step.Config[engine.SignalConfigName].(string); name == "" {
+				warn("adım %s: sinyal adımında %s adı yok", step.ID, engine.SignalConfigName)
+			}
+			if _, ok := step.Config[engine.SignalConfigTimeoutStep]; ok {
+				if _, ok := step.Config[engine.SignalConfigTimeout]; !ok {
+					warn("adım %s: %s var ama %s yok; zaman aşımı adımına hiç geçilmez", step.ID, engine.SignalConfigTimeoutStep, engine.SignalConfigTimeout)
+				}
+			}
+		case engine.StepTypeMap:
+			stepID, _ := step.Config[engine.MapConfigStep].(string)
+			workflowID, _ := step.Config[engine.MapConfigWorkflow].(string)
+			if (stepID == "") == (workflowID == "") {
+				warn("adım %s: map adımında %s veya %s alanlarından yalnızca biri olmalı", step.ID, engine.MapConfigStep, engine.MapConfigWorkflow)
+			}
+			if items, _ := step.Config[engine.MapConfigItems].(string); items == "" {
+				warn("adım %s: map adımında %s yolu yok", step.ID, engine.MapConfigItems)
+			}
+		case engine.StepTypeDecision:
+			if len(step.NextSteps) < 2 {
+				warn("adım %s: karar adımının %d dalı var", step.ID, len(step.NextSteps))
+			}
+		}
+	}
+	return warnings
+}
+
+// unreachableSteps ilk adımdan ileri geçişlerle ulaşılamayan adımları tanımdaki
+// sırayla döndürür
+func unreachableSteps(definition *engine.WorkflowDefinition) []string {
+	if len(definition.Steps) == 0 {
+		return nil
+	}
+	index := make(map[string]*engine.StepDefinition, len(definition.Steps))
+	for i := range definition.Steps {
+		index[definition.Steps[i].ID] = &definition.Steps[i]
+	}
+
+	visited := make(map[string]bool)
+	stack := []string{definition.Steps[0].ID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		step, ok := index[id]
+		if !ok || visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, step.NextSteps...)
+		if timeoutStep, ok := step.Config[engine.SignalConfigTimeoutStep].(string); ok && step.Type == engine.StepTypeSignal {
+			stack = append(stack, timeoutStep)
+		}
+	}
+
+	var unreachable []string
+	for _, step := range definition.Steps {
+		if !visited[step.ID] {
+			unreachable = append(unreachable, step.ID)
+		}
+	}
+	return unreachable
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// hasWarning uyarılardan birinin want'ı içerip içermediğini döndürür
+func hasWarning(warnings []string, want string) bool {
+	for _, warning := range warnings {
+		if strings.Contains(warning, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLintStepConfiguration(t *testing.T) {
+	definition := engine.NewWorkflowDefinition("config", "Config", "")
+	definition.AddStep(engine.NewStepDefinition("wait", "Wait", engine.StepTypeTimer).
+		WithNextSteps("approval"))
+	definition.AddStep(engine.NewStepDefinition("approval", "Approval", engine.StepTypeSignal).
+		WithConfig(map[string]interface{}{engine.SignalConfigTimeoutStep: "fanout"}).
+		WithNextSteps("fanout"))
+	definition.AddStep(engine.NewStepDefinition("fanout", "Fanout", engine.StepTypeMap).
+		WithConfig(map[string]interface{}{
+			engine.MapConfigStep:     "item",
+			engine.MapConfigWorkflow: "child",
+		}).
+		WithNextSteps("route"))
+	definition.AddStep(engine.NewStepDefinition("route", "Route", engine.StepTypeDecision).
+		WithNextSteps("charge"))
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", "payment").
+		WithRetryPolicy(1, 2*time.Second, time.Second, 2))
+
+	warnings := lint(definition)
+	for _, want := range []string{
+		"adım wait: zamanlayıcı adımında duration veya until yok",
+		"adım approval: sinyal adımında signal adı yok",
+		"adım approval: timeout_step var ama timeout yok",
+		"adım fanout: map adımında step veya workflow",
+		"adım fanout: map adımında items yolu yok",
+		"adım route: karar adımının 1 dalı var",
+		`adım charge: bilinmeyen adım tipi "payment"`,
+		"adım charge: yeniden deneme politikası hiç yeniden denemez",
+		"adım charge: en uzun bekleme ilk beklemeden kısa",
+	} {
+		if !hasWarning(warnings, want) {
+			t.Errorf("Expected warning %q, got %q", want, warnings)
+		}
+	}
+}
+
+func TestLintSignalTimeoutStepIsReachable(t *testing.T) {
+	// Yalnızca sinyal zaman aşımıyla ulaşılan adım ulaşılamaz sayılmamalı
+	definition := orderDefinition()
+	definition.Steps[1].Config[engine.SignalConfigTimeout] = "1h"
+	definition.Steps[1].Config[engine.SignalConfigTimeoutStep] = "expire"
+	definition.AddStep(engine.NewStepDefinition("expire", "Expire", engine.StepTypeTask))
+
+	if warnings := lint(definition); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %q", warnings)
+	}
+}
@@ -0,0 +1,139 @@
+// Command maestro iş akışı tanımlarını doğrular, çizer ve yerel olarak çalıştırır;
+// bir depo dizinindeki veya HTTP API'sindeki örnekleri listeler ve denetler:
+//
+//	maestro validate <tanım.json>...
+//	maestro lint <tanım.json>...
+//	maestro graph [-format dot|mermaid] [-instance id -store dizin | -api URL] [tanım.json]
+//	maestro run -plugin steps.so [-input girdi.json] [-store dizin] <tanım.json>
+//	maestro instances list|show|cancel|signal|retry [-store dizin | -api URL] ...
+//
+// Adım fonksiyonları Go eklentilerinden yüklenir. Eklenti
+// Register(*engine.WorkflowEngine) fonksiyonunu veya Steps adında bir
+// map[string]engine.StepFunc değişkenini dışa açmalıdır.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// errUsage komut yanlış kullanıldığında döner; kullanım bilgisi zaten yazılmıştır
+var errUsage = errors.New("hatalı kullanım")
+
+// command bir alt komutu temsil eder
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, stdout, stderr io.Writer) error
+}
+
+// commands alt komutları kullanım bilgisindeki sırayla tutar
+var commands = []command{
+	{name: "validate", summary: "tanımları motorun kurallarına göre doğrular", run: validateCommand},
+	{name: "lint", summary: "tanımlardaki olası hataları uyarı olarak raporlar", run: lintCommand},
+	{name: "graph", summary: "tanımın adım grafiğini DOT veya Mermaid biçiminde yazar", run: graphCommand},
+	{name: "run", summary: "tanımı eklentilerdeki adımlarla yerel olarak çalıştırır", run: runCommand},
+	{name: "instances", summary: "örnekleri listeler ve denetler", run: instancesCommand},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run komutu çalıştırır ve sürecin çıkış kodunu döndürür
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(ctx, args[1:], stdout, stderr)
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			return 2
+		default:
+			fmt.Fprintf(stderr, "maestro %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "maestro: bilinmeyen komut: %s\n\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+// usage komutların listesini yazar
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Kullanım: maestro <komut> [seçenekler] [argümanlar]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Komutlar:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Komutun seçenekleri için: maestro <komut> -h")
+}
+
+// newFlagSet komutun hatalarını stderr'e yazan bir seçenek kümesi oluşturur
+func newFlagSet(name, synopsis string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("maestro "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Kullanım: maestro %s %s\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags seçenekleri okur; hatalı seçenekte errUsage döner
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// requireArgs konumsal argüman sayısını denetler; uymazsa kullanım bilgisini yazar
+func requireArgs(fs *flag.FlagSet, min, max int) error {
+	n := fs.NArg()
+	if n < min || (max >= 0 && n > max) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// stringList tekrarlanabilen bir metin seçeneğidir
+type stringList []string
+
+func (l *stringList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
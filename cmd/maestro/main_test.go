@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// execute komutu çalıştırır ve çıkış kodunu, standart çıktıyı ve hatayı döndürür
+func execute(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// writeDefinition tanımı geçici bir JSON dosyasına yazar ve yolunu döndürür
+func writeDefinition(t *testing.T, definition *engine.WorkflowDefinition) string {
+	t.Helper()
+	data, err := json.Marshal(definition)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), definition.ID+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+// orderDefinition hazırlık, onay bekleme ve gönderim adımlarından oluşur
+func orderDefinition() *engine.WorkflowDefinition {
+	definition := engine.NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(engine.NewStepDefinition("prepare", "Prepare", engine.StepTypeTask).
+		WithNextSteps("approval"))
+	definition.AddStep(engine.NewStepDefinition("approval", "Approval", engine.StepTypeSignal).
+		WithConfig(map[string]interface{}{engine.SignalConfigName: "approval"}).
+		WithNextSteps("ship"))
+	definition.AddStep(engine.NewStepDefinition("ship", "Ship", engine.StepTypeTask))
+	return definition
+}
+
+// orderSteps order tanımının adım fonksiyonlarını kaydeder
+func orderSteps(e *engine.WorkflowEngine) {
+	e.RegisterStep("prepare", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "prepared", nil
+	})
+	e.RegisterStep("ship", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "shipped", nil
+	})
+}
+
+func TestRunWithoutArgumentsPrintsUsage(t *testing.T) {
+	code, _, stderr := execute(t)
+	if code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	for _, cmd := range commands {
+		if !strings.Contains(stderr, cmd.name) {
+			t.Errorf("Expected usage to list %s, got %q", cmd.name, stderr)
+		}
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	code, _, stderr := execute(t, "deploy")
+	if code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "deploy") {
+		t.Errorf("Expected the unknown command in the error, got %q", stderr)
+	}
+}
+
+func TestRunCommandHelp(t *testing.T) {
+	code, _, stderr := execute(t, "run", "-h")
+	if code != 0 {
+		t.Errorf("Expected exit code 0 for help, got %d", code)
+	}
+	if !strings.Contains(stderr, "-plugin") {
+		t.Errorf("Expected flag descriptions in help, got %q", stderr)
+	}
+}
+
+func TestRunBadFlag(t *testing.T) {
+	code, _, _ := execute(t, "validate", "-unknown", "x.json")
+	if code != 2 {
+		t.Errorf("Expected exit code 2 for an unknown flag, got %d", code)
+	}
+}
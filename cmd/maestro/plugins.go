@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// registrar adım fonksiyonlarını motora kaydeder
+type registrar func(*engine.WorkflowEngine)
+
+// loadPlugins Go eklentilerini açar ve her biri için bir registrar döndürür.
+// Eklenti Register(*engine.WorkflowEngine) fonksiyonunu veya Steps adında
+// map[string]engine.StepFunc değişkenini dışa açmalıdır; ikisi birden varsa
+// ikisi de uygulanır.
+func loadPlugins(paths []string) ([]registrar, error) {
+	registrars := make([]registrar, 0, len(paths))
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("eklenti açılamadı: %w", err)
+		}
+
+		found := false
+		if symbol, err := p.Lookup("Register"); err == nil {
+			register, ok := symbol.(func(*engine.WorkflowEngine))
+			if !ok {
+				return nil, fmt.Errorf("%s: Register func(*engine.WorkflowEngine) değil: %T", path, symbol)
+			}
+			registrars = append(registrars, register)
+			found = true
+		}
+		if symbol, err := p.Lookup("Steps"); err == nil {
+			steps, ok := symbol.(*map[string]engine.StepFunc)
+			if !ok {
+				return nil, fmt.Errorf("%s: Steps map[string]engine.StepFunc değil: %T", path, symbol)
+			}
+			registrars = append(registrars, registerSteps(*steps))
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: eklenti Register veya Steps dışa açmıyor", path)
+		}
+	}
+	return registrars, nil
+}
+
+// registerSteps adım haritasını motora kaydeden bir registrar döndürür
+func registerSteps(steps map[string]engine.StepFunc) registrar {
+	return func(e *engine.WorkflowEngine) {
+		for id, step := range steps {
+			e.RegisterStep(id, step)
+		}
+	}
+}
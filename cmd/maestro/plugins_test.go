@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+func TestLoadPluginsMissingFile(t *testing.T) {
+	if _, err := loadPlugins([]string{filepath.Join(t.TempDir(), "missing.so")}); err == nil {
+		t.Error("Expected an error for a missing plugin")
+	}
+}
+
+func TestRegisterSteps(t *testing.T) {
+	e := engine.NewWorkflowEngine()
+	registerSteps(map[string]engine.StepFunc{
+		"greet": func(ctx context.Context, data interface{}) (interface{}, error) {
+			return "hello", nil
+		},
+	})(e)
+
+	result, err := e.ExecuteStep(context.Background(), "greet", nil)
+	if err != nil {
+		t.Fatalf("ExecuteStep failed: %v", err)
+	}
+	if result != "hello" {
+		t.Errorf("Expected hello, got %v", result)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/filestore"
+)
+
+// runOptions yerel çalıştırmanın seçenekleridir
+type runOptions struct {
+	input          map[string]interface{}
+	registrars     []registrar
+	store          engine.WorkflowStore
+	idempotencyKey string
+	// wait zamanlayıcı bekleyen örneği uyanma zamanına kadar bekletir
+	wait   bool
+	logger *slog.Logger
+}
+
+// runCommand tanımı eklentilerdeki adım fonksiyonlarıyla çalıştırır ve örneğin
+// son kaydını yazar; örnek başarısız olursa komut da başarısız olur
+func runCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("run", "[seçenekler] <tanım.json>", stderr)
+	var plugins stringList
+	fs.Var(&plugins, "plugin", "adım fonksiyonlarını dışa açan Go eklentisi (.so); tekrarlanabilir")
+	inputPath := fs.String("input", "", "örneğin girdisini içeren JSON dosyası; - standart girdidir")
+	inputJSON := fs.String("input-json", "", "örneğin girdisi olarak JSON nesnesi")
+	storeDir := fs.String("store", "", "örneğin kaydedileceği filestore dizini; verilmezse bellekte çalışır")
+	key := fs.String("idempotency-key", "", "örneği başlatan isteğin idempotency anahtarı")
+	wait := fs.Bool("wait", false, "zamanlayıcı bekleyen örneği uyanma zamanına kadar bekletir")
+	verbose := fs.Bool("v", false, "motorun kayıtlarını standart hataya yazar")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, 1); err != nil {
+		return err
+	}
+	if *inputPath != "" && *inputJSON != "" {
+		return fmt.Errorf("-input ve -input-json birlikte verilemez")
+	}
+
+	definition, err := loadDefinition(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	options := runOptions{idempotencyKey: *key, wait: *wait}
+	if options.input, err = loadInput(*inputPath, *inputJSON); err != nil {
+		return err
+	}
+	if options.registrars, err = loadPlugins(plugins); err != nil {
+		return err
+	}
+	if *storeDir != "" {
+		if options.store, err = filestore.New(*storeDir); err != nil {
+			return err
+		}
+	}
+	if *verbose {
+		options.logger = slog.New(slog.NewTextHandler(stderr, nil))
+	}
+
+	record, err := runWorkflow(ctx, definition, options)
+	if record != nil {
+		if werr := printJSON(stdout, record); werr != nil {
+			return werr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if record.State.Status == engine.StatusFailed {
+		return fmt.Errorf("örnek başarısız oldu: %v", record.State.Error)
+	}
+	return nil
+}
+
+// loadInput örneğin girdisini dosyadan veya JSON metninden okur
+func loadInput(path, raw string) (map[string]interface{}, error) {
+	data := []byte(raw)
+	if path != "" {
+		var err error
+		if data, err = readInput(path); err != nil {
+			return nil, err
+		}
+	}
+	input := make(map[string]interface{})
+	if len(data) == 0 {
+		return input, nil
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("girdi JSON nesnesi değil: %w", err)
+	}
+	return input, nil
+}
+
+// runWorkflow tanımı yeni bir motorda başlatır ve örnek bir bekleme noktasına
+// veya sona ulaşınca kaydını döndürür. wait verilmişse zamanlayıcı bekleyen
+// örnek uyanma zamanında devam ettirilir.
+func runWorkflow(ctx context.Context, definition *engine.WorkflowDefinition, options runOptions) (*engine.InstanceRecord, error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	var opts []engine.EngineOption
+	if options.store != nil {
+		opts = append(opts, engine.WithStore(options.store))
+	}
+	if options.logger != nil {
+		opts = append(opts, engine.WithLogger(options.logger))
+	}
+	e := engine.NewWorkflowEngine(opts...)
+	for _, register := range options.registrars {
+		register(e)
+	}
+	if err := e.RegisterDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+
+	var startOpts []engine.StartOption
+	if options.idempotencyKey != "" {
+		startOpts = append(startOpts, engine.WithIdempotencyKey(options.idempotencyKey))
+	}
+	runtime, err := e.StartWorkflow(ctx, definition.ID, options.input, startOpts...)
+	if runtime == nil {
+		return nil, err
+	}
+	if err != nil {
+		return e.Store().GetInstance(ctx, runtime.ID())
+	}
+
+	for options.wait {
+		state := runtime.GetState()
+		if state.Status != engine.StatusWaiting || state.WakeAt == nil {
+			break
+		}
+		timer := time.NewTimer(time.Until(*state.WakeAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return e.Store().GetInstance(context.WithoutCancel(ctx), runtime.ID())
+		case <-timer.C:
+		}
+		if _, err := e.FireDueTimers(ctx); err != nil {
+			return nil, err
+		}
+		if current, ok := e.GetRuntime(runtime.ID()); ok {
+			runtime = current
+		} else {
+			break
+		}
+	}
+	return e.Store().GetInstance(ctx, runtime.ID())
+}
+
+// printJSON değeri girintili JSON olarak yazar
+func printJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
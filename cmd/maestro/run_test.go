@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+func TestRunWorkflowStopsAtSignal(t *testing.T) {
+	record, err := runWorkflow(context.Background(), orderDefinition(), runOptions{
+		input:      map[string]interface{}{"order": "A-1"},
+		registrars: []registrar{orderSteps},
+	})
+	if err != nil {
+		t.Fatalf("runWorkflow failed: %v", err)
+	}
+	if record.State.Status != engine.StatusWaiting || record.State.CurrentStepID != "approval" {
+		t.Errorf("Expected waiting at approval, got %s at %s", record.State.Status, record.State.CurrentStepID)
+	}
+	if record.State.StepResults["prepare"] != "prepared" {
+		t.Errorf("Expected prepare result, got %v", record.State.StepResults["prepare"])
+	}
+	if record.State.Context["order"] != "A-1" {
+		t.Errorf("Expected input in context, got %v", record.State.Context)
+	}
+}
+
+func TestRunWorkflowWaitsForTimers(t *testing.T) {
+	definition := engine.NewWorkflowDefinition("delayed", "Delayed", "")
+	definition.AddStep(engine.NewStepDefinition("pause", "Pause", engine.StepTypeTimer).
+		WithConfig(map[string]interface{}{engine.TimerConfigDuration: "20ms"}).
+		WithNextSteps("ship"))
+	definition.AddStep(engine.NewStepDefinition("ship", "Ship", engine.StepTypeTask))
+
+	record, err := runWorkflow(context.Background(), definition, runOptions{
+		registrars: []registrar{orderSteps},
+		wait:       true,
+	})
+	if err != nil {
+		t.Fatalf("runWorkflow failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed after the timer, got %s", record.State.Status)
+	}
+}
+
+func TestRunWorkflowRejectsInvalidDefinition(t *testing.T) {
+	definition := engine.NewWorkflowDefinition("empty", "Empty", "")
+	if _, err := runWorkflow(context.Background(), definition, runOptions{}); err == nil {
+		t.Error("Expected an error for a definition without steps")
+	}
+}
+
+func TestRunCommandFailsForFailedInstance(t *testing.T) {
+	// Eklenti verilmediğinden ilk adımın fonksiyonu bulunamaz
+	path := writeDefinition(t, orderDefinition())
+
+	code, stdout, stderr := execute(t, "run", "-input-json", `{"order": "A-1"}`, path)
+	if code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	var record engine.InstanceRecord
+	if err := json.Unmarshal([]byte(stdout), &record); err != nil {
+		t.Fatalf("Expected the record as JSON, got %q: %v", stdout, err)
+	}
+	if record.State.Status != engine.StatusFailed {
+		t.Errorf("Expected failed, got %s", record.State.Status)
+	}
+	if !strings.Contains(stderr, "başarısız") {
+		t.Errorf("Expected the failure on stderr, got %q", stderr)
+	}
+}
+
+func TestLoadInput(t *testing.T) {
+	input, err := loadInput("", `{"amount": 10}`)
+	if err != nil {
+		t.Fatalf("loadInput failed: %v", err)
+	}
+	if input["amount"] != float64(10) {
+		t.Errorf("Expected amount 10, got %v", input["amount"])
+	}
+
+	if _, err := loadInput("", `[1, 2]`); err == nil {
+		t.Error("Expected an error for a non-object input")
+	}
+	if _, err := loadInput("missing.json", ""); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+}
@@ -8,15 +8,70 @@ type StepFunc = engine.StepFunc
 type ObserverFunc = engine.ObserverFunc
 type Event = engine.Event
 type EventType = engine.EventType
+type EngineOption = engine.EngineOption
+type WorkflowStore = engine.WorkflowStore
+type Clock = engine.Clock
+type HistoryEvent = engine.HistoryEvent
+type StartOption = engine.StartOption
+type StepInfo = engine.StepInfo
+type LeaseStore = engine.LeaseStore
+type EventLog = engine.EventLog
+type WorkerPoolStats = engine.WorkerPoolStats
+type StepOption = engine.StepOption
+type RateLimit = engine.RateLimit
+type Throttle = engine.Throttle
+type Task = engine.Task
+type StepHandler = engine.StepHandler
+type StepMiddleware = engine.StepMiddleware
+type GraphOption = engine.GraphOption
 
 // Re-export event constants
 const (
 	EventStepStarted  = engine.EventStepStarted
 	EventStepComplete = engine.EventStepComplete
 	EventStepFailed   = engine.EventStepFailed
+	EventStepRetried  = engine.EventStepRetried
+
+	EventStepSuspended = engine.EventStepSuspended
+
+	EventStepThrottled = engine.EventStepThrottled
+	EventStepHeartbeat = engine.EventStepHeartbeat
+)
+
+// Re-export engine options
+var (
+	WithStore             = engine.WithStore
+	WithClock             = engine.WithClock
+	WithIdempotencyWindow = engine.WithIdempotencyWindow
+	WithLeases            = engine.WithLeases
+	WithClaimPollInterval = engine.WithClaimPollInterval
+	WithMaxConcurrency    = engine.WithMaxConcurrency
+	WithStepConcurrency   = engine.WithStepConcurrency
+	WithTaskLease         = engine.WithTaskLease
+	WithCompletionKey     = engine.WithCompletionKey
+	WithTracerProvider    = engine.WithTracerProvider
+	WithLogger            = engine.WithLogger
 )
 
+// Re-export start options, step options and step helpers
+var (
+	WithIdempotencyKey   = engine.WithIdempotencyKey
+	StepInfoFromContext  = engine.StepInfoFromContext
+	ContextWithStepInfo  = engine.ContextWithStepInfo
+	WithStepRateLimit    = engine.WithStepRateLimit
+	Heartbeat            = engine.Heartbeat
+	ContextWithHeartbeat = engine.ContextWithHeartbeat
+	LoggerFromContext    = engine.LoggerFromContext
+	ContextWithLogger    = engine.ContextWithLogger
+)
+
+// Re-export graph options
+var WithStatusOverlay = engine.WithStatusOverlay
+
+// Pending is returned by a step whose result arrives later through CompleteStep
+var Pending = engine.Pending
+
 // NewEngine creates a new workflow engine
-func NewEngine() *WorkflowEngine {
-	return engine.NewWorkflowEngine()
+func NewEngine(opts ...EngineOption) *WorkflowEngine {
+	return engine.NewWorkflowEngine(opts...)
 }
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errCrashed = errors.New("process crashed")
+
+// crashStore sürecin çöktüğü noktayı taklit eder: crashOn doğru döndüğünde geçmiş
+// eklemesi yazılmaz ve o andan sonra hiçbir kayıt depoya ulaşmaz
+type crashStore struct {
+	WorkflowStore
+	crashOn func(events []HistoryEvent) bool
+	crashed bool
+}
+
+func (s *crashStore) AppendHistory(ctx context.Context, instanceID string, events []HistoryEvent) error {
+	if s.crashed || (s.crashOn != nil && s.crashOn(events)) {
+		s.crashed = true
+		return errCrashed
+	}
+	return s.WorkflowStore.AppendHistory(ctx, instanceID, events)
+}
+
+func (s *crashStore) SaveInstance(ctx context.Context, record *InstanceRecord) error {
+	if s.crashed {
+		return errCrashed
+	}
+	return s.WorkflowStore.SaveInstance(ctx, record)
+}
+
+// startsWith eklemenin ilk olayı verilen tip ve adımsa doğru döner
+func startsWith(eventType HistoryEventType, stepID string) func([]HistoryEvent) bool {
+	return func(events []HistoryEvent) bool {
+		return events[0].Type == eventType && events[0].StepID == stepID
+	}
+}
+
+// runUntilCrash charge → receipt iş akışını çökene kadar çalıştırır, ardından
+// aynı depo üzerinde yeni bir motorla örneği kurtarıp tamamlar. Her adımın iki
+// süreçteki toplam çalışma sayısı ve depodaki geçmiş döner.
+func runUntilCrash(t *testing.T, crashOn func([]HistoryEvent) bool, crashInCharge bool) (map[string]int, []HistoryEvent) {
+	t.Helper()
+	ctx := context.Background()
+	inner := NewMemoryStore()
+	store := &crashStore{WorkflowStore: inner, crashOn: crashOn}
+	runs := make(map[string]int)
+
+	register := func(engine *WorkflowEngine) {
+		engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+			runs["charge"]++
+			if crashInCharge && runs["charge"] == 1 {
+				store.crashed = true
+			}
+			return "charged", nil
+		})
+		engine.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+			runs["receipt"]++
+			return "sent", nil
+		})
+	}
+
+	first := NewWorkflowEngine(WithStore(store))
+	register(first)
+	definition := newRetryDefinition(1, 0)
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); !errors.Is(err, errCrashed) {
+		t.Fatalf("Start should stop at the crash, got %v", err)
+	}
+
+	second := NewWorkflowEngine(WithStore(inner))
+	register(second)
+	runtimes, err := second.Recover(ctx)
+	if err != nil || len(runtimes) != 1 {
+		t.Fatalf("Recover failed: %v (%d runtimes)", err, len(runtimes))
+	}
+	if err := runtimes[0].Resume(ctx); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if status := runtimes[0].GetState().Status; status != StatusCompleted {
+		t.Fatalf("Recovered instance should complete, got %s", status)
+	}
+	assertFoldMatches(t, second, runtimes[0])
+
+	history, _ := inner.LoadHistory(ctx, runtime.ID())
+	return runs, history
+}
+
+// chargeAttempts geçmişteki charge başlangıçlarının deneme numaralarını döndürür
+func chargeAttempts(history []HistoryEvent) []int {
+	attempts := make([]int, 0)
+	for _, event := range history {
+		if event.Type == HistoryStepStarted && event.StepID == "charge" {
+			attempts = append(attempts, event.Attempt)
+		}
+	}
+	return attempts
+}
+
+func TestCrashBeforeStepStarted(t *testing.T) {
+	runs, history := runUntilCrash(t, startsWith(HistoryStepStarted, "charge"), false)
+
+	// Başlangıcı kalıcı olmayan adım hiç çalıştırılmamış olmalı
+	if runs["charge"] != 1 || runs["receipt"] != 1 {
+		t.Errorf("Each step should run once, got %v", runs)
+	}
+	if attempts := chargeAttempts(history); len(attempts) != 1 || attempts[0] != 1 {
+		t.Errorf("Expected a single first attempt, got %v", attempts)
+	}
+}
+
+func TestCrashWhileStepRunning(t *testing.T) {
+	runs, history := runUntilCrash(t, nil, true)
+
+	// Sonucu kalıcı olmayan adım aynı deneme numarasıyla yeniden çalıştırılır
+	if runs["charge"] != 2 || runs["receipt"] != 1 {
+		t.Errorf("Charge should be re-executed once, got %v", runs)
+	}
+	if attempts := chargeAttempts(history); len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 1 {
+		t.Errorf("Re-executed step should keep its attempt number, got %v", attempts)
+	}
+}
+
+func TestCrashAfterStepCompleted(t *testing.T) {
+	runs, history := runUntilCrash(t, startsWith(HistoryStepScheduled, "receipt"), false)
+
+	// Sonucu kalıcı olan adım kurtarmada yeniden çalıştırılmaz
+	if runs["charge"] != 1 || runs["receipt"] != 1 {
+		t.Errorf("Completed step should not be re-executed, got %v", runs)
+	}
+	if attempts := chargeAttempts(history); len(attempts) != 1 {
+		t.Errorf("Expected a single charge attempt, got %v", attempts)
+	}
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock motorun zaman kaynağını soyutlar; testlerde sahte saat enjekte etmek için kullanılır
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock sistem saatini kullanan varsayılan saattir
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ManualClock yalnızca elle ilerletildiğinde zamanı değişen bir saattir
+type ManualClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []manualWaiter
+}
+
+type manualWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewManualClock verilen anda durmuş yeni bir saat oluşturur
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now saatin gösterdiği anı döndürür
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// After saat d kadar ilerletildiğinde tetiklenen bir kanal döndürür
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch := make(chan time.Time, 1)
+	deadline := c.now.Add(d)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, manualWaiter{deadline: deadline, ch: ch})
+	return ch
+}
+
+// Advance saati d kadar ilerletir ve vadesi gelen bekleyenleri tetikler
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set saati verilen ana ayarlar
+func (c *ManualClock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setLocked(t)
+}
+
+func (c *ManualClock) setLocked(t time.Time) {
+	c.now = t
+
+	sort.Slice(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+	})
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(t) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- t
+	}
+	c.waiters = remaining
+}
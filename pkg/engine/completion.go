@@ -0,0 +1,265 @@
+package engine
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Pending adım fonksiyonunun sonucu dış bir sistemin geri çağrısıyla
+// belirlenecekse döndürdüğü değerdir. Örnek goroutine tutmadan bekleme durumuna
+// geçer; geri çağrı StepInfo.CompletionToken ile CompleteStep'i çağırarak adımı
+// sonuçlandırır. Yalnızca örnek içinde çalışan görev adımlarında geçerlidir.
+var Pending = &pendingResult{}
+
+type pendingResult struct{}
+
+// ErrInvalidCompletionToken jeton çözülemediğinde veya imzası bu motorun
+// anahtarıyla doğrulanamadığında döner
+var ErrInvalidCompletionToken = errors.New("geçersiz tamamlama jetonu")
+
+// ErrStaleCompletionToken jetonun denemesi artık sonuç beklemiyorsa döner;
+// deneme zaten tamamlanmış, zaman aşımına uğramış, yeniden denenmiş veya adım
+// döngüyle ya da Retry ile yeniden başlatılmıştır
+var ErrStaleCompletionToken = errors.New("tamamlama jetonu eskimiş")
+
+// ErrCompletionTimeout askıya alınan adımın sonucu adım zaman aşımı içinde
+// gelmediğinde denemenin hatasıdır; adımın yeniden deneme politikası uygulanır
+var ErrCompletionTimeout = errors.New("adım tamamlama zaman aşımı")
+
+// completionKeySize varsayılan rastgele imza anahtarının bayt uzunluğudur
+const completionKeySize = 32
+
+// WithCompletionKey tamamlama jetonlarını imzalayan anahtarı belirler. Anahtar
+// verilmezse motor her başlatıldığında rastgele bir anahtar üretir; bu durumda
+// süreç yeniden başladıktan sonra veya başka bir düğümde önceki jetonlar
+// doğrulanamaz. Aynı depoyu paylaşan düğümler aynı anahtarı kullanmalıdır.
+func WithCompletionKey(key []byte) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.completionKey = append([]byte(nil), key...)
+	}
+}
+
+// newCompletionKey rastgele bir imza anahtarı üretir
+func newCompletionKey() []byte {
+	key := make([]byte, completionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("tamamlama anahtarı üretilemedi: %v", err))
+	}
+	return key
+}
+
+// completionClaims tamamlama jetonunun imzalanan içeriğidir. Execution adımın
+// yürütme numarasıdır; döngüyle yeniden girilen veya Retry ile yeniden başlatılan
+// adımın denemeleri önceki yürütmenin jetonlarıyla tamamlanamaz.
+type completionClaims struct {
+	InstanceID string `json:"instance_id"`
+	StepID     string `json:"step_id"`
+	Execution  int    `json:"execution"`
+	Attempt    int    `json:"attempt"`
+}
+
+// completionToken deneme için imzalı bir tamamlama jetonu üretir. Jeton
+// base64url kodlu içerik ile HMAC-SHA256 imzasının noktayla birleşimidir.
+func (e *WorkflowEngine) completionToken(claims completionClaims) string {
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(e.signCompletion(encoded))
+}
+
+// parseCompletionToken jetonun imzasını doğrular ve içeriğini döndürür
+func (e *WorkflowEngine) parseCompletionToken(token string) (completionClaims, error) {
+	var claims completionClaims
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, ErrInvalidCompletionToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, e.signCompletion(encoded)) {
+		return claims, ErrInvalidCompletionToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return claims, ErrInvalidCompletionToken
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, ErrInvalidCompletionToken
+	}
+	return claims, nil
+}
+
+// signCompletion jeton içeriğini motorun anahtarıyla imzalar
+func (e *WorkflowEngine) signCompletion(encoded string) []byte {
+	mac := hmac.New(sha256.New, e.completionKey)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// CompleteStep Pending döndürerek askıya alınmış adımı dış sistemin geri
+// çağrısıyla sonuçlandırır. stepErr nil ise result adımın sonucu olarak
+// kaydedilir ve örnek bir sonraki bekleme noktasına veya sona kadar yürütülür;
+// değilse deneme bu hatayla başarısız sayılır ve adımın yeniden deneme
+// politikası uygulanır. Jeton bu motorun anahtarıyla imzalanmamışsa
+// ErrInvalidCompletionToken, denemesi artık sonuç beklemiyorsa
+// ErrStaleCompletionToken döner.
+func (e *WorkflowEngine) CompleteStep(ctx context.Context, token string, result interface{}, stepErr error) error {
+	claims, err := e.parseCompletionToken(token)
+	if err != nil {
+		return err
+	}
+	runtime, err := e.runtimeFor(ctx, claims.InstanceID)
+	if err != nil {
+		return err
+	}
+	return runtime.completeSuspended(ctx, claims, result, stepErr)
+}
+
+// stepCompletion geri çağrının bildirdiği deneme sonucudur
+type stepCompletion struct {
+	stepID  string
+	attempt int
+	result  interface{}
+	err     error
+}
+
+// suspend Pending döndüren denemeyi askıya alır ve örneği bekleme durumuna
+// geçirir; adımın zaman aşımı varsa sonuç bu süre içinde gelmezse deneme
+// ErrCompletionTimeout ile başarısız olur. Geri çağrı adım fonksiyonu dönmeden
+// geldiyse örnek askıya alınmaz ve geri çağrının sonucu döner.
+func (r *WorkflowRuntime) suspend(ctx context.Context, step *StepDefinition, attempt int) (*stepCompletion, error) {
+	now := r.engine.clock.Now()
+
+	var wakeAt *time.Time
+	if step.Timeout > 0 {
+		deadline := now.Add(step.Timeout)
+		wakeAt = &deadline
+	}
+
+	r.mutex.Lock()
+	if !r.state.Status.advancing() {
+		r.mutex.Unlock()
+		return nil, nil
+	}
+	if early := r.early; early != nil && early.stepID == step.ID && early.attempt == attempt {
+		r.early = nil
+		r.mutex.Unlock()
+		return early, nil
+	}
+	r.state.Status = StatusWaiting
+	r.state.WakeAt = copyTime(wakeAt)
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryStepSuspended,
+		StepID:    step.ID,
+		Timestamp: now,
+		Attempt:   attempt,
+		WakeAt:    copyTime(wakeAt),
+	})
+	r.mutex.Unlock()
+
+	if wakeAt != nil {
+		timer := Timer{
+			ID:         timerID(r.id, step.ID, TimerKindCompletionTimeout),
+			InstanceID: r.id,
+			StepID:     step.ID,
+			Kind:       TimerKindCompletionTimeout,
+			WakeAt:     *wakeAt,
+		}
+		if err := r.engine.store.SaveTimer(ctx, timer); err != nil {
+			return nil, fmt.Errorf("zamanlayıcı kaydedilemedi: %w", err)
+		}
+	}
+	if err := r.persist(ctx); err != nil {
+		return nil, err
+	}
+	r.log.Info("workflow waiting", LogKeyStepID, step.ID, LogKeyAttempt, attempt, "reason", "callback")
+
+	r.engine.notifyObservers(Event{
+		Type:       EventStepSuspended,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     step.ID,
+		Data:       attempt,
+		Timestamp:  now,
+	})
+	return nil, nil
+}
+
+// completeSuspended jetonun denemesi hâlâ bekliyorsa örneği devam ettirir ve
+// geri çağrının sonucunu uygular. Deneme henüz askıya alınmamışsa sonuç
+// saklanır ve adım fonksiyonu Pending döndürdüğünde uygulanır.
+func (r *WorkflowRuntime) completeSuspended(ctx context.Context, claims completionClaims, result interface{}, stepErr error) error {
+	now := r.engine.clock.Now()
+
+	r.mutex.Lock()
+	if r.state.Status.IsTerminal() {
+		r.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrInstanceFinished, r.id)
+	}
+	step := r.stepByID(claims.StepID)
+	current := step != nil && step.Type != StepTypeTimer && step.Type != StepTypeSignal &&
+		r.state.CurrentStepID == claims.StepID &&
+		r.state.Executions[claims.StepID]+1 == claims.Execution &&
+		r.state.Attempts[claims.StepID]+1 == claims.Attempt
+	if current && r.state.Status.advancing() && r.early == nil {
+		r.early = &stepCompletion{stepID: step.ID, attempt: claims.Attempt, result: result, err: stepErr}
+		r.mutex.Unlock()
+		return nil
+	}
+	if !current || r.state.Status != StatusWaiting {
+		r.mutex.Unlock()
+		return fmt.Errorf("%w: %s/%s/%d/%d", ErrStaleCompletionToken, r.id, claims.StepID, claims.Execution, claims.Attempt)
+	}
+	r.state.wake()
+	event := HistoryEvent{
+		Type:      HistoryStepResumed,
+		StepID:    step.ID,
+		Timestamp: now,
+		Attempt:   claims.Attempt,
+	}
+	if stepErr != nil {
+		event.Error = stepErr.Error()
+	}
+	r.recordLocked(event)
+	r.mutex.Unlock()
+
+	r.log.Info("workflow resumed", LogKeyStepID, step.ID, LogKeyAttempt, claims.Attempt, "reason", "callback")
+
+	timer := timerID(r.id, step.ID, TimerKindCompletionTimeout)
+	if stepErr != nil {
+		return r.failSuspended(ctx, step, stepErr, timer)
+	}
+	more, err := r.completeStep(ctx, step, result)
+	if err != nil {
+		return err
+	}
+	return r.resume(ctx, more, timer)
+}
+
+// completionTimeout sonucu zamanında gelmeyen askıdaki denemeyi başarısız sayar
+func (r *WorkflowRuntime) completionTimeout(ctx context.Context, step *StepDefinition) error {
+	return r.failSuspended(ctx, step, fmt.Errorf("%w: %s", ErrCompletionTimeout, step.ID))
+}
+
+// failSuspended askıdaki denemeyi cause ile sonlandırır; politika izin veriyorsa
+// adım yeniden çalıştırılır, vermiyorsa örnek başarısız olur
+func (r *WorkflowRuntime) failSuspended(ctx context.Context, step *StepDefinition, cause error, timerIDs ...string) error {
+	retry, err := r.retry(ctx, step, cause)
+	if err != nil {
+		return err
+	}
+	if retry {
+		return r.resume(ctx, true, timerIDs...)
+	}
+	ferr := r.fail(ctx, cause)
+	if err := r.resume(ctx, false, timerIDs...); err != nil {
+		return err
+	}
+	return ferr
+}
@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newExportDefinition dış bir işi başlatıp sonucu geri çağrıyla bekleyen export
+// adımından ve ardından çalışan notify adımından oluşan bir tanım oluşturur
+func newExportDefinition(export StepDefinition) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("export", "Export", "")
+	definition.AddStep(export.WithNextSteps("notify"))
+	definition.AddStep(NewStepDefinition("notify", "Notify", StepTypeTask))
+	return definition
+}
+
+// registerExportSteps export adımının her denemede jetonunu tokens'a yazmasını sağlar
+func registerExportSteps(engine *WorkflowEngine, tokens chan<- string) {
+	engine.RegisterStep("export", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := StepInfoFromContext(ctx)
+		tokens <- info.CompletionToken
+		return Pending, nil
+	})
+	engine.RegisterStep("notify", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "notified", nil
+	})
+}
+
+func TestCompleteStepResumesSuspendedInstance(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	tokens := make(chan string, 1)
+	registerExportSteps(engine, tokens)
+
+	runtime := NewWorkflowRuntime(engine, newExportDefinition(NewStepDefinition("export", "Export", StepTypeTask)))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != StatusWaiting || state.CurrentStepID != "export" {
+		t.Fatalf("Expected instance waiting on export, got %s at %s", state.Status, state.CurrentStepID)
+	}
+
+	token := <-tokens
+	if err := engine.CompleteStep(ctx, token, map[string]interface{}{"url": "s3://exports/1"}, nil); err != nil {
+		t.Fatalf("CompleteStep failed: %v", err)
+	}
+	state := runtime.GetState()
+	if state.Status != StatusCompleted || state.StepResults["notify"] != "notified" {
+		t.Fatalf("Expected completed instance, got %s %v", state.Status, state.StepResults)
+	}
+	if result, ok := state.StepResults["export"].(map[string]interface{}); !ok || result["url"] != "s3://exports/1" {
+		t.Errorf("Callback result should be the step result, got %v", state.StepResults["export"])
+	}
+
+	// Aynı jetonla ikinci geri çağrı reddedilir
+	if err := engine.CompleteStep(ctx, token, nil, nil); err == nil {
+		t.Error("CompleteStep on a completed instance should fail")
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestCompleteStepErrorRetriesAttempt(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	tokens := make(chan string, 2)
+	registerExportSteps(engine, tokens)
+
+	runtime := NewWorkflowRuntime(engine, newExportDefinition(
+		NewStepDefinition("export", "Export", StepTypeTask).WithRetryPolicy(2, 0, 0, 1)))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	first := <-tokens
+	if err := engine.CompleteStep(ctx, first, nil, errors.New("export job crashed")); err != nil {
+		t.Fatalf("CompleteStep failed: %v", err)
+	}
+
+	// Yeniden deneme adımı tekrar askıya alır ve yeni bir jeton üretir
+	second := <-tokens
+	if second == first {
+		t.Fatal("Retried attempt should get a new token")
+	}
+	if state := runtime.GetState(); state.Status != StatusWaiting || state.Attempts["export"] != 1 {
+		t.Fatalf("Expected second attempt waiting, got %s with attempts %v", state.Status, state.Attempts)
+	}
+	if err := engine.CompleteStep(ctx, first, "late", nil); !errors.Is(err, ErrStaleCompletionToken) {
+		t.Errorf("Expected ErrStaleCompletionToken for the first attempt, got %v", err)
+	}
+
+	if err := engine.CompleteStep(ctx, second, nil, errors.New("export job crashed again")); err == nil {
+		t.Error("CompleteStep should return the failure once retries are exhausted")
+	}
+	if state := runtime.GetState(); state.Status != StatusFailed {
+		t.Errorf("Expected failed status, got %s", state.Status)
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestCompleteStepRejectsTokenFromEarlierLoopIteration(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	tokens := make(chan string, 2)
+	registerExportSteps(engine, tokens)
+	checks := 0
+	engine.RegisterStep("check", func(ctx context.Context, data interface{}) (interface{}, error) {
+		checks++
+		return review{NeedsRevision: checks == 1}, nil
+	})
+
+	definition := NewWorkflowDefinition("export", "Export", "")
+	definition.AddStep(NewStepDefinition("export", "Export", StepTypeTask).WithNextSteps("check"))
+	definition.AddStep(NewStepDefinition("check", "Check", StepTypeTask).
+		WithLoop("export", "steps.check.NeedsRevision", 2))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	first := <-tokens
+	if err := engine.CompleteStep(ctx, first, "v1", nil); err != nil {
+		t.Fatalf("CompleteStep failed: %v", err)
+	}
+
+	// Döngü export adımına geri döner; deneme sayacı yine 1 olsa da ilk turun
+	// jetonu yeni yürütmeyi tamamlayamaz
+	second := <-tokens
+	if state := runtime.GetState(); state.Status != StatusWaiting || state.CurrentStepID != "export" {
+		t.Fatalf("Expected the second iteration waiting on export, got %s at %s", state.Status, state.CurrentStepID)
+	}
+	if err := engine.CompleteStep(ctx, first, "stale", nil); !errors.Is(err, ErrStaleCompletionToken) {
+		t.Errorf("Expected ErrStaleCompletionToken for the first iteration, got %v", err)
+	}
+
+	if err := engine.CompleteStep(ctx, second, "v2", nil); err != nil {
+		t.Fatalf("CompleteStep failed: %v", err)
+	}
+	state := runtime.GetState()
+	if state.Status != StatusCompleted || state.StepResults["export"] != "v2" {
+		t.Errorf("Expected completed instance with the second result, got %s %v", state.Status, state.StepResults["export"])
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestCompleteStepRejectsForgedToken(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	tokens := make(chan string, 1)
+	registerExportSteps(engine, tokens)
+
+	runtime := NewWorkflowRuntime(engine, newExportDefinition(NewStepDefinition("export", "Export", StepTypeTask)))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	token := <-tokens
+
+	// Başka bir anahtarla imzalanmış veya değiştirilmiş jetonlar kabul edilmez
+	other := NewWorkflowEngine(WithCompletionKey([]byte("another-key")))
+	forged := other.completionToken(completionClaims{InstanceID: runtime.ID(), StepID: "export", Execution: 1, Attempt: 1})
+	for _, tt := range []string{"", "not-a-token", token + "x", "x" + token, forged} {
+		if err := engine.CompleteStep(ctx, tt, "forged", nil); !errors.Is(err, ErrInvalidCompletionToken) {
+			t.Errorf("Expected ErrInvalidCompletionToken for %q, got %v", tt, err)
+		}
+	}
+	if state := runtime.GetState(); state.Status != StatusWaiting {
+		t.Errorf("Forged tokens should not resume the instance, got %s", state.Status)
+	}
+}
+
+func TestCompleteStepAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	key := []byte("shared-secret")
+	definition := newExportDefinition(NewStepDefinition("export", "Export", StepTypeTask))
+
+	first := NewWorkflowEngine(WithStore(store), WithCompletionKey(key))
+	tokens := make(chan string, 1)
+	registerExportSteps(first, tokens)
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := first.StartWorkflow(ctx, "export", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	token := <-tokens
+
+	// Aynı anahtarla açılan yeni süreç jetonu doğrular ve örneği depodan yükler
+	second := NewWorkflowEngine(WithStore(store), WithCompletionKey(key))
+	registerExportSteps(second, make(chan string, 1))
+	if err := second.CompleteStep(ctx, token, "exported", nil); err != nil {
+		t.Fatalf("CompleteStep failed: %v", err)
+	}
+	resumed, err := second.runtimeFor(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("runtimeFor failed: %v", err)
+	}
+	if state := resumed.GetState(); state.Status != StatusCompleted || state.StepResults["export"] != "exported" {
+		t.Errorf("Expected completed instance after restart, got %s %v", state.Status, state.StepResults)
+	}
+	assertFoldMatches(t, second, resumed)
+}
+
+func TestCompletionTimeoutFailsStep(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	tokens := make(chan string, 1)
+	registerExportSteps(engine, tokens)
+
+	runtime := NewWorkflowRuntime(engine, newExportDefinition(
+		NewStepDefinition("export", "Export", StepTypeTask).WithTimeout(time.Hour)))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	token := <-tokens
+	if state := runtime.GetState(); state.WakeAt == nil || !state.WakeAt.Equal(clock.Now().Add(time.Hour)) {
+		t.Errorf("Expected wake time at the step timeout, got %v", state.WakeAt)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err := engine.FireDueTimers(ctx); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+	state := runtime.GetState()
+	if state.Status != StatusFailed || !errors.Is(state.Error, ErrCompletionTimeout) {
+		t.Fatalf("Expected failure with ErrCompletionTimeout, got %s (%v)", state.Status, state.Error)
+	}
+	if err := engine.CompleteStep(ctx, token, "late", nil); err == nil {
+		t.Error("CompleteStep after the timeout should fail")
+	}
+}
+
+func TestCompleteStepBeforeSuspend(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	engine.RegisterStep("export", func(ctx context.Context, data interface{}) (interface{}, error) {
+		// Dış iş adım fonksiyonu dönmeden geri çağırır
+		info, _ := StepInfoFromContext(ctx)
+		if err := engine.CompleteStep(ctx, info.CompletionToken, "fast", nil); err != nil {
+			t.Errorf("CompleteStep failed: %v", err)
+		}
+		return Pending, nil
+	})
+	engine.RegisterStep("notify", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "notified", nil
+	})
+	if err := engine.RegisterDefinition(ctx, newExportDefinition(NewStepDefinition("export", "Export", StepTypeTask))); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	runtime, err := engine.StartWorkflow(ctx, "export", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != StatusCompleted || state.StepResults["export"] != "fast" {
+		t.Errorf("Early callback should complete the step, got %s %v", state.Status, state.StepResults)
+	}
+}
@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -25,6 +27,15 @@ type StepDefinition struct {
 	NextSteps   []string               `json:"next_steps,omitempty"`
 	RetryPolicy *RetryPolicy           `json:"retry_policy,omitempty"`
 	Timeout     time.Duration          `json:"timeout,omitempty"`
+	Loop        *LoopPolicy            `json:"loop,omitempty"`
+	Priority    int                    `json:"priority,omitempty"`
+	RateLimit   *RateLimit             `json:"rate_limit,omitempty"`
+	// FanOut açıksa adım tamamlandığında NextSteps'teki adımların hepsi çalışır;
+	// kapalıysa yalnızca ilk sonraki adım çalışır
+	FanOut bool `json:"fan_out,omitempty"`
+	// HeartbeatTimeout sıfırdan büyükse adım fonksiyonu bu süre içinde Heartbeat
+	// çağırmadığında deneme başarısız sayılır
+	HeartbeatTimeout time.Duration `json:"heartbeat_timeout,omitempty"`
 }
 
 // StepType adım tiplerini temsil eder
@@ -35,6 +46,9 @@ const (
 	StepTypeApproval StepType = "approval"
 	StepTypeDecision StepType = "decision"
 	StepTypeProcess  StepType = "process"
+	StepTypeTimer    StepType = "timer"
+	StepTypeSignal   StepType = "signal"
+	StepTypeMap      StepType = "map"
 )
 
 // RetryPolicy yeniden deneme politikasını temsil eder
@@ -45,6 +59,15 @@ type RetryPolicy struct {
 	Multiplier      float64       `json:"multiplier"`
 }
 
+// LoopPolicy adım tamamlandıktan sonra koşul doğru olduğu sürece önceki bir adıma
+// dönülmesini sağlar. Target'tan bu adıma kadar olan adımlar döngü gövdesidir ve
+// gövde en fazla MaxIterations kez çalışır.
+type LoopPolicy struct {
+	Target        string `json:"target"`
+	Condition     string `json:"condition"`
+	MaxIterations int    `json:"max_iterations"`
+}
+
 // NewWorkflowDefinition yeni bir iş akışı tanımı oluşturur
 func NewWorkflowDefinition(id, name, description string) *WorkflowDefinition {
 	now := time.Now()
@@ -83,12 +106,22 @@ func (s StepDefinition) WithConfig(config map[string]interface{}) StepDefinition
 	return s
 }
 
-// WithNextSteps adıma sonraki adımları ekler
+// WithNextSteps adıma sonraki adımları ekler. Adım tamamlandığında yalnızca ilk
+// sonraki adım çalışır; hepsinin çalışması için WithFanOut kullanılmalıdır.
 func (s StepDefinition) WithNextSteps(nextSteps ...string) StepDefinition {
 	s.NextSteps = nextSteps
 	return s
 }
 
+// WithFanOut adım tamamlandığında sonraki adımların hepsinin çalışmasını sağlar.
+// Dallar kuyruğa alındıkları sırayla tek tek yürütülür; birden fazla daldan
+// ulaşılan bir adım, kendisine ulaşabilecek bekleyen tüm dallar bittikten sonra
+// bir kez çalışır.
+func (s StepDefinition) WithFanOut() StepDefinition {
+	s.FanOut = true
+	return s
+}
+
 // WithRetryPolicy adıma yeniden deneme politikası ekler
 func (s StepDefinition) WithRetryPolicy(maxAttempts int, initialInterval, maxInterval time.Duration, multiplier float64) StepDefinition {
 	s.RetryPolicy = &RetryPolicy{
@@ -100,8 +133,238 @@ func (s StepDefinition) WithRetryPolicy(maxAttempts int, initialInterval, maxInt
 	return s
 }
 
+// WithLoop adıma koşullu bir geri dönüş ekler
+func (s StepDefinition) WithLoop(target, condition string, maxIterations int) StepDefinition {
+	s.Loop = &LoopPolicy{
+		Target:        target,
+		Condition:     condition,
+		MaxIterations: maxIterations,
+	}
+	return s
+}
+
 // WithTimeout adıma zaman aşımı süresi ekler
 func (s StepDefinition) WithTimeout(timeout time.Duration) StepDefinition {
 	s.Timeout = timeout
 	return s
 }
+
+// WithHeartbeatTimeout adımın kalp atışları arasındaki en uzun süreyi belirler;
+// süre dolunca deneme ErrHeartbeatTimeout ile başarısız olur ve yeniden denenir
+func (s StepDefinition) WithHeartbeatTimeout(timeout time.Duration) StepDefinition {
+	s.HeartbeatTimeout = timeout
+	return s
+}
+
+// WithPriority adımın havuz kuyruğundaki önceliğini belirler; büyük değerler önce çalışır
+func (s StepDefinition) WithPriority(priority int) StepDefinition {
+	s.Priority = priority
+	return s
+}
+
+// WithRateLimit adıma her Per süresinde limit çalışmalık bir sınır ekler;
+// resource boşsa sınır yalnızca bu adıma uygulanır
+func (s StepDefinition) WithRateLimit(resource string, limit int, per time.Duration) StepDefinition {
+	s.RateLimit = &RateLimit{Resource: resource, Limit: limit, Per: per}
+	return s
+}
+
+// Validate tanımın çalıştırılabilir olduğunu doğrular: adım kimlikleri benzersiz
+// olmalı, tüm adım referansları var olmalı ve adım grafiği yalnızca döngü
+// politikası ile bildirilmiş geri dönüşler dışında döngü içermemelidir
+func (w *WorkflowDefinition) Validate() error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("iş akışında hiç adım yok")
+	}
+
+	index := make(map[string]*StepDefinition, len(w.Steps))
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		if step.ID == "" {
+			return fmt.Errorf("adım kimliği boş olamaz (%d. adım)", i+1)
+		}
+		if _, exists := index[step.ID]; exists {
+			return fmt.Errorf("adım kimliği tekrarlanıyor: %s", step.ID)
+		}
+		index[step.ID] = step
+	}
+
+	var errs []error
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		for _, next := range forwardEdges(step) {
+			if _, ok := index[next]; !ok {
+				errs = append(errs, fmt.Errorf("adım %s bilinmeyen adıma bağlanıyor: %s", step.ID, next))
+			}
+		}
+		if step.Loop != nil {
+			if err := validateLoop(step, index); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if step.RateLimit != nil {
+			if err := step.RateLimit.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("adım %s: %w", step.ID, err))
+			}
+		}
+		if step.HeartbeatTimeout < 0 {
+			errs = append(errs, fmt.Errorf("adım %s: kalp atışı süresi negatif olamaz: %s", step.ID, step.HeartbeatTimeout))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if cycle := findCycle(w.Steps, index); cycle != "" {
+		return fmt.Errorf("adım grafiğinde döngü politikası olmayan bir döngü var: %s", cycle)
+	}
+	return nil
+}
+
+// successors adım tamamlandığında kuyruğa alınacak sonraki adımları döndürür
+func successors(step *StepDefinition) []string {
+	if step.FanOut || len(step.NextSteps) < 2 {
+		return step.NextSteps
+	}
+	return step.NextSteps[:1]
+}
+
+// forwardEdges adımın ileri yöndeki tüm geçişlerini döndürür
+func forwardEdges(step *StepDefinition) []string {
+	edges := append([]string(nil), step.NextSteps...)
+	if step.Type == StepTypeSignal {
+		if timeoutStep, ok := step.Config[SignalConfigTimeoutStep].(string); ok && timeoutStep != "" {
+			edges = append(edges, timeoutStep)
+		}
+	}
+	return edges
+}
+
+// validateLoop döngü politikasını doğrular; hedef adım ileri geçişlerle döngüyü
+// kapatan adıma ulaşabilmelidir
+func validateLoop(step *StepDefinition, index map[string]*StepDefinition) error {
+	loop := step.Loop
+	if loop.Condition == "" {
+		return fmt.Errorf("adım %s için döngü koşulu yok", step.ID)
+	}
+	if loop.MaxIterations < 1 {
+		return fmt.Errorf("adım %s için döngü yineleme sınırı pozitif olmalı", step.ID)
+	}
+	if _, ok := index[loop.Target]; !ok {
+		return fmt.Errorf("adım %s bilinmeyen döngü hedefine dönüyor: %s", step.ID, loop.Target)
+	}
+	if !reachable(loop.Target, step.ID, index) {
+		return fmt.Errorf("döngü hedefi %s, %s adımına ulaşamıyor", loop.Target, step.ID)
+	}
+	return nil
+}
+
+// reachable from adımından to adımına ileri geçişlerle ulaşılabilir mi döndürür
+func reachable(from, to string, index map[string]*StepDefinition) bool {
+	visited := make(map[string]bool)
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		if step, ok := index[id]; ok {
+			stack = append(stack, forwardEdges(step)...)
+		}
+	}
+	return false
+}
+
+// joinAncestors birden fazla girişi olan (ileri geçiş veya döngü dönüşüyle
+// ulaşılan) birleşme adımlarını, onlara ileri geçişlerle ulaşabilen adımlara göre
+// indeksler: sonuç her adım için o adımdan ulaşılabilen birleşme adımlarını
+// tutar. Tanım başına bir kez hesaplanır; hazır kuyruğu birleşme adımlarını bu
+// indeksle tutulan sayaçlara göre erteler.
+func joinAncestors(index map[string]*StepDefinition) map[string][]string {
+	inDegree := make(map[string]int)
+	parents := make(map[string][]string)
+	for id, step := range index {
+		for _, next := range forwardEdges(step) {
+			inDegree[next]++
+			parents[next] = append(parents[next], id)
+		}
+		if step.Loop != nil {
+			inDegree[step.Loop.Target]++
+		}
+	}
+
+	joins := make(map[string][]string)
+	for join, degree := range inDegree {
+		if degree < 2 {
+			continue
+		}
+		visited := map[string]bool{join: true}
+		stack := append([]string(nil), parents[join]...)
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			joins[id] = append(joins[id], join)
+			stack = append(stack, parents[id]...)
+		}
+	}
+	return joins
+}
+
+// findCycle ileri geçişlerden oluşan grafikte bir döngü arar ve bulunan
+// döngünün yolunu döndürür
+func findCycle(steps []StepDefinition, index map[string]*StepDefinition) string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	color := make(map[string]int, len(steps))
+
+	for i := range steps {
+		if color[steps[i].ID] != unvisited {
+			continue
+		}
+
+		// Derin grafiklerde yığın taşmasını önlemek için yinelemeli DFS
+		type frame struct {
+			id    string
+			edges []string
+			next  int
+		}
+		path := []frame{{id: steps[i].ID, edges: forwardEdges(&steps[i])}}
+		color[steps[i].ID] = visiting
+		for len(path) > 0 {
+			top := &path[len(path)-1]
+			edges := top.edges
+			if top.next >= len(edges) {
+				color[top.id] = done
+				path = path[:len(path)-1]
+				continue
+			}
+			next := edges[top.next]
+			top.next++
+
+			switch color[next] {
+			case visiting:
+				cycle := next
+				for j := len(path) - 1; j >= 0 && path[j].id != next; j-- {
+					cycle = path[j].id + " -> " + cycle
+				}
+				return next + " -> " + cycle
+			case unvisited:
+				color[next] = visiting
+				path = append(path, frame{id: next, edges: forwardEdges(index[next])})
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,228 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Yol ifadelerinin kök önekleri
+const (
+	pathContext = "context"
+	pathSteps   = "steps"
+)
+
+// resolvePath "context.<anahtar>..." veya "steps.<adım>..." biçimindeki bir yolu durum üzerinde çözer.
+// Kökten sonraki parçalar haritalarda anahtar, yapılarda alan, dizilerde indeks olarak yorumlanır.
+func (s *WorkflowState) resolvePath(path string) (interface{}, error) {
+	parts := strings.Split(strings.TrimSpace(path), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("geçersiz yol: %s", path)
+	}
+
+	var current interface{}
+	switch parts[0] {
+	case pathContext:
+		value, ok := s.Context[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("bağlamda anahtar bulunamadı: %s", parts[1])
+		}
+		current = value
+	case pathSteps:
+		value, ok := s.StepResults[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("adım sonucu bulunamadı: %s", parts[1])
+		}
+		current = value
+	default:
+		return nil, fmt.Errorf("bilinmeyen yol kökü: %s", parts[0])
+	}
+
+	for _, part := range parts[2:] {
+		next, ok := lookupField(current, part)
+		if !ok {
+			return nil, fmt.Errorf("yol çözülemedi: %s (%s)", path, part)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// lookupField bir değerin içinden adı verilen alanı, anahtarı veya indeksi okur
+func lookupField(value interface{}, name string) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		item := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return nil, false
+		}
+		return item.Interface(), true
+	case reflect.Struct:
+		field := v.FieldByName(name)
+		if !field.IsValid() || !field.CanInterface() {
+			return nil, false
+		}
+		return field.Interface(), true
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(name)
+		if err != nil || index < 0 || index >= v.Len() {
+			return nil, false
+		}
+		return v.Index(index).Interface(), true
+	}
+	return nil, false
+}
+
+// conditionOperators koşul ifadelerinde desteklenen karşılaştırma işleçleridir;
+// aynı konumda iki karakterli işleçler tek karakterlilerden önce eşleşir
+var conditionOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// evalCondition bir koşul ifadesini durum üzerinde değerlendirir. Desteklenen biçimler:
+//
+//	steps.review.NeedsRevision          // yolun değeri doğruysa
+//	!steps.review.Approved              // yolun değeri yanlışsa
+//	steps.review.Score < 8              // sayısal karşılaştırma
+//	context.status == "draft"           // eşitlik; sağ taraf değişmez veya yol olabilir
+func (s *WorkflowState) evalCondition(expr string) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if i, op := findOperator(expr); i >= 0 {
+		left, err := s.resolvePath(expr[:i])
+		if err != nil {
+			return false, err
+		}
+		right, err := s.resolveOperand(expr[i+len(op):])
+		if err != nil {
+			return false, err
+		}
+		return compareValues(left, right, op)
+	}
+
+	if strings.HasPrefix(expr, "!") {
+		value, err := s.resolvePath(expr[1:])
+		if err != nil {
+			return false, err
+		}
+		return !truthy(value), nil
+	}
+
+	value, err := s.resolvePath(expr)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// findOperator ifadedeki en soldaki karşılaştırma işlecini ve konumunu döndürür
+func findOperator(expr string) (int, string) {
+	index, operator := -1, ""
+	for _, op := range conditionOperators {
+		if i := strings.Index(expr, op); i >= 0 && (index < 0 || i < index) {
+			index, operator = i, op
+		}
+	}
+	return index, operator
+}
+
+// resolveOperand karşılaştırmanın sağ tarafını değişmez veya yol olarak çözer
+func (s *WorkflowState) resolveOperand(raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case raw == "null" || raw == "nil":
+		return nil, nil
+	case len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0]:
+		return raw[1 : len(raw)-1], nil
+	}
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n, nil
+	}
+	return s.resolvePath(raw)
+}
+
+// compareValues iki değeri işlece göre karşılaştırır
+func compareValues(left, right interface{}, op string) (bool, error) {
+	lf, lok := toFloat(left)
+	rf, rok := toFloat(right)
+	if lok && rok {
+		switch op {
+		case "==":
+			return lf == rf, nil
+		case "!=":
+			return lf != rf, nil
+		case ">":
+			return lf > rf, nil
+		case "<":
+			return lf < rf, nil
+		case ">=":
+			return lf >= rf, nil
+		case "<=":
+			return lf <= rf, nil
+		}
+	}
+
+	switch op {
+	case "==":
+		return reflect.DeepEqual(normalizeValue(left), normalizeValue(right)), nil
+	case "!=":
+		return !reflect.DeepEqual(normalizeValue(left), normalizeValue(right)), nil
+	}
+	return false, fmt.Errorf("%s işleci sayısal olmayan değerlerle kullanılamaz: %v, %v", op, left, right)
+}
+
+// normalizeValue adlandırılmış metin tiplerini karşılaştırma için düz metne çevirir
+func normalizeValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.IsValid() && v.Kind() == reflect.String {
+		return v.String()
+	}
+	return value
+}
+
+// toFloat sayısal bir değeri float64'e çevirir
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// truthy bir değerin koşul olarak doğru sayılıp sayılmadığını döndürür
+func truthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() > 0
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil()
+	}
+	if f, ok := toFloat(value); ok {
+		return f != 0
+	}
+	return true
+}
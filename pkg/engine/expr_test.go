@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestEvalCondition(t *testing.T) {
+	state := &WorkflowState{
+		Context: map[string]interface{}{
+			"status":   "draft",
+			"attempts": 2,
+			"tags":     []string{"urgent"},
+			"limit":    3.0,
+		},
+		StepResults: map[string]interface{}{
+			"review": &review{NeedsRevision: true, Score: 7},
+			"empty":  nil,
+		},
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{"steps.review.NeedsRevision", true},
+		{"!steps.review.NeedsRevision", false},
+		{"steps.review.Score < 8", true},
+		{"steps.review.Score >= 8", false},
+		{"steps.review.Score == 7", true},
+		{"context.status == \"draft\"", true},
+		{"context.status != 'draft'", false},
+		{"context.attempts < context.limit", true},
+		{"context.tags", true},
+		{"context.tags.0 == \"urgent\"", true},
+		{"steps.empty", false},
+	}
+
+	for _, tt := range tests {
+		actual, err := state.evalCondition(tt.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.expected, actual)
+		}
+	}
+
+	for _, expr := range []string{"steps.missing", "context.status > 3", "unknown.path"} {
+		if _, err := state.evalCondition(expr); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecisionConfigBranches karar adımının dal etiketlerini tutan yapılandırma
+// anahtarıdır: {"branches": {"<sonraki adım>": "<etiket>"}}. Yalnızca grafik
+// çıktısında kullanılır; etiketi olmayan dal sırasıyla numaralanır. Motor
+// etiketleri değerlendirmez: karar adımı da diğer adımlar gibi ilk sonraki
+// adımla, WithFanOut ile işaretlenmişse tüm sonraki adımlarla devam eder.
+const DecisionConfigBranches = "branches"
+
+// GraphOption grafik çıktısının seçeneğini temsil eder
+type GraphOption func(*graphOptions)
+
+// graphOptions grafik çıktısının seçenekleridir
+type graphOptions struct {
+	// statuses adımların örnekteki durumudur; verilmişse düğümler boyanır
+	statuses map[string]WorkflowStatus
+}
+
+// WithStatusOverlay düğümleri örneğin adım durumlarına göre boyar; olay
+// incelemelerinde örneğin nerede durduğunu grafikte gösterir
+func WithStatusOverlay(state WorkflowState) GraphOption {
+	return func(o *graphOptions) {
+		o.statuses = state.StepStatuses()
+	}
+}
+
+// StepStatuses adımların örnekteki durumunu döndürür: sonucu kaydedilmiş
+// adımlar tamamlanmış, sıradaki adımlar bekleyen sayılır; mevcut adım örneğin
+// durumunu alır. Henüz ulaşılmamış adımlar haritada yer almaz.
+func (s WorkflowState) StepStatuses() map[string]WorkflowStatus {
+	statuses := make(map[string]WorkflowStatus, len(s.StepResults)+len(s.PendingSteps)+1)
+	for id := range s.StepResults {
+		statuses[id] = StatusCompleted
+	}
+	for _, id := range s.PendingSteps {
+		statuses[id] = StatusPending
+	}
+	if s.CurrentStepID != "" {
+		statuses[s.CurrentStepID] = s.Status
+	}
+	return statuses
+}
+
+// overlayColors durumların dolgu ve kenar renkleridir
+var overlayColors = map[WorkflowStatus][2]string{
+	StatusPending:   {"#f8f9fa", "#6c757d"},
+	StatusRunning:   {"#cce5ff", "#004085"},
+	StatusWaiting:   {"#fff3cd", "#856404"},
+	StatusPaused:    {"#fff3cd", "#856404"},
+	StatusCompleted: {"#d4edda", "#155724"},
+	StatusFailed:    {"#f8d7da", "#721c24"},
+	StatusCanceled:  {"#e2e3e5", "#383d41"},
+}
+
+// dotShapes adım tiplerinin DOT düğüm biçimleridir
+var dotShapes = map[StepType]string{
+	StepTypeTask:     "box",
+	StepTypeProcess:  "component",
+	StepTypeApproval: "house",
+	StepTypeDecision: "diamond",
+	StepTypeTimer:    "circle",
+	StepTypeSignal:   "cds",
+	StepTypeMap:      "box3d",
+}
+
+// mermaidShapes adım tiplerinin Mermaid düğüm ayraçlarıdır
+var mermaidShapes = map[StepType][2]string{
+	StepTypeTask:     {"[", "]"},
+	StepTypeProcess:  {"[[", "]]"},
+	StepTypeApproval: {"[/", "/]"},
+	StepTypeDecision: {"{", "}"},
+	StepTypeTimer:    {"((", "))"},
+	StepTypeSignal:   {">", "]"},
+	StepTypeMap:      {"{{", "}}"},
+}
+
+// graphEdge iki adım arasındaki bir geçiştir; back döngü politikasının geri dönüşüdür
+type graphEdge struct {
+	from, to string
+	label    string
+	back     bool
+}
+
+// ToDOT adım grafiğini Graphviz DOT biçiminde döndürür. Düğümlerin biçimi adım
+// tipini, etiketleri yeniden deneme ve zaman aşımı ayarlarını gösterir; karar
+// dalları, sinyal zaman aşımı ve döngü geri dönüşleri etiketli kenarlardır.
+func (w *WorkflowDefinition) ToDOT(opts ...GraphOption) string {
+	options := newGraphOptions(opts)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(w.ID))
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		shape, ok := dotShapes[step.Type]
+		if !ok {
+			shape = "box"
+		}
+		attrs := []string{
+			"label=" + dotQuote(strings.Join(stepLabel(step), "\n")),
+			"shape=" + shape,
+		}
+		if colors, ok := overlayColors[options.statuses[step.ID]]; ok {
+			attrs = append(attrs, "style=filled", "fillcolor="+dotQuote(colors[0]), "color="+dotQuote(colors[1]))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(step.ID), strings.Join(attrs, ", "))
+	}
+	for _, edge := range graphEdges(w) {
+		var attrs []string
+		if edge.label != "" {
+			attrs = append(attrs, "label="+dotQuote(edge.label))
+		}
+		if edge.back {
+			attrs = append(attrs, "style=dashed", "constraint=false")
+		}
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(edge.from), dotQuote(edge.to))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ToMermaid adım grafiğini Mermaid flowchart biçiminde döndürür; içeriği ToDOT
+// ile aynıdır. Düğüm kimlikleri adımların sırasından üretilir, adım kimliği
+// etikette yer alır.
+func (w *WorkflowDefinition) ToMermaid(opts ...GraphOption) string {
+	options := newGraphOptions(opts)
+
+	ids := make(map[string]string, len(w.Steps))
+	for i := range w.Steps {
+		ids[w.Steps[i].ID] = "s" + strconv.Itoa(i+1)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	classes := make(map[WorkflowStatus][]string)
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		shape, ok := mermaidShapes[step.Type]
+		if !ok {
+			shape = mermaidShapes[StepTypeTask]
+		}
+		fmt.Fprintf(&b, "  %s%s%s%s\n", ids[step.ID], shape[0], mermaidQuote(strings.Join(stepLabel(step), "<br/>")), shape[1])
+		if status, ok := options.statuses[step.ID]; ok {
+			classes[status] = append(classes[status], ids[step.ID])
+		}
+	}
+	for _, edge := range graphEdges(w) {
+		arrow := "-->"
+		if edge.back {
+			arrow = "-.->"
+		}
+		if edge.label != "" {
+			arrow += "|" + mermaidQuote(edge.label) + "|"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[edge.from], arrow, ids[edge.to])
+	}
+
+	statuses := make([]string, 0, len(classes))
+	for status := range classes {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		colors := overlayColors[WorkflowStatus(status)]
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:%s\n", status, colors[0], colors[1])
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(classes[WorkflowStatus(status)], ","), status)
+	}
+	return b.String()
+}
+
+// newGraphOptions seçenekleri uygular
+func newGraphOptions(opts []GraphOption) graphOptions {
+	var options graphOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// stepLabel düğümün etiket satırlarını döndürür: ad, ad kimlikten farklıysa
+// kimlik, ardından yeniden deneme ve zaman aşımı ayarları
+func stepLabel(step *StepDefinition) []string {
+	lines := []string{step.ID}
+	if step.Name != "" && step.Name != step.ID {
+		lines = []string{step.Name, "(" + step.ID + ")"}
+	}
+	if step.RetryPolicy != nil && step.RetryPolicy.MaxAttempts > 1 {
+		line := fmt.Sprintf("retry: %dx", step.RetryPolicy.MaxAttempts)
+		if step.RetryPolicy.InitialInterval > 0 {
+			line += " / " + formatDuration(step.RetryPolicy.InitialInterval)
+		}
+		lines = append(lines, line)
+	}
+	if step.Timeout > 0 {
+		lines = append(lines, "timeout: "+formatDuration(step.Timeout))
+	}
+	if step.Type == StepTypeSignal {
+		if timeout, ok := step.Config[SignalConfigTimeout]; ok {
+			if d, err := parseDuration(timeout); err == nil {
+				lines = append(lines, "signal timeout: "+formatDuration(d))
+			}
+		}
+	}
+	return lines
+}
+
+// graphEdges tanımın tüm geçişlerini adımların sırasıyla döndürür; bilinmeyen
+// adımlara giden geçişler atlanır
+func graphEdges(w *WorkflowDefinition) []graphEdge {
+	known := make(map[string]bool, len(w.Steps))
+	for i := range w.Steps {
+		known[w.Steps[i].ID] = true
+	}
+
+	var edges []graphEdge
+	add := func(edge graphEdge) {
+		if known[edge.from] && known[edge.to] {
+			edges = append(edges, edge)
+		}
+	}
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		for j, next := range step.NextSteps {
+			edge := graphEdge{from: step.ID, to: next}
+			if step.Type == StepTypeDecision {
+				edge.label = branchLabel(step, next, j)
+			}
+			add(edge)
+		}
+		if step.Type == StepTypeSignal {
+			if timeoutStep, ok := step.Config[SignalConfigTimeoutStep].(string); ok && timeoutStep != "" {
+				add(graphEdge{from: step.ID, to: timeoutStep, label: "timeout"})
+			}
+		}
+		if loop := step.Loop; loop != nil {
+			label := loop.Condition
+			if loop.MaxIterations > 0 {
+				label += fmt.Sprintf(" (max %d)", loop.MaxIterations)
+			}
+			add(graphEdge{from: step.ID, to: loop.Target, label: label, back: true})
+		}
+	}
+	return edges
+}
+
+// branchLabel karar adımının index. dalının etiketini döndürür
+func branchLabel(step *StepDefinition, next string, index int) string {
+	if branches, ok := step.Config[DecisionConfigBranches].(map[string]interface{}); ok {
+		if label, ok := branches[next].(string); ok && label != "" {
+			return label
+		}
+	}
+	if branches, ok := step.Config[DecisionConfigBranches].(map[string]string); ok {
+		if label := branches[next]; label != "" {
+			return label
+		}
+	}
+	return strconv.Itoa(index + 1)
+}
+
+// formatDuration süreyi sondaki sıfır birimleri olmadan yazar (1m0s yerine 1m)
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// dotQuote metni DOT için çift tırnaklı yazar; satır sonları \n olur
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// mermaidQuote metni Mermaid için çift tırnaklı yazar; tırnaklar varlık olarak kaçırılır
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphDefinition her adım tipini, karar dallarını, sinyal zaman aşımını ve
+// bir döngüyü içeren tanımdır
+func graphDefinition() *WorkflowDefinition {
+	definition := NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(NewStepDefinition("charge", "Charge", StepTypeTask).
+		WithRetryPolicy(3, time.Second, time.Minute, 2).
+		WithTimeout(30 * time.Second).
+		WithNextSteps("route"))
+	definition.AddStep(NewStepDefinition("route", "Route", StepTypeDecision).
+		WithConfig(map[string]interface{}{
+			DecisionConfigBranches: map[string]interface{}{"approval": "manual"},
+		}).
+		WithNextSteps("approval", "pack"))
+	definition.AddStep(NewStepDefinition("approval", `Say "yes"`, StepTypeSignal).
+		WithConfig(map[string]interface{}{
+			SignalConfigName:        "approval",
+			SignalConfigTimeout:     "1h",
+			SignalConfigTimeoutStep: "expire",
+		}).
+		WithNextSteps("pack"))
+	definition.AddStep(NewStepDefinition("expire", "Expire", StepTypeTask))
+	definition.AddStep(NewStepDefinition("pack", "Pack", StepTypeMap).
+		WithNextSteps("ship").
+		WithLoop("charge", "steps.pack.retry == true", 2))
+	definition.AddStep(NewStepDefinition("ship", "ship", StepTypeTimer))
+	return definition
+}
+
+func TestToDOT(t *testing.T) {
+	dot := graphDefinition().ToDOT()
+
+	for _, want := range []string{
+		`digraph "order" {`,
+		`"charge" [label="Charge\n(charge)\nretry: 3x / 1s\ntimeout: 30s", shape=box];`,
+		`"route" [label="Route\n(route)", shape=diamond];`,
+		`"approval" [label="Say \"yes\"\n(approval)\nsignal timeout: 1h", shape=cds];`,
+		`"pack" [label="Pack\n(pack)", shape=box3d];`,
+		// Adıyla aynı kimlik tekrarlanmaz
+		`"ship" [label="ship", shape=circle];`,
+		`"route" -> "approval" [label="manual"];`,
+		`"route" -> "pack" [label="2"];`,
+		`"approval" -> "expire" [label="timeout"];`,
+		`"pack" -> "charge" [label="steps.pack.retry == true (max 2)", style=dashed, constraint=false];`,
+		`"pack" -> "ship";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected %s in DOT output:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "fillcolor") {
+		t.Error("Expected no colors without an overlay")
+	}
+}
+
+func TestToMermaid(t *testing.T) {
+	mermaid := graphDefinition().ToMermaid()
+
+	for _, want := range []string{
+		"flowchart TD\n",
+		`s1["Charge<br/>(charge)<br/>retry: 3x / 1s<br/>timeout: 30s"]`,
+		`s2{"Route<br/>(route)"}`,
+		`s3>"Say #quot;yes#quot;<br/>(approval)<br/>signal timeout: 1h"]`,
+		`s5{{"Pack<br/>(pack)"}}`,
+		`s6(("ship"))`,
+		`s2 -->|"manual"| s3`,
+		`s2 -->|"2"| s5`,
+		`s3 -->|"timeout"| s4`,
+		`s5 -.->|"steps.pack.retry == true (max 2)"| s1`,
+		`s5 --> s6`,
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected %s in Mermaid output:\n%s", want, mermaid)
+		}
+	}
+	if strings.Contains(mermaid, "classDef") {
+		t.Error("Expected no classes without an overlay")
+	}
+}
+
+func TestGraphStatusOverlay(t *testing.T) {
+	state := WorkflowState{
+		CurrentStepID: "approval",
+		Status:        StatusWaiting,
+		StepResults:   map[string]interface{}{"charge": "ok", "route": nil},
+		PendingSteps:  []string{"pack"},
+	}
+	definition := graphDefinition()
+
+	dot := definition.ToDOT(WithStatusOverlay(state))
+	for _, want := range []string{
+		`"charge" [label="Charge\n(charge)\nretry: 3x / 1s\ntimeout: 30s", shape=box, style=filled, fillcolor="#d4edda"`,
+		`"approval" [label="Say \"yes\"\n(approval)\nsignal timeout: 1h", shape=cds, style=filled, fillcolor="#fff3cd"`,
+		`"pack" [label="Pack\n(pack)", shape=box3d, style=filled, fillcolor="#f8f9fa"`,
+		// Ulaşılmamış adım boyanmaz
+		`"ship" [label="ship", shape=circle];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected %s in DOT output:\n%s", want, dot)
+		}
+	}
+
+	mermaid := definition.ToMermaid(WithStatusOverlay(state))
+	for _, want := range []string{
+		"classDef completed fill:#d4edda,stroke:#155724\n  class s1,s2 completed\n",
+		"class s3 waiting\n",
+		"class s5 pending\n",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected %s in Mermaid output:\n%s", want, mermaid)
+		}
+	}
+}
+
+func TestStepStatusesOfFailedInstance(t *testing.T) {
+	state := WorkflowState{
+		CurrentStepID: "charge",
+		Status:        StatusFailed,
+		StepResults:   map[string]interface{}{"charge": "first iteration"},
+	}
+
+	// Döngüde yeniden çalışan adımın mevcut durumu önceki sonucundan önceliklidir
+	statuses := state.StepStatuses()
+	if statuses["charge"] != StatusFailed {
+		t.Errorf("Expected charge to be failed, got %s", statuses["charge"])
+	}
+	if len(statuses) != 1 {
+		t.Errorf("Expected only charge in statuses, got %v", statuses)
+	}
+}
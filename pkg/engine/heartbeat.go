@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrHeartbeatTimeout adım HeartbeatTimeout süresi boyunca kalp atışı
+// göndermediğinde denemenin hatasıdır; adımın yeniden deneme politikası uygulanır
+var ErrHeartbeatTimeout = errors.New("adım kalp atışı zaman aşımı")
+
+// Heartbeat adım fonksiyonunun hâlâ çalıştığını ve ilerlemesini bildirir.
+// details nil değilse denemenin son ilerleme bilgisi olarak saklanır; deneme
+// başarısız olup yeniden denenirse sonraki deneme bu bilgiyi
+// StepInfo.HeartbeatDetails ile alır. Her çağrı EventStepHeartbeat olarak
+// bildirilir. Adım bağlamı dışında çağrılırsa etkisizdir.
+func Heartbeat(ctx context.Context, details interface{}) {
+	if watch, ok := ctx.Value(heartbeatKey{}).(*heartbeatWatch); ok {
+		watch.beat(details)
+	}
+}
+
+// ContextWithHeartbeat Heartbeat çağrılarını onBeat'e ileten bir bağlam döndürür;
+// uzak işçiler adım fonksiyonlarının kalp atışlarını motora iletmek için kullanır
+func ContextWithHeartbeat(ctx context.Context, onBeat func(details interface{})) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, &heartbeatWatch{onBeat: onBeat})
+}
+
+type heartbeatKey struct{}
+
+// heartbeatWatch bir denemenin kalp atışlarını toplar. timeout sıfırdan büyükse
+// adım fonksiyonu başladıktan sonra son kalp atışından itibaren timeout içinde
+// yeni bir kalp atışı gelmezse deneme iptal edilir.
+type heartbeatWatch struct {
+	clock   Clock
+	timeout time.Duration
+	onBeat  func(details interface{})
+
+	mutex    sync.Mutex
+	deadline time.Time
+	started  chan struct{}
+	once     sync.Once
+}
+
+// heartbeatWatchFrom bağlamdaki kalp atışı izleyicisini döndürür
+func heartbeatWatchFrom(ctx context.Context) *heartbeatWatch {
+	watch, _ := ctx.Value(heartbeatKey{}).(*heartbeatWatch)
+	return watch
+}
+
+// start adım fonksiyonu çalışmaya başladığında süreyi başlatır; havuz ve hız
+// sınırı beklemeleri kalp atışı süresine sayılmaz
+func (w *heartbeatWatch) start() {
+	if w.started == nil {
+		return
+	}
+	w.once.Do(func() {
+		w.mutex.Lock()
+		w.deadline = w.clock.Now().Add(w.timeout)
+		w.mutex.Unlock()
+		close(w.started)
+	})
+}
+
+// beat süreyi yeniler ve ayrıntıları iletir
+func (w *heartbeatWatch) beat(details interface{}) {
+	if w.started != nil {
+		w.mutex.Lock()
+		w.deadline = w.clock.Now().Add(w.timeout)
+		w.mutex.Unlock()
+	}
+	if w.onBeat != nil {
+		w.onBeat(details)
+	}
+}
+
+// watch süre dolana kadar bekler ve denemeyi ErrHeartbeatTimeout ile iptal eder.
+// Süre her kalp atışında uzadığından uyanınca bitiş yeniden kontrol edilir.
+func (w *heartbeatWatch) watch(ctx context.Context, cancel context.CancelCauseFunc) {
+	select {
+	case <-w.started:
+	case <-ctx.Done():
+		return
+	}
+	for {
+		w.mutex.Lock()
+		remaining := w.deadline.Sub(w.clock.Now())
+		w.mutex.Unlock()
+		if remaining <= 0 {
+			cancel(ErrHeartbeatTimeout)
+			return
+		}
+		select {
+		case <-w.clock.After(remaining):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// withHeartbeats denemenin bağlamına kalp atışı izleyicisini ekler. Kalp atışı
+// ayrıntıları denemenin ilerlemesi olarak saklanır ve gözlemcilere bildirilir;
+// adımın HeartbeatTimeout süresi varsa izleyici denemeyi bu süreye göre iptal
+// eder. Dönen fonksiyon deneme bitince çağrılmalıdır.
+func (r *WorkflowRuntime) withHeartbeats(ctx context.Context, step *StepDefinition) (context.Context, context.CancelFunc) {
+	watch := &heartbeatWatch{
+		clock:   r.engine.clock,
+		timeout: step.HeartbeatTimeout,
+		onBeat: func(details interface{}) {
+			now := r.engine.clock.Now()
+			if details != nil {
+				r.mutex.Lock()
+				if r.progress == nil {
+					r.progress = make(map[string]interface{})
+				}
+				r.progress[step.ID] = details
+				r.mutex.Unlock()
+			}
+			r.engine.notifyObservers(Event{
+				Type:       EventStepHeartbeat,
+				InstanceID: r.id,
+				WorkflowID: r.definition.ID,
+				StepID:     step.ID,
+				Data:       details,
+				Timestamp:  now,
+			})
+		},
+	}
+	ctx, cancel := context.WithCancelCause(context.WithValue(ctx, heartbeatKey{}, watch))
+	if step.HeartbeatTimeout > 0 {
+		watch.started = make(chan struct{})
+		go watch.watch(ctx, cancel)
+	}
+	return ctx, func() { cancel(nil) }
+}
+
+// lastHeartbeatLocked adımın son kalp atışı ayrıntılarını döndürür; çalışan
+// denemenin ilerlemesi yoksa önceki denemelerden kalan ayrıntılar kullanılır.
+// Çağıran kilidi tutmalıdır.
+func (r *WorkflowRuntime) lastHeartbeatLocked(stepID string) interface{} {
+	if details, ok := r.progress[stepID]; ok {
+		return details
+	}
+	return r.state.HeartbeatDetails[stepID]
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTranscodeDefinition kalp atışı süresi olan tek adımlı bir tanım oluşturur
+func newTranscodeDefinition(maxAttempts int) *WorkflowDefinition {
+	return singleStepDefinition("transcode", NewStepDefinition("transcode", "Transcode", StepTypeTask).
+		WithHeartbeatTimeout(time.Minute).
+		WithRetryPolicy(maxAttempts, 0, 0, 1))
+}
+
+func TestHeartbeatTimeoutRetriesWithLastDetails(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	if err := engine.RegisterDefinition(ctx, newTranscodeDefinition(2)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	beat := make(chan struct{})
+	var resumed interface{}
+	engine.RegisterStep("transcode", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := StepInfoFromContext(ctx)
+		if info.Attempt == 1 {
+			// İlk deneme ilerleme bildirdikten sonra takılır
+			Heartbeat(ctx, map[string]interface{}{"offset": 10})
+			close(beat)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		resumed = info.HeartbeatDetails
+		return "transcoded", nil
+	})
+
+	done := make(chan *WorkflowRuntime, 1)
+	go func() {
+		runtime, _ := engine.StartWorkflow(ctx, "transcode", nil)
+		done <- runtime
+	}()
+	<-beat
+	clock.Advance(time.Minute)
+
+	runtime := <-done
+	if state := runtime.GetState(); state.Status != StatusCompleted || len(state.HeartbeatDetails) != 0 {
+		t.Errorf("Expected completed instance without leftover details, got %s %v", state.Status, state.HeartbeatDetails)
+	}
+	if !reflect.DeepEqual(resumed, map[string]interface{}{"offset": 10}) {
+		t.Errorf("Second attempt should resume from the last heartbeat, got %v", resumed)
+	}
+
+	// Zaman aşımı ve ayrıntılar geçmişe yazılır
+	history, err := engine.History(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	var retried *HistoryEvent
+	for i := range history {
+		if history[i].Type == HistoryStepRetried {
+			retried = &history[i]
+		}
+	}
+	if retried == nil || retried.Details == nil || retried.Error != ErrHeartbeatTimeout.Error()+": transcode" {
+		t.Errorf("Expected retry event with heartbeat details, got %+v", retried)
+	}
+}
+
+func TestHeartbeatsKeepLongStepAlive(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	if err := engine.RegisterDefinition(ctx, newTranscodeDefinition(1)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	progress := make(chan interface{}, 8)
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventStepHeartbeat {
+			progress <- event.Data
+		}
+	})
+	proceed := make(chan struct{})
+	engine.RegisterStep("transcode", func(ctx context.Context, data interface{}) (interface{}, error) {
+		for percent := 25; percent <= 75; percent += 25 {
+			Heartbeat(ctx, percent)
+			select {
+			case <-proceed:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return "transcoded", nil
+	})
+
+	done := make(chan *WorkflowRuntime, 1)
+	go func() {
+		runtime, _ := engine.StartWorkflow(ctx, "transcode", nil)
+		done <- runtime
+	}()
+
+	// Kalp atışları arasında süre dolmadığından adım toplamda süreyi aşsa da çalışır
+	for _, percent := range []int{25, 50, 75} {
+		if got := <-progress; got != percent {
+			t.Errorf("Expected progress %d, got %v", percent, got)
+		}
+		clock.Advance(40 * time.Second)
+		proceed <- struct{}{}
+	}
+
+	runtime := <-done
+	if state := runtime.GetState(); state.Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s (%v)", state.Status, state.Error)
+	}
+}
+
+func TestHeartbeatTimeoutIgnoresQueueWait(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	runtime := NewWorkflowRuntime(engine, newTranscodeDefinition(1))
+	step := runtime.stepByID("transcode")
+
+	ctx, stop := runtime.withHeartbeats(context.Background(), step)
+	defer stop()
+
+	// Adım fonksiyonu başlamadan geçen süre sayılmaz
+	clock.Advance(2 * time.Minute)
+	watch := heartbeatWatchFrom(ctx)
+	watch.start()
+	clock.Advance(59 * time.Second)
+	select {
+	case <-ctx.Done():
+		t.Fatalf("Attempt should not time out before the step starts, got %v", context.Cause(ctx))
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	<-ctx.Done()
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrHeartbeatTimeout) {
+		t.Errorf("Expected ErrHeartbeatTimeout, got %v", cause)
+	}
+}
+
+func TestHeartbeatOutsideStepIsNoop(t *testing.T) {
+	// Adım bağlamı dışında çağrılan Heartbeat hiçbir şey yapmaz
+	Heartbeat(context.Background(), "ignored")
+
+	definition := singleStepDefinition("transcode", NewStepDefinition("transcode", "Transcode", StepTypeTask).
+		WithHeartbeatTimeout(-time.Second))
+	if err := definition.Validate(); err == nil {
+		t.Error("Validate should reject a negative heartbeat timeout")
+	}
+}
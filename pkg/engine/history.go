@@ -0,0 +1,352 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHistoryConflict eklenen olayların sırası örneğin geçmişinin devamı olmadığında döner;
+// aynı örneği iki sürecin birlikte yürüttüğünü gösterir
+var ErrHistoryConflict = errors.New("geçmiş sırası çakışıyor")
+
+// ErrInvalidHistory geçmiş katlanamadığında veya tanımla uyuşmadığında döner
+var ErrInvalidHistory = errors.New("geçersiz geçmiş")
+
+// HistoryEventType geçmiş olayı tiplerini temsil eder
+type HistoryEventType string
+
+const (
+	HistoryWorkflowStarted   HistoryEventType = "workflow_started"
+	HistoryWorkflowCompleted HistoryEventType = "workflow_completed"
+	HistoryWorkflowFailed    HistoryEventType = "workflow_failed"
+	HistoryWorkflowCanceled  HistoryEventType = "workflow_canceled"
+	HistoryWorkflowPaused    HistoryEventType = "workflow_paused"
+	HistoryWorkflowUnpaused  HistoryEventType = "workflow_unpaused"
+	HistoryWorkflowRetried   HistoryEventType = "workflow_retried"
+
+	HistoryStepScheduled HistoryEventType = "step_scheduled"
+	HistoryStepStarted   HistoryEventType = "step_started"
+	HistoryStepCompleted HistoryEventType = "step_completed"
+	HistoryStepRetried   HistoryEventType = "step_retried"
+	HistoryStepSuspended HistoryEventType = "step_suspended"
+	HistoryStepResumed   HistoryEventType = "step_resumed"
+
+	HistoryTimerScheduled HistoryEventType = "timer_scheduled"
+	HistoryTimerFired     HistoryEventType = "timer_fired"
+
+	HistorySignalWaiting  HistoryEventType = "signal_waiting"
+	HistorySignalReceived HistoryEventType = "signal_received"
+	HistorySignalConsumed HistoryEventType = "signal_consumed"
+)
+
+// HistoryEvent bir örneğin geçmişindeki tek bir geçişi temsil eder. Olaylar
+// 1'den başlayan kesintisiz sıra numaralarıyla eklenir ve örneğin durumu
+// yalnızca bu olaylar katlanarak yeniden kurulabilir. Tipine göre kullanılmayan
+// alanlar boş kalır.
+type HistoryEvent struct {
+	Sequence  int64                  `json:"seq"`
+	Type      HistoryEventType       `json:"type"`
+	StepID    string                 `json:"step_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Context   map[string]interface{} `json:"context,omitempty"`    // workflow_started: başlangıç bağlamı; step_completed: adım değiştirdiyse yeni bağlam
+	Result    interface{}            `json:"result,omitempty"`     // step_completed: adım sonucu
+	NextSteps []string               `json:"next_steps,omitempty"` // step_completed: kuyruğa alınan adımlar
+	Iteration int                    `json:"iteration,omitempty"`  // step_completed: geçişten sonraki döngü sayacı
+	Attempt   int                    `json:"attempt,omitempty"`    // step_started, step_retried, step_suspended ve step_resumed: deneme numarası
+	Signal    *SignalRecord          `json:"signal,omitempty"`     // signal_received ve signal_consumed
+	TimerKind TimerKind              `json:"timer_kind,omitempty"` // timer_scheduled ve timer_fired
+	WakeAt    *time.Time             `json:"wake_at,omitempty"`    // bekleme olaylarında ve step_retried: uyanma zamanı
+	Error     string                 `json:"error,omitempty"`      // workflow_failed, step_retried ve step_resumed
+	Details   interface{}            `json:"details,omitempty"`    // step_retried: son kalp atışı ayrıntıları
+
+	TraceContext map[string]string `json:"trace_context,omitempty"` // workflow_started: kök span'in iz bağlamı
+}
+
+// History örneğin geçmişini depodan sıralı olarak döndürür
+func (e *WorkflowEngine) History(ctx context.Context, instanceID string) ([]HistoryEvent, error) {
+	return e.store.LoadHistory(ctx, instanceID)
+}
+
+// recordLocked olayı sıradaki numarayla geçmiş arabelleğine ekler; olaylar
+// flushHistory ile depoya yazılır. Çağıran kilidi tutmalıdır.
+func (r *WorkflowRuntime) recordLocked(event HistoryEvent) {
+	r.sequence++
+	event.Sequence = r.sequence
+	r.history = append(r.history, event)
+}
+
+// flushHistory arabellekteki geçmiş olaylarını depoya ekler. Yazma başarısız
+// olursa olaylar arabellekte kalır ve bir sonraki çağrıda yeniden denenir.
+func (r *WorkflowRuntime) flushHistory(ctx context.Context) error {
+	r.historyMutex.Lock()
+	defer r.historyMutex.Unlock()
+	return r.flushHistoryLocked(ctx)
+}
+
+// flushHistoryLocked flushHistory gibidir; çağıran historyMutex'i tutmalıdır
+func (r *WorkflowRuntime) flushHistoryLocked(ctx context.Context) error {
+	r.mutex.Lock()
+	events := r.history
+	r.history = nil
+	r.mutex.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+	if err := r.engine.store.AppendHistory(ctx, r.id, events); err != nil {
+		r.mutex.Lock()
+		r.history = append(events, r.history...)
+		r.mutex.Unlock()
+		return fmt.Errorf("geçmiş kaydedilemedi: %w", err)
+	}
+	return nil
+}
+
+// saveTransition bekleyen geçmiş olaylarını ve aynı anda alınan örnek kaydını
+// tek işlemde yazar. Yazma başarısız olursa olaylar arabellekte kalır.
+func (r *WorkflowRuntime) saveTransition(ctx context.Context, store TransitionStore) error {
+	r.historyMutex.Lock()
+	defer r.historyMutex.Unlock()
+
+	r.mutex.Lock()
+	events := r.history
+	r.history = nil
+	record := r.snapshotLocked()
+	r.mutex.Unlock()
+
+	if err := store.SaveTransition(ctx, record, events); err != nil {
+		r.mutex.Lock()
+		r.history = append(events, r.history...)
+		r.mutex.Unlock()
+		return fmt.Errorf("örnek durumu kaydedilemedi: %w", err)
+	}
+	return nil
+}
+
+// FoldHistory geçmiş olaylarını sırayla uygulayarak örneğin durumunu yeniden kurar
+func FoldHistory(events []HistoryEvent) (WorkflowState, error) {
+	folder := newHistoryFolder()
+	for _, event := range events {
+		if err := folder.apply(event); err != nil {
+			return WorkflowState{}, err
+		}
+	}
+	return *folder.state, nil
+}
+
+// historyFolder geçmiş olaylarını bir duruma uygular
+type historyFolder struct {
+	state    *WorkflowState
+	queued   map[string]bool
+	sequence int64
+}
+
+func newHistoryFolder() *historyFolder {
+	return &historyFolder{
+		state: &WorkflowState{
+			Status:      StatusPending,
+			Context:     make(map[string]interface{}),
+			StepResults: make(map[string]interface{}),
+		},
+		queued: make(map[string]bool),
+	}
+}
+
+// apply tek bir olayı duruma uygular
+func (f *historyFolder) apply(event HistoryEvent) error {
+	if event.Sequence != f.sequence+1 {
+		return fmt.Errorf("%w: %d. olay beklenirken %d geldi", ErrInvalidHistory, f.sequence+1, event.Sequence)
+	}
+	// Başlatılmadan önce yalnızca arabelleğe alınan sinyaller kaydedilebilir
+	pending := f.state.Status == StatusPending
+	if pending && event.Type != HistoryWorkflowStarted && event.Type != HistorySignalReceived {
+		return fmt.Errorf("%w: başlatılmamış örnekte %s olayı", ErrInvalidHistory, event.Type)
+	}
+	if !pending && event.Type == HistoryWorkflowStarted {
+		return fmt.Errorf("%w: örnek ikinci kez başlatıldı", ErrInvalidHistory)
+	}
+	f.sequence = event.Sequence
+
+	s := f.state
+	switch event.Type {
+	case HistoryWorkflowStarted:
+		s.Status = StatusRunning
+		s.StartedAt = event.Timestamp
+		s.Context = copyMap(event.Context)
+		s.TraceContext = copyStrings(event.TraceContext)
+
+	case HistoryStepScheduled:
+		// Sıralama kararı geçmişe yazıldığından katlama adımı kuyruktaki yerinden çıkarır
+		if f.queued[event.StepID] {
+			s.take(f.queued, event.StepID)
+		}
+		s.CurrentStepID = event.StepID
+
+	case HistoryStepStarted:
+		// Başlatılan deneme durumu değiştirmez; kurtarmada aynı deneme yeniden çalıştırılır
+
+	case HistoryStepCompleted:
+		if event.Context != nil {
+			s.Context = copyMap(event.Context)
+		}
+		s.StepResults[event.StepID] = event.Result
+		if s.StepHistory == nil {
+			s.StepHistory = make(map[string][]interface{})
+		}
+		s.StepHistory[event.StepID] = append(s.StepHistory[event.StepID], event.Result)
+		s.setLoopIteration(event.StepID, event.Iteration)
+		s.countExecution(event.StepID)
+		delete(s.Attempts, event.StepID)
+		delete(s.HeartbeatDetails, event.StepID)
+		s.enqueue(f.queued, event.NextSteps)
+		s.CurrentStepID = ""
+
+	case HistoryStepRetried:
+		if s.Attempts == nil {
+			s.Attempts = make(map[string]int)
+		}
+		s.Attempts[event.StepID] = event.Attempt
+		s.setHeartbeatDetails(event.StepID, event.Details)
+
+	case HistoryTimerScheduled, HistorySignalWaiting, HistoryStepSuspended:
+		s.Status = StatusWaiting
+		s.WakeAt = copyTime(event.WakeAt)
+
+	case HistoryTimerFired, HistoryStepResumed:
+		s.wake()
+
+	case HistorySignalReceived:
+		if event.Signal == nil {
+			return fmt.Errorf("%w: %d. olayda sinyal yok", ErrInvalidHistory, event.Sequence)
+		}
+		s.Signals = append(s.Signals, *event.Signal)
+
+	case HistorySignalConsumed:
+		if event.Signal == nil {
+			return fmt.Errorf("%w: %d. olayda sinyal yok", ErrInvalidHistory, event.Sequence)
+		}
+		s.consumeSignal(event.Signal.Name, event.StepID, event.Timestamp)
+		s.wake()
+
+	case HistoryWorkflowPaused:
+		s.Paused = true
+		if s.Status == StatusRunning {
+			s.Status = StatusPaused
+		}
+
+	case HistoryWorkflowUnpaused:
+		s.Paused = false
+		if s.Status == StatusPaused {
+			s.Status = StatusRunning
+		}
+
+	case HistoryWorkflowCompleted:
+		completedAt := event.Timestamp
+		s.Status = StatusCompleted
+		s.CompletedAt = &completedAt
+
+	case HistoryWorkflowFailed:
+		if event.StepID != "" {
+			s.CurrentStepID = event.StepID
+		}
+		s.Status = StatusFailed
+		s.Error = errors.New(event.Error)
+
+	case HistoryWorkflowRetried:
+		if s.Status != StatusFailed {
+			return fmt.Errorf("%w: başarısız olmayan örnek yeniden denendi", ErrInvalidHistory)
+		}
+		s.CurrentStepID = event.StepID
+		s.Error = nil
+		s.countExecution(event.StepID)
+		delete(s.Attempts, event.StepID)
+		delete(s.HeartbeatDetails, event.StepID)
+		s.wake()
+
+	case HistoryWorkflowCanceled:
+		completedAt := event.Timestamp
+		s.Status = StatusCanceled
+		s.WakeAt = nil
+		s.CompletedAt = &completedAt
+
+	default:
+		return fmt.Errorf("%w: bilinmeyen olay tipi: %s", ErrInvalidHistory, event.Type)
+	}
+	return nil
+}
+
+// enqueue adımları hazır kuyruğunun sonuna ekler; kuyrukta zaten bekleyen bir
+// adım ikinci kez eklenmez
+func (s *WorkflowState) enqueue(queued map[string]bool, stepIDs []string) {
+	for _, id := range stepIDs {
+		if queued[id] {
+			continue
+		}
+		queued[id] = true
+		s.PendingSteps = append(s.PendingSteps, id)
+	}
+}
+
+// take adımı hazır kuyruğundaki yerinden çıkarır
+func (s *WorkflowState) take(queued map[string]bool, stepID string) {
+	for i, id := range s.PendingSteps {
+		if id != stepID {
+			continue
+		}
+		if i == 0 {
+			// Kuyruğun başından alınan adım kopyalamadan çıkarılır
+			s.PendingSteps = s.PendingSteps[1:]
+		} else {
+			s.PendingSteps = append(s.PendingSteps[:i:i], s.PendingSteps[i+1:]...)
+		}
+		break
+	}
+	delete(queued, stepID)
+	if len(s.PendingSteps) == 0 {
+		s.PendingSteps = nil
+	}
+}
+
+// setLoopIteration adımın döngü sayacını ayarlar; sıfır sayaç silinir
+func (s *WorkflowState) setLoopIteration(stepID string, iteration int) {
+	if iteration == 0 {
+		delete(s.LoopIterations, stepID)
+		return
+	}
+	if s.LoopIterations == nil {
+		s.LoopIterations = make(map[string]int)
+	}
+	s.LoopIterations[stepID] = iteration
+}
+
+// countExecution adımın biten yürütme sayısını artırır
+func (s *WorkflowState) countExecution(stepID string) {
+	if s.Executions == nil {
+		s.Executions = make(map[string]int)
+	}
+	s.Executions[stepID]++
+}
+
+// setHeartbeatDetails adımın önceki denemelerinden kalan kalp atışı
+// ayrıntılarını ayarlar; nil ayrıntı silinir
+func (s *WorkflowState) setHeartbeatDetails(stepID string, details interface{}) {
+	if details == nil {
+		delete(s.HeartbeatDetails, stepID)
+		return
+	}
+	if s.HeartbeatDetails == nil {
+		s.HeartbeatDetails = make(map[string]interface{})
+	}
+	s.HeartbeatDetails[stepID] = details
+}
+
+// copyTime zaman işaretçisinin kopyasını döndürür
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
@@ -0,0 +1,333 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// assertFoldMatches geçmiş katlanarak kurulan durumun canlı durumla aynı olduğunu doğrular
+func assertFoldMatches(t *testing.T, engine *WorkflowEngine, runtime *WorkflowRuntime) {
+	t.Helper()
+
+	history, err := engine.History(context.Background(), runtime.ID())
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	folded, err := FoldHistory(history)
+	if err != nil {
+		t.Fatalf("FoldHistory failed: %v", err)
+	}
+
+	expected, _ := json.Marshal(runtime.GetState())
+	actual, _ := json.Marshal(folded)
+	if string(expected) != string(actual) {
+		t.Errorf("Folded state differs from live state:\nlive:   %s\nfolded: %s", expected, actual)
+	}
+}
+
+func historyTypes(events []HistoryEvent) []HistoryEventType {
+	types := make([]HistoryEventType, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func TestHistoryRecordsTransitions(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	registerPaymentSteps(engine, make(chan interface{}, 1))
+
+	runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+		SignalConfigName: "payment_received",
+	}))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", 42); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	history, err := engine.History(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	expected := []HistoryEventType{
+		HistoryWorkflowStarted,
+		HistoryStepScheduled,
+		HistoryStepStarted,
+		HistoryStepCompleted,
+		HistoryStepScheduled,
+		HistorySignalWaiting,
+		HistorySignalReceived,
+		HistorySignalConsumed,
+		HistoryStepCompleted,
+		HistoryStepScheduled,
+		HistoryStepStarted,
+		HistoryStepCompleted,
+		HistoryWorkflowCompleted,
+	}
+	types := historyTypes(history)
+	if len(types) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("Event %d: expected %s, got %s", i+1, expected[i], types[i])
+		}
+		if history[i].Sequence != int64(i+1) {
+			t.Errorf("Event %d has sequence %d", i+1, history[i].Sequence)
+		}
+	}
+
+	// Sinyal adımının sonucu yük olarak kaydedilmeli
+	if history[8].StepID != "await-payment" || history[8].Result != 42 {
+		t.Errorf("Unexpected signal step completion: %+v", history[8])
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestFoldHistoryMatchesLiveState(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("timer", func(t *testing.T) {
+		clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+		engine := NewWorkflowEngine(WithClock(clock))
+		registerTimerSteps(engine, make(chan string, 1))
+
+		runtime := NewWorkflowRuntime(engine, newTimerDefinition())
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+
+		clock.Advance(72 * time.Hour)
+		if _, err := engine.FireDueTimers(ctx); err != nil {
+			t.Fatalf("FireDueTimers failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+
+	t.Run("signal timeout branch", func(t *testing.T) {
+		clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+		engine := NewWorkflowEngine(WithClock(clock))
+		registerPaymentSteps(engine, make(chan interface{}, 1))
+
+		runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+			SignalConfigName:        "payment_received",
+			SignalConfigTimeout:     "1h",
+			SignalConfigTimeoutStep: "escalate",
+		}))
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if err := engine.Signal(ctx, runtime.ID(), "unrelated", nil); err != nil {
+			t.Fatalf("Signal failed: %v", err)
+		}
+		clock.Advance(time.Hour)
+		if _, err := engine.FireDueTimers(ctx); err != nil {
+			t.Fatalf("FireDueTimers failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+
+	t.Run("buffered signal", func(t *testing.T) {
+		engine := NewWorkflowEngine()
+		registerPaymentSteps(engine, make(chan interface{}, 1))
+
+		runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+			SignalConfigName: "payment_received",
+		}))
+		if err := engine.Signal(ctx, runtime.ID(), "payment_received", "early"); err != nil {
+			t.Fatalf("Signal failed: %v", err)
+		}
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		engine := NewWorkflowEngine()
+		registerRevisionSteps(engine, 3)
+
+		runtime := NewWorkflowRuntime(engine, newRevisionDefinition("steps.review.NeedsRevision", 5))
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+
+	t.Run("loop limit", func(t *testing.T) {
+		engine := NewWorkflowEngine()
+		registerRevisionSteps(engine, 100)
+
+		runtime := NewWorkflowRuntime(engine, newRevisionDefinition("steps.review.NeedsRevision", 3))
+		if err := runtime.Start(ctx); !errors.Is(err, ErrLoopLimitExceeded) {
+			t.Fatalf("Expected ErrLoopLimitExceeded, got %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+
+	t.Run("fan out", func(t *testing.T) {
+		engine := NewWorkflowEngine()
+		var order []string
+		registerRecorder(engine, &order, "start", "left", "right", "join")
+
+		definition := NewWorkflowDefinition("diamond", "Diamond", "")
+		definition.AddStep(NewStepDefinition("start", "Start", StepTypeTask).WithNextSteps("left", "right").WithFanOut())
+		definition.AddStep(NewStepDefinition("left", "Left", StepTypeTask).WithNextSteps("join"))
+		definition.AddStep(NewStepDefinition("right", "Right", StepTypeTask).WithNextSteps("join"))
+		definition.AddStep(NewStepDefinition("join", "Join", StepTypeTask))
+
+		runtime := NewWorkflowRuntime(engine, definition)
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+
+	t.Run("context mutation", func(t *testing.T) {
+		engine := NewWorkflowEngine()
+		engine.RegisterStep("mark", func(ctx context.Context, data interface{}) (interface{}, error) {
+			values := data.(map[string]interface{})
+			values["flag"] = true
+			values["tags"] = map[string]interface{}{"stage": "marked"}
+			return nil, nil
+		})
+		engine.RegisterStep("tag", func(ctx context.Context, data interface{}) (interface{}, error) {
+			// İç içe değerdeki değişiklik de kaydedilmeli
+			data.(map[string]interface{})["tags"].(map[string]interface{})["stage"] = "tagged"
+			return nil, nil
+		})
+		engine.RegisterStep("read", func(ctx context.Context, data interface{}) (interface{}, error) {
+			return data.(map[string]interface{})["flag"], nil
+		})
+
+		definition := NewWorkflowDefinition("mutation", "Mutation", "")
+		definition.AddStep(NewStepDefinition("mark", "Mark", StepTypeTask).WithNextSteps("tag"))
+		definition.AddStep(NewStepDefinition("tag", "Tag", StepTypeTask).WithNextSteps("read"))
+		definition.AddStep(NewStepDefinition("read", "Read", StepTypeTask))
+
+		runtime := NewWorkflowRuntime(engine, definition)
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+
+		// Bağlamı değiştirmeyen adım bağlam kaydetmez
+		history, _ := engine.History(ctx, runtime.ID())
+		for _, event := range history {
+			if event.Type == HistoryStepCompleted && (event.Context == nil) != (event.StepID == "read") {
+				t.Errorf("Unexpected context on %s completion: %v", event.StepID, event.Context)
+			}
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		engine := NewWorkflowEngine()
+		registerTimerSteps(engine, make(chan string, 1))
+
+		runtime := NewWorkflowRuntime(engine, newTimerDefinition())
+		if err := runtime.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if err := runtime.Cancel(); err != nil {
+			t.Fatalf("Cancel failed: %v", err)
+		}
+		assertFoldMatches(t, engine, runtime)
+	})
+}
+
+func TestFoldHistoryRejectsGaps(t *testing.T) {
+	now := time.Now()
+	events := []HistoryEvent{
+		{Sequence: 1, Type: HistoryWorkflowStarted, Timestamp: now},
+		{Sequence: 3, Type: HistoryStepScheduled, StepID: "a", Timestamp: now},
+	}
+	if _, err := FoldHistory(events); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("Expected ErrInvalidHistory for a sequence gap, got %v", err)
+	}
+
+	events = []HistoryEvent{{Sequence: 1, Type: HistoryStepScheduled, StepID: "a", Timestamp: now}}
+	if _, err := FoldHistory(events); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("Expected ErrInvalidHistory without workflow_started, got %v", err)
+	}
+}
+
+func TestAppendHistoryConflict(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Now()
+
+	first := []HistoryEvent{
+		{Sequence: 1, Type: HistoryWorkflowStarted, Timestamp: now},
+		{Sequence: 2, Type: HistoryStepScheduled, StepID: "a", Timestamp: now},
+	}
+	if err := store.AppendHistory(ctx, "instance", first); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	// İkinci bir yazar aynı sırayı yazmaya çalışırsa reddedilmeli
+	stale := []HistoryEvent{{Sequence: 2, Type: HistoryWorkflowCanceled, Timestamp: now}}
+	if err := store.AppendHistory(ctx, "instance", stale); !errors.Is(err, ErrHistoryConflict) {
+		t.Errorf("Expected ErrHistoryConflict, got %v", err)
+	}
+
+	history, _ := store.LoadHistory(ctx, "instance")
+	if len(history) != 2 {
+		t.Errorf("Rejected events should not be stored, got %d events", len(history))
+	}
+}
+
+func TestRecoverRebuildsStateFromHistory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	first := NewWorkflowEngine(WithStore(store))
+	registerPaymentSteps(first, make(chan interface{}, 1))
+	definition := newPaymentDefinition(map[string]interface{}{SignalConfigName: "payment_received"})
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Kayıtlı anlık görüntüyü başlangıçtaki eski haline döndür
+	record, err := store.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	record.State.CurrentStepID = "invoice"
+	record.State.Status = StatusRunning
+	record.State.StepResults = map[string]interface{}{}
+	if err := store.SaveInstance(ctx, record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+
+	second := NewWorkflowEngine(WithStore(store))
+	registerPaymentSteps(second, make(chan interface{}, 1))
+	runtimes, err := second.Recover(ctx)
+	if err != nil || len(runtimes) != 1 {
+		t.Fatalf("Recover failed: %v (%d runtimes)", err, len(runtimes))
+	}
+
+	state := runtimes[0].GetState()
+	if state.Status != StatusWaiting || state.CurrentStepID != "await-payment" {
+		t.Errorf("State should be rebuilt from history, got %s at %s", state.Status, state.CurrentStepID)
+	}
+	if state.StepResults["invoice"] != "invoiced" {
+		t.Errorf("Step results should come from history, got %v", state.StepResults)
+	}
+
+	// Kurtarılan örnek geçmişe kaldığı yerden eklemeye devam etmeli
+	if err := second.Signal(ctx, runtime.ID(), "payment_received", nil); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	assertFoldMatches(t, second, runtimes[0])
+}
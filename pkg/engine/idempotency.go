@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DefaultIdempotencyWindow idempotency anahtarlarının varsayılan geçerlilik süresidir
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// StartOption örnek başlatma seçeneğini temsil eder
+type StartOption func(*startOptions)
+
+type startOptions struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey başlatmayı bir anahtara bağlar. Aynı iş akışı için aynı
+// anahtarla geçerlilik süresi içinde başlatılmış bir örnek varsa yeni örnek
+// oluşturulmaz, mevcut örnek döner; yeniden denenen istekler böylece çift örnek
+// oluşturmaz.
+func WithIdempotencyKey(key string) StartOption {
+	return func(o *startOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// StartWorkflow kayıtlı tanımın en güncel sürümüyle input bağlamında yeni bir
+// örnek başlatır ve örnek bekleme noktasına gelene veya sona erene kadar
+// yürütür. Adım hatası örnek hata durumuna geçtikten sonra çalışma zamanıyla
+// birlikte döner. Anahtarı eşleşen bir örnek varsa yürütülmeden olduğu gibi döner.
+func (e *WorkflowEngine) StartWorkflow(ctx context.Context, workflowID string, input map[string]interface{}, opts ...StartOption) (*WorkflowRuntime, error) {
+	var options startOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	definition, err := e.definitionFor(ctx, workflowID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime, created, err := e.beginWorkflow(ctx, definition, input, options)
+	if err != nil || !created {
+		return runtime, err
+	}
+	return runtime, runtime.run(ctx)
+}
+
+// beginWorkflow anahtarla eşleşen örneği arar, yoksa yeni örneği kaydederek
+// başlatır ve örneğin yeni oluşturulup oluşturulmadığını döndürür. Arama ve
+// kayıt startMutex altında yapılır; aynı süreçte eşzamanlı gelen iki istekten
+// yalnızca biri örnek oluşturur.
+func (e *WorkflowEngine) beginWorkflow(ctx context.Context, definition *WorkflowDefinition, input map[string]interface{}, options startOptions) (*WorkflowRuntime, bool, error) {
+	if options.idempotencyKey != "" {
+		e.startMutex.Lock()
+		defer e.startMutex.Unlock()
+
+		existing, err := e.findByIdempotencyKey(ctx, definition.ID, options.idempotencyKey)
+		if err != nil || existing != nil {
+			return existing, false, err
+		}
+	}
+
+	runtime := NewWorkflowRuntime(e, definition)
+	runtime.state.Context = copyMap(input)
+	runtime.idempotencyKey = options.idempotencyKey
+	return runtime, true, runtime.begin(ctx)
+}
+
+// findByIdempotencyKey iş akışının anahtarla başlatılmış en yeni örneğini
+// geçerlilik süresi içindeyse döndürür; yoksa nil döner
+func (e *WorkflowEngine) findByIdempotencyKey(ctx context.Context, workflowID, key string) (*WorkflowRuntime, error) {
+	records, err := e.store.ListInstances(ctx, InstanceFilter{WorkflowID: workflowID, IdempotencyKey: key})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *InstanceRecord
+	for _, record := range records {
+		if latest == nil || record.CreatedAt.After(latest.CreatedAt) {
+			latest = record
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	if e.idempotencyWindow > 0 && e.clock.Now().Sub(latest.CreatedAt) >= e.idempotencyWindow {
+		return nil, nil
+	}
+	return e.runtimeFor(ctx, latest.ID)
+}
+
+// StepInfo yürütülen adım denemesini tanımlar ve adım fonksiyonuna bağlam
+// üzerinden iletilir
+type StepInfo struct {
+	InstanceID string
+	WorkflowID string
+	StepID     string
+	Attempt    int
+	// IdempotencyToken örnek, adım, yürütme ve deneme numarasından oluşur. Çökme
+	// sonrası yeniden çalıştırılan deneme aynı jetonu alır; döngüyle yeniden
+	// girilen veya Retry ile yeniden başlatılan adım yeni bir yürütme sayılır ve
+	// farklı jeton alır. Ödeme veya e-posta sağlayıcılarına yapılan çağrılar bu
+	// jetonla tekilleştirilebilir. Map adımının öğeleri jetonun sonuna öğe
+	// sırasının eklendiği kendi jetonlarını alır.
+	IdempotencyToken string
+	// Priority adımın havuz kuyruğundaki önceliğidir; map adımının öğeleri
+	// adımın önceliğini devralır
+	Priority int
+	// HeartbeatDetails önceki denemenin Heartbeat ile bildirdiği son ayrıntılardır;
+	// ilk denemede veya ayrıntı bildirilmediyse nil'dir
+	HeartbeatDetails interface{}
+	// CompletionToken adım Pending döndürdüğünde sonucu CompleteStep ile
+	// bildirmek için dış sisteme verilecek imzalı jetondur; map öğelerinde boştur
+	CompletionToken string
+}
+
+type stepInfoKey struct{}
+
+// StepInfoFromContext adım fonksiyonunun bağlamından deneme bilgisini döndürür;
+// örnek dışında doğrudan ExecuteStep ile çalıştırılan adımlar için false döner
+func StepInfoFromContext(ctx context.Context) (StepInfo, bool) {
+	info, ok := ctx.Value(stepInfoKey{}).(StepInfo)
+	return info, ok
+}
+
+// ContextWithStepInfo deneme bilgisini bağlama ekler; uzak işçiler adım
+// fonksiyonlarını motorun sağladığı bağlamla çalıştırmak için kullanır
+func ContextWithStepInfo(ctx context.Context, info StepInfo) context.Context {
+	return withStepInfo(ctx, info)
+}
+
+// withStepInfo deneme bilgisini bağlama ekler
+func withStepInfo(ctx context.Context, info StepInfo) context.Context {
+	return context.WithValue(ctx, stepInfoKey{}, info)
+}
+
+// stepInfo örneğin adım denemesi için bilgiyi oluşturur
+func (r *WorkflowRuntime) stepInfo(stepID string, attempt int) StepInfo {
+	var priority int
+	if step := r.stepByID(stepID); step != nil {
+		priority = step.Priority
+	}
+	r.mutex.RLock()
+	details := r.state.HeartbeatDetails[stepID]
+	execution := r.state.Executions[stepID] + 1
+	r.mutex.RUnlock()
+	return StepInfo{
+		InstanceID:       r.id,
+		WorkflowID:       r.definition.ID,
+		StepID:           stepID,
+		Attempt:          attempt,
+		IdempotencyToken: r.id + "/" + stepID + "/" + strconv.Itoa(execution) + "/" + strconv.Itoa(attempt),
+		Priority:         priority,
+		HeartbeatDetails: details,
+		CompletionToken: r.engine.completionToken(completionClaims{
+			InstanceID: r.id,
+			StepID:     stepID,
+			Execution:  execution,
+			Attempt:    attempt,
+		}),
+	}
+}
@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartWorkflowIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock), WithIdempotencyWindow(time.Hour))
+	attempts := registerFlakySteps(engine, 0)
+	if err := engine.RegisterDefinition(ctx, newRetryDefinition(1, 0)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	first, err := engine.StartWorkflow(ctx, "charge", map[string]interface{}{"amount": 10}, WithIdempotencyKey("share-42"))
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if first.GetState().Context["amount"] != 10 {
+		t.Errorf("Input should become the instance context, got %v", first.GetState().Context)
+	}
+
+	// Yeniden denenen istek aynı örneği döndürmeli
+	retried, err := engine.StartWorkflow(ctx, "charge", nil, WithIdempotencyKey("share-42"))
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if retried.ID() != first.ID() || *attempts != 1 {
+		t.Errorf("Duplicate start should return %s without running, got %s (%d runs)", first.ID(), retried.ID(), *attempts)
+	}
+
+	other, err := engine.StartWorkflow(ctx, "charge", nil, WithIdempotencyKey("share-43"))
+	if err != nil || other.ID() == first.ID() {
+		t.Errorf("A different key should start a new instance, got %v", err)
+	}
+
+	// Geçerlilik süresi dolan anahtar yeni örnek başlatır
+	clock.Advance(time.Hour)
+	expired, err := engine.StartWorkflow(ctx, "charge", nil, WithIdempotencyKey("share-42"))
+	if err != nil || expired.ID() == first.ID() {
+		t.Errorf("Expired key should start a new instance, got %v", err)
+	}
+	if *attempts != 3 {
+		t.Errorf("Expected three executions, got %d", *attempts)
+	}
+}
+
+func TestIdempotencyKeySurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	first := NewWorkflowEngine(WithStore(store))
+	registerFlakySteps(first, 0)
+	if err := first.RegisterDefinition(ctx, newRetryDefinition(1, 0)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	started, err := first.StartWorkflow(ctx, "charge", nil, WithIdempotencyKey("share-42"))
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	// Yeni süreç anahtarı depodaki kayıttan bulmalı
+	second := NewWorkflowEngine(WithStore(store))
+	attempts := registerFlakySteps(second, 0)
+	existing, err := second.StartWorkflow(ctx, "charge", nil, WithIdempotencyKey("share-42"))
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if existing.ID() != started.ID() || *attempts != 0 {
+		t.Errorf("Expected existing instance %s, got %s (%d runs)", started.ID(), existing.ID(), *attempts)
+	}
+	if existing.GetState().Status != StatusCompleted {
+		t.Errorf("Existing instance should be completed, got %s", existing.GetState().Status)
+	}
+}
+
+func TestStepInfoIdempotencyToken(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+
+	tokens := make([]string, 0)
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, ok := StepInfoFromContext(ctx)
+		if !ok {
+			t.Fatal("Step context should carry step info")
+		}
+		tokens = append(tokens, info.IdempotencyToken)
+		if info.Attempt == 1 {
+			return nil, context.DeadlineExceeded
+		}
+		return "charged", nil
+	})
+	engine.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+
+	instanceIDs := make(map[string]bool)
+	workflowIDs := make(map[string]bool)
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventStepStarted {
+			instanceIDs[event.InstanceID] = true
+		}
+		workflowIDs[event.WorkflowID] = true
+	})
+
+	runtime := NewWorkflowRuntime(engine, newRetryDefinition(2, 0))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Her deneme kendi jetonunu almalı
+	expected := []string{runtime.ID() + "/charge/1/1", runtime.ID() + "/charge/1/2"}
+	if len(tokens) != 2 || tokens[0] != expected[0] || tokens[1] != expected[1] {
+		t.Errorf("Expected tokens %v, got %v", expected, tokens)
+	}
+	if len(instanceIDs) != 1 || !instanceIDs[runtime.ID()] {
+		t.Errorf("Step events should carry the instance ID, got %v", instanceIDs)
+	}
+	// Yeniden deneme olayları dahil tüm olaylar tanım kimliğini taşır
+	if len(workflowIDs) != 1 || !workflowIDs[runtime.definition.ID] {
+		t.Errorf("Events should carry the workflow ID, got %v", workflowIDs)
+	}
+
+	if _, ok := StepInfoFromContext(ctx); ok {
+		t.Error("Plain context should not carry step info")
+	}
+}
+
+func TestIdempotencyTokenChangesAcrossExecutions(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	registerRevisionSteps(engine, 3)
+
+	tokens := make(map[string]int)
+	engine.RegisterStep("revise", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := StepInfoFromContext(ctx)
+		tokens[info.IdempotencyToken]++
+		return len(tokens), nil
+	})
+	engine.RegisterStep("review", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return review{NeedsRevision: len(tokens) < 3}, nil
+	})
+
+	runtime := NewWorkflowRuntime(engine, newRevisionDefinition("steps.review.NeedsRevision", 5))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Döngünün her turu ayrı bir yürütmedir ve kendi jetonunu alır
+	if len(tokens) != 3 {
+		t.Errorf("Expected three distinct loop tokens, got %v", tokens)
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestIdempotencyTokenChangesAfterManualRetry(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	registerFlakySteps(engine, 0)
+
+	tokens := make([]string, 0)
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := StepInfoFromContext(ctx)
+		tokens = append(tokens, info.IdempotencyToken)
+		if len(tokens) == 1 {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	if err := engine.RegisterDefinition(ctx, newRetryDefinition(1, 0)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	runtime, err := engine.StartWorkflow(ctx, "charge", nil)
+	if err == nil {
+		t.Fatal("Expected the first execution to fail")
+	}
+	if err := engine.Retry(ctx, runtime.ID()); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+
+	// Elle yeniden deneme deneme sayacını sıfırlasa da jeton yeni yürütmeye aittir
+	if len(tokens) != 2 || tokens[0] == tokens[1] {
+		t.Errorf("Expected distinct tokens before and after Retry, got %v", tokens)
+	}
+	retried, err := engine.runtimeFor(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("runtimeFor failed: %v", err)
+	}
+	assertFoldMatches(t, engine, retried)
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLeaseLost örneğin kiralaması başka bir düğüme geçtiğinde veya süresi
+// yenilenemeden dolduğunda döner
+var ErrLeaseLost = errors.New("örnek kiralaması kaybedildi")
+
+// DefaultClaimPollInterval sahiplenme servisinin varsayılan yoklama aralığıdır
+const DefaultClaimPollInterval = time.Second
+
+// LeaseStore aynı depoyu paylaşan birden çok sürecin çalışır örnekleri
+// kiralayarak yürütmesini sağlayan isteğe bağlı depo arayüzüdür. Kiralamayı
+// tutan düğüm örneği yürütür; süresi dolan kiralama başka bir düğüm tarafından
+// sahiplenilir. Geçmişe ekleme sırasındaki ErrHistoryConflict, süresi dolduğu
+// halde yürütmeye devam eden eski sahibin yazmalarını ayrıca reddeder.
+type LeaseStore interface {
+	// ClaimInstances kiralaması olmayan veya süresi dolmuş çalışır örneklerden en
+	// fazla limit kadarını owner adına lease süresiyle kiralar ve kimliklerini
+	// döndürür. Kiralaması hiç alınmamış örnekler son kayıtlarından lease süresi
+	// geçene kadar sahiplenilmez; böylece örneği yeni kaydeden düğüm kiralamayı
+	// almadan başka bir düğüm araya giremez.
+	ClaimInstances(ctx context.Context, owner string, lease time.Duration, limit int) ([]string, error)
+	// AcquireLease örneğin kiralamasını alır veya owner zaten sahibiyse uzatır;
+	// kiralama başka bir düğümdeyse ErrLeaseLost döner
+	AcquireLease(ctx context.Context, owner, instanceID string, lease time.Duration) error
+	// ReleaseLease owner'ın kiralamasını bırakır; örnek hemen sahiplenilebilir hale gelir
+	ReleaseLease(ctx context.Context, owner, instanceID string) error
+}
+
+// WithLeases örnekleri kiralama ile yürütmeyi açar. Depo LeaseStore arayüzünü
+// uygulamalıdır; owner düğümü küme içinde tekil olarak tanımlar. Yürütülen her
+// örneğin kiralaması lease süresinin üçte birinde bir yenilenir.
+func WithLeases(owner string, lease time.Duration) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.leaseOwner = owner
+		e.leaseDuration = lease
+	}
+}
+
+// WithClaimPollInterval sahiplenme servisinin depoyu yoklama aralığını belirler
+func WithClaimPollInterval(interval time.Duration) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.claimPollInterval = interval
+	}
+}
+
+// leaseStore kiralama açıksa deponun LeaseStore arayüzünü döndürür
+func (e *WorkflowEngine) leaseStore() (LeaseStore, bool) {
+	if e.leaseOwner == "" {
+		return nil, false
+	}
+	store, ok := e.store.(LeaseStore)
+	return store, ok
+}
+
+// holdLease örneğin kiralamasını alır ve yürütme süresince yeniler. Dönen bağlam
+// kiralama kaybedildiğinde iptal edilir; release yenilemeyi durdurup kiralamayı
+// bırakır. Kiralama kapalıysa bağlam olduğu gibi döner.
+func (e *WorkflowEngine) holdLease(ctx context.Context, instanceID string) (context.Context, func(), error) {
+	store, ok := e.leaseStore()
+	if !ok {
+		return ctx, func() {}, nil
+	}
+	if err := store.AcquireLease(ctx, e.leaseOwner, instanceID, e.leaseDuration); err != nil {
+		return ctx, nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// Yenileme motorun saatiyle zamanlanır; RunTimers gibi ManualClock ile sürülebilir
+		for {
+			select {
+			case <-done:
+				return
+			case <-e.clock.After(e.leaseDuration / 3):
+				err := store.AcquireLease(ctx, e.leaseOwner, instanceID, e.leaseDuration)
+				if err != nil && ctx.Err() == nil {
+					cancel(fmt.Errorf("%w: %v", ErrLeaseLost, err))
+					return
+				}
+			}
+		}
+	}()
+
+	release := func() {
+		close(done)
+		<-stopped
+		cancel(nil)
+		// Bırakma hatası yok sayılır; kiralama en geç süresi dolunca serbest kalır
+		_ = store.ReleaseLease(context.WithoutCancel(ctx), e.leaseOwner, instanceID)
+	}
+	return ctx, release, nil
+}
+
+// ClaimRunnable kiralaması boşta olan çalışır örneklerden en fazla limit kadarını
+// sahiplenir ve her birini bekleme noktasına veya sona ulaşana kadar yürütür;
+// sahiplenilen örnek sayısını döndürür. Sahibi ölen düğümün örnekleri
+// kiralamaları dolduktan sonra buradan devralınır.
+func (e *WorkflowEngine) ClaimRunnable(ctx context.Context, limit int) (int, error) {
+	var wg sync.WaitGroup
+	claimed, err := e.claimRunnable(ctx, limit, &wg)
+	wg.Wait()
+	return claimed, err
+}
+
+// RunClaims sahiplenme servisini ctx iptal edilene kadar çalıştırır. Birden çok
+// düğüm aynı depoyu paylaştığında her düğümde RunTimers ile birlikte çalıştırılmalıdır.
+func (e *WorkflowEngine) RunClaims(ctx context.Context, limit int) error {
+	for {
+		if _, err := e.claimRunnable(ctx, limit, nil); err != nil && ctx.Err() == nil {
+			e.diagnostics().Error("claim service failed", "error", err)
+			e.notifyObservers(Event{
+				Type:      EventClaimFailed,
+				Data:      err,
+				Timestamp: e.clock.Now(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.clock.After(e.claimPollInterval):
+		}
+	}
+}
+
+// claimRunnable sahiplenilen örnekleri ayrı goroutine'lerde yürütür
+func (e *WorkflowEngine) claimRunnable(ctx context.Context, limit int, wg *sync.WaitGroup) (int, error) {
+	store, ok := e.leaseStore()
+	if !ok {
+		return 0, fmt.Errorf("kiralama açık değil veya depo LeaseStore desteklemiyor")
+	}
+
+	ids, err := store.ClaimInstances(ctx, e.leaseOwner, e.leaseDuration, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if wg != nil {
+			wg.Add(1)
+		}
+		go func(id string) {
+			if wg != nil {
+				defer wg.Done()
+			}
+			// Adım hataları örnek durumuna yazılır; servis çalışmaya devam eder
+			_ = e.runClaimed(ctx, id)
+		}(id)
+	}
+	return len(ids), nil
+}
+
+// runClaimed sahiplenilen örneği depodan güncel haliyle yükleyip yürütür
+func (e *WorkflowEngine) runClaimed(ctx context.Context, id string) error {
+	// Bellekteki eski çalışma zamanı başka bir düğümün ilerlettiği durumu bilmez
+	e.untrackRuntime(id)
+	runtime, err := e.loadRuntime(ctx, id)
+	if err != nil {
+		return err
+	}
+	if runtime.GetState().Status != StatusRunning {
+		store, _ := e.leaseStore()
+		return store.ReleaseLease(ctx, e.leaseOwner, id)
+	}
+	return runtime.run(ctx)
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// deadNodeStore ölen bir düğümün deposunu taklit eder: dead işaretlendikten sonra
+// hiçbir yazma ve kiralama yenilemesi depoya ulaşmaz
+type deadNodeStore struct {
+	*MemoryStore
+	dead atomic.Bool
+}
+
+func (s *deadNodeStore) AppendHistory(ctx context.Context, instanceID string, events []HistoryEvent) error {
+	if s.dead.Load() {
+		return errCrashed
+	}
+	return s.MemoryStore.AppendHistory(ctx, instanceID, events)
+}
+
+func (s *deadNodeStore) SaveInstance(ctx context.Context, record *InstanceRecord) error {
+	if s.dead.Load() {
+		return errCrashed
+	}
+	return s.MemoryStore.SaveInstance(ctx, record)
+}
+
+func (s *deadNodeStore) AcquireLease(ctx context.Context, owner, instanceID string, lease time.Duration) error {
+	if s.dead.Load() {
+		return errCrashed
+	}
+	return s.MemoryStore.AcquireLease(ctx, owner, instanceID, lease)
+}
+
+func TestLeaseBlocksOtherNodes(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	first := NewWorkflowEngine(WithStore(store), WithLeases("node-a", time.Second))
+	if err := first.RegisterDefinition(ctx, newRetryDefinition(1, 0)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	first.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		close(started)
+		<-unblock
+		return "charged", nil
+	})
+	first.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+
+	done := make(chan error, 1)
+	var runtime *WorkflowRuntime
+	go func() {
+		var err error
+		runtime, err = first.StartWorkflow(ctx, "charge", nil)
+		done <- err
+	}()
+	<-started
+
+	// Kiralama canlıyken ikinci düğüm örneği sahiplenemez
+	second := NewWorkflowEngine(WithStore(store), WithLeases("node-b", time.Second))
+	if claimed, err := second.ClaimRunnable(ctx, 0); err != nil || claimed != 0 {
+		t.Errorf("Leased instance should not be claimed, got %d (%v)", claimed, err)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if runtime.GetState().Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", runtime.GetState().Status)
+	}
+}
+
+func TestLeaseRenewalUsesEngineClock(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	store := &deadNodeStore{MemoryStore: NewMemoryStore()}
+
+	engine := NewWorkflowEngine(WithStore(store), WithClock(clock), WithLeases("node-a", 30*time.Second))
+	if err := engine.RegisterDefinition(ctx, newRetryDefinition(1, 0)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	started := make(chan struct{})
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.StartWorkflow(ctx, "charge", nil)
+		done <- err
+	}()
+	<-started
+
+	// Yenileme başarısız olacak; kayıp yalnızca motorun saati ilerledikçe fark edilir
+	store.dead.Store(true)
+	deadline := time.After(time.Second)
+	for {
+		clock.Advance(10 * time.Second)
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrLeaseLost) {
+				t.Errorf("Expected ErrLeaseLost, got %v", err)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Lease renewal should follow the engine clock")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestDeadNodeInstanceIsReclaimed(t *testing.T) {
+	ctx := context.Background()
+	shared := NewMemoryStore()
+	lease := 60 * time.Millisecond
+
+	// İlk düğüm charge adımı sırasında ölür; kiralaması yenilenemez
+	dying := &deadNodeStore{MemoryStore: shared}
+	first := NewWorkflowEngine(WithStore(dying), WithLeases("node-a", lease))
+	if err := first.RegisterDefinition(ctx, newRetryDefinition(3, 0)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	first.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		dying.dead.Store(true)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	runtime, err := first.StartWorkflow(ctx, "charge", nil)
+	if !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("Dead node should lose its lease, got %v", err)
+	}
+
+	second := NewWorkflowEngine(WithStore(shared), WithLeases("node-b", lease))
+	registerFlakySteps(second, 0)
+	time.Sleep(lease)
+	claimed, err := second.ClaimRunnable(ctx, 0)
+	if err != nil || claimed != 1 {
+		t.Fatalf("Expected one reclaimed instance, got %d (%v)", claimed, err)
+	}
+
+	record, err := shared.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != StatusCompleted {
+		t.Errorf("Reclaimed instance should complete, got %s", record.State.Status)
+	}
+
+	// Yarıda kalan deneme yeni düğümde aynı numarayla yeniden çalıştırılır
+	history, _ := shared.LoadHistory(ctx, runtime.ID())
+	if attempts := chargeAttempts(history); len(attempts) != 2 || attempts[1] != 1 {
+		t.Errorf("Reclaimed attempt should keep its number, got %v", attempts)
+	}
+	for _, event := range history {
+		if event.Type == HistoryStepRetried || event.Type == HistoryWorkflowFailed {
+			t.Errorf("Lost lease should not be recorded as a failure, got %s", event.Type)
+		}
+	}
+}
+
+func TestClaimRunnableRequiresLeases(t *testing.T) {
+	engine := NewWorkflowEngine()
+	if _, err := engine.ClaimRunnable(context.Background(), 0); err == nil {
+		t.Error("ClaimRunnable should fail without leases")
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Kayıtlarda ve adım logger'ında kullanılan öznitelik anahtarları
+const (
+	LogKeyWorkflowID = "workflow_id"
+	LogKeyInstanceID = "instance_id"
+	LogKeyStepID     = "step_id"
+	LogKeyAttempt    = "attempt"
+)
+
+// WithLogger motorun tanılama kayıtlarını yazacağı logger'ı belirler. Örnek
+// yaşam döngüsü Info, yeniden denemeler ve başarısız denemeler Warn, başarısız
+// örnekler ve arka plan servislerinin hataları Error, adım başlangıç ve
+// bitişleri Debug seviyesinde yazılır. Verilmezse motor kayıt yazmaz.
+func WithLogger(logger *slog.Logger) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.logger = logger
+	}
+}
+
+// LoggerFromContext adım fonksiyonunun bağlamındaki logger'ı döndürür. Adım
+// logger'ı workflow_id, instance_id, step_id ve attempt özniteliklerini taşır;
+// motora WithLogger verilmemişse slog.Default üzerine kurulur. Adım bağlamı
+// dışında slog.Default döner.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// ContextWithLogger logger'ı bağlama ekler; uzak işçiler adım fonksiyonlarına
+// kendi adım logger'larını iletmek için kullanır
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+type loggerKey struct{}
+
+// Logger motorun tanılama logger'ını döndürür. Motoru saran paketler kendi
+// kayıtlarını aynı hedefe yazmak için kullanır; WithLogger verilmemişse dönen
+// logger kayıtları atar.
+func (e *WorkflowEngine) Logger() *slog.Logger {
+	return e.diagnostics()
+}
+
+// diagnostics motorun tanılama logger'ını döndürür; logger verilmemişse
+// kayıtlar atılır
+func (e *WorkflowEngine) diagnostics() *slog.Logger {
+	if e.logger == nil {
+		return discardLogger
+	}
+	return e.logger
+}
+
+// runtimeLogger örneğin kayıtlarını iş akışı ve örnek kimliğiyle yazan logger'ı oluşturur
+func (e *WorkflowEngine) runtimeLogger(workflowID, instanceID string) *slog.Logger {
+	return e.diagnostics().With(LogKeyWorkflowID, workflowID, LogKeyInstanceID, instanceID)
+}
+
+// stepLogger adım fonksiyonuna verilecek deneme logger'ını oluşturur
+func (r *WorkflowRuntime) stepLogger(stepID string, attempt int) *slog.Logger {
+	base := r.engine.logger
+	if base == nil {
+		base = slog.Default()
+	}
+	return base.With(
+		LogKeyWorkflowID, r.definition.ID,
+		LogKeyInstanceID, r.id,
+		LogKeyStepID, stepID,
+		LogKeyAttempt, attempt,
+	)
+}
+
+// discardLogger hiçbir kaydı yazmayan logger'dır
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler tüm seviyeleri kapalı bildiren bir slog.Handler'dır
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logAttempt denemenin sonucunu kaydeder; başarısız denemeler Warn seviyesinde yazılır
+func (r *WorkflowRuntime) logAttempt(step *StepDefinition, attempt int, started time.Time, result interface{}, err error) {
+	duration := r.engine.clock.Now().Sub(started)
+	switch {
+	case err != nil:
+		r.log.Warn("step attempt failed", LogKeyStepID, step.ID, LogKeyAttempt, attempt, "duration", duration, "error", err)
+	case result == Pending:
+		r.log.Debug("step pending", LogKeyStepID, step.ID, LogKeyAttempt, attempt, "duration", duration)
+	default:
+		r.log.Debug("step completed", LogKeyStepID, step.ID, LogKeyAttempt, attempt, "duration", duration)
+	}
+}
@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// decodeLogs JSON handler çıktısını kayıtlara ayırır
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// findLog mesajı verilen ilk kaydı döndürür
+func findLog(records []map[string]interface{}, msg string) map[string]interface{} {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestLoggerRecordsLifecycleAndRetries(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	engine := NewWorkflowEngine(WithLogger(logger))
+
+	attempts := 0
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		attempts++
+		LoggerFromContext(ctx).Info("charging card")
+		if attempts == 1 {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	definition := singleStepDefinition("order", NewStepDefinition("charge", "Charge", StepTypeTask).WithRetryPolicy(2, 0, 0, 1))
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := engine.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	records := decodeLogs(t, &buf)
+	for _, msg := range []string{"workflow started", "step started", "step attempt failed", "step retrying", "step completed", "workflow completed"} {
+		record := findLog(records, msg)
+		if record == nil {
+			t.Errorf("Expected %q in logs:\n%s", msg, buf.String())
+			continue
+		}
+		if record[LogKeyWorkflowID] != "order" || record[LogKeyInstanceID] != runtime.ID() {
+			t.Errorf("Expected instance attributes on %q, got %v", msg, record)
+		}
+	}
+
+	if failed := findLog(records, "step attempt failed"); failed != nil {
+		if failed["level"] != "WARN" || failed[LogKeyStepID] != "charge" || failed[LogKeyAttempt] != float64(1) || failed["error"] != "gateway unavailable" {
+			t.Errorf("Unexpected failed attempt record: %v", failed)
+		}
+	}
+	if retry := findLog(records, "step retrying"); retry != nil && retry[LogKeyAttempt] != float64(2) {
+		t.Errorf("Retry should log the next attempt, got %v", retry[LogKeyAttempt])
+	}
+
+	// Adım fonksiyonunun kayıtları deneme özniteliklerini taşır
+	var stepLogs []map[string]interface{}
+	for _, record := range records {
+		if record["msg"] == "charging card" {
+			stepLogs = append(stepLogs, record)
+		}
+	}
+	if len(stepLogs) != 2 {
+		t.Fatalf("Expected two step log records, got %d", len(stepLogs))
+	}
+	for i, record := range stepLogs {
+		if record[LogKeyStepID] != "charge" || record[LogKeyAttempt] != float64(i+1) || record[LogKeyInstanceID] != runtime.ID() {
+			t.Errorf("Unexpected step log record: %v", record)
+		}
+	}
+}
+
+func TestLoggerRecordsWaitingAndFailure(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	engine := NewWorkflowEngine(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	registerPaymentSteps(engine, make(chan interface{}, 1))
+	if err := engine.RegisterDefinition(ctx, newPaymentDefinition(map[string]interface{}{SignalConfigName: "payment_received"})); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := engine.StartWorkflow(ctx, "payment", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	waiting := findLog(decodeLogs(t, &buf), "workflow waiting")
+	if waiting == nil || waiting["reason"] != "signal" || waiting[LogKeyStepID] != "await-payment" {
+		t.Fatalf("Expected a waiting record for the signal, got %v", waiting)
+	}
+
+	// Sinyal sonrası sevkiyat başarısız olur
+	engine.RegisterStep("ship", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, errors.New("carrier down")
+	})
+	buf.Reset()
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", nil); err == nil {
+		t.Fatal("Expected the signal run to fail")
+	}
+	records := decodeLogs(t, &buf)
+	if findLog(records, "workflow resumed") == nil {
+		t.Errorf("Expected a resumed record, got:\n%s", buf.String())
+	}
+	failed := findLog(records, "workflow failed")
+	if failed == nil || failed["level"] != "ERROR" || failed[LogKeyStepID] != "ship" {
+		t.Errorf("Expected an error record for the failed instance, got %v", failed)
+	}
+	// Debug kayıtları varsayılan seviyede yazılmaz
+	if findLog(records, "step started") != nil {
+		t.Error("Debug records should be filtered by the handler level")
+	}
+}
+
+func TestStepLoggerFallsBackToDefault(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		LoggerFromContext(ctx).Info("charging card")
+		return "charged", nil
+	})
+	if err := engine.RegisterDefinition(ctx, singleStepDefinition("order", NewStepDefinition("charge", "Charge", StepTypeTask))); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	if _, err := engine.StartWorkflow(ctx, "order", nil); err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	// Motor logger'ı yoksa yalnızca adımın kendi kaydı yazılır
+	records := decodeLogs(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "charging card" || records[0][LogKeyStepID] != "charge" {
+		t.Errorf("Expected only the step record on the default logger, got:\n%s", buf.String())
+	}
+}
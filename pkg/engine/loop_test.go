@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type review struct {
+	NeedsRevision bool
+	Score         int
+}
+
+func newRevisionDefinition(condition string, maxIterations int) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("document", "Document Review", "")
+	definition.AddStep(NewStepDefinition("draft", "Draft", StepTypeTask).WithNextSteps("revise"))
+	definition.AddStep(NewStepDefinition("revise", "Revise", StepTypeTask).WithNextSteps("review"))
+	definition.AddStep(NewStepDefinition("review", "Review", StepTypeTask).
+		WithNextSteps("publish").
+		WithLoop("revise", condition, maxIterations))
+	definition.AddStep(NewStepDefinition("publish", "Publish", StepTypeTask))
+	return definition
+}
+
+func registerRevisionSteps(engine *WorkflowEngine, revisionsNeeded int) *int {
+	revisions := 0
+	engine.RegisterStep("draft", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "draft", nil
+	})
+	engine.RegisterStep("revise", func(ctx context.Context, data interface{}) (interface{}, error) {
+		revisions++
+		return revisions, nil
+	})
+	engine.RegisterStep("review", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return review{NeedsRevision: revisions < revisionsNeeded, Score: revisions * 3}, nil
+	})
+	engine.RegisterStep("publish", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "published", nil
+	})
+	return &revisions
+}
+
+func TestLoopRevisitsStepUntilConditionIsFalse(t *testing.T) {
+	engine := NewWorkflowEngine()
+	revisions := registerRevisionSteps(engine, 3)
+
+	iterations := make([]interface{}, 0)
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventLoopIteration {
+			iterations = append(iterations, event.Data)
+		}
+	})
+
+	runtime := NewWorkflowRuntime(engine, newRevisionDefinition("steps.review.NeedsRevision", 5))
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Fatalf("Expected completed status, got %s", state.Status)
+	}
+	if *revisions != 3 {
+		t.Errorf("Expected three revisions, got %d", *revisions)
+	}
+
+	// Her yinelemenin sonucu geçmişte tutulmalı
+	history := state.StepHistory["revise"]
+	if len(history) != 3 || history[0] != 1 || history[2] != 3 {
+		t.Errorf("Unexpected revise history: %v", history)
+	}
+	if len(state.StepHistory["review"]) != 3 {
+		t.Errorf("Expected three review results, got %d", len(state.StepHistory["review"]))
+	}
+	if state.StepResults["revise"] != 3 {
+		t.Errorf("StepResults should hold the latest iteration, got %v", state.StepResults["revise"])
+	}
+	if _, ok := state.LoopIterations["review"]; ok {
+		t.Error("Loop counter should be cleared once the loop exits")
+	}
+	if len(iterations) != 2 || iterations[0] != 2 || iterations[1] != 3 {
+		t.Errorf("Expected loop iteration events for passes 2 and 3, got %v", iterations)
+	}
+}
+
+func TestLoopComparisonCondition(t *testing.T) {
+	engine := NewWorkflowEngine()
+	revisions := registerRevisionSteps(engine, 100)
+
+	runtime := NewWorkflowRuntime(engine, newRevisionDefinition("steps.review.Score < 8", 10))
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if *revisions != 3 {
+		t.Errorf("Loop should stop once the score reaches 8, got %d revisions", *revisions)
+	}
+}
+
+func TestLoopLimitExceeded(t *testing.T) {
+	engine := NewWorkflowEngine()
+	revisions := registerRevisionSteps(engine, 100)
+
+	runtime := NewWorkflowRuntime(engine, newRevisionDefinition("steps.review.NeedsRevision", 3))
+	err := runtime.Start(context.Background())
+	if !errors.Is(err, ErrLoopLimitExceeded) {
+		t.Fatalf("Expected ErrLoopLimitExceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "review") {
+		t.Errorf("Error should name the looping step: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusFailed {
+		t.Errorf("Expected failed status, got %s", state.Status)
+	}
+	if *revisions != 3 {
+		t.Errorf("Loop body should run at most MaxIterations times, ran %d", *revisions)
+	}
+	if _, ok := state.StepResults["publish"]; ok {
+		t.Error("Publish should not run after the loop fails")
+	}
+}
+
+func TestValidateRejectsUndeclaredCycles(t *testing.T) {
+	definition := NewWorkflowDefinition("cycle", "Cycle", "")
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask).WithNextSteps("b"))
+	definition.AddStep(NewStepDefinition("b", "B", StepTypeTask).WithNextSteps("c"))
+	definition.AddStep(NewStepDefinition("c", "C", StepTypeTask).WithNextSteps("a"))
+
+	err := definition.Validate()
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Fatalf("Expected cycle error with path, got %v", err)
+	}
+
+	runtime := NewWorkflowRuntime(NewWorkflowEngine(), definition)
+	if err := runtime.Start(context.Background()); err == nil {
+		t.Error("Start should reject a definition with an undeclared cycle")
+	}
+}
+
+func TestValidateLoopPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		loop *LoopPolicy
+	}{
+		{"missing condition", &LoopPolicy{Target: "revise", MaxIterations: 3}},
+		{"missing limit", &LoopPolicy{Target: "revise", Condition: "steps.review.NeedsRevision"}},
+		{"unknown target", &LoopPolicy{Target: "missing", Condition: "steps.review.NeedsRevision", MaxIterations: 3}},
+		{"forward target", &LoopPolicy{Target: "publish", Condition: "steps.review.NeedsRevision", MaxIterations: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition := newRevisionDefinition("steps.review.NeedsRevision", 3)
+			definition.Steps[2].Loop = tt.loop
+			if err := definition.Validate(); err == nil {
+				t.Error("Validate should reject the loop policy")
+			}
+		})
+	}
+
+	if err := newRevisionDefinition("steps.review.NeedsRevision", 3).Validate(); err != nil {
+		t.Errorf("Valid loop should pass validation: %v", err)
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	definition := NewWorkflowDefinition("refs", "Refs", "")
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask).WithNextSteps("missing"))
+	if err := definition.Validate(); err == nil {
+		t.Error("Validate should reject unknown next steps")
+	}
+
+	definition = NewWorkflowDefinition("dup", "Dup", "")
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask))
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask))
+	if err := definition.Validate(); err == nil {
+		t.Error("Validate should reject duplicate step IDs")
+	}
+}
@@ -0,0 +1,268 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Map adımı yapılandırma anahtarları.
+//
+//	{"items": "steps.fetch-users.Users", "step": "notify-user", "parallelism": 4}
+//	{"items": "context.files", "workflow": "process-file", "failure_policy": "continue"}
+//	{"items": "context.files", "step": "scan", "failure_policy": "tolerate", "max_failures": 2}
+//
+// "step" her öğe için kayıtlı bir StepFunc'ı öğenin kendisiyle çağırır. "workflow"
+// her öğe için kayıtlı tanımdan bir alt iş akışı başlatır; öğe alt akışın
+// bağlamına "item", sırası "index" anahtarıyla yazılır.
+const (
+	MapConfigItems         = "items"
+	MapConfigStep          = "step"
+	MapConfigWorkflow      = "workflow"
+	MapConfigParallelism   = "parallelism"
+	MapConfigFailurePolicy = "failure_policy"
+	MapConfigMaxFailures   = "max_failures"
+)
+
+// MapFailurePolicy map adımında öğe hatalarının nasıl ele alınacağını belirler
+type MapFailurePolicy string
+
+const (
+	// MapFailFast ilk hatada kalan öğeleri iptal eder ve adımı başarısız sayar
+	MapFailFast MapFailurePolicy = "fail_fast"
+	// MapContinue tüm öğeleri çalıştırır ve hataları sonuçta toplar
+	MapContinue MapFailurePolicy = "continue"
+	// MapTolerate hata sayısı max_failures değerini aşana kadar devam eder
+	MapTolerate MapFailurePolicy = "tolerate"
+)
+
+// MapResult map adımının sonucunu temsil eder; Results öğe sırasını korur ve
+// başarısız öğelerin yerinde nil bulunur
+type MapResult struct {
+	Results []interface{}  `json:"results"`
+	Errors  []MapItemError `json:"errors,omitempty"`
+}
+
+// MapItemError bir öğenin hatasını temsil eder
+type MapItemError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// mapConfig çözülmüş map adımı yapılandırmasıdır
+type mapConfig struct {
+	stepID      string
+	workflowID  string
+	parallelism int
+	policy      MapFailurePolicy
+	maxFailures int
+}
+
+// executeMap koleksiyondaki her öğe için adımı veya alt iş akışını sınırlı
+// eşzamanlılıkla çalıştırır ve sıralı sonuçları döndürür
+func (r *WorkflowRuntime) executeMap(ctx context.Context, step *StepDefinition) (interface{}, error) {
+	config, err := parseMapConfig(step)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsPath, _ := step.Config[MapConfigItems].(string)
+	r.mutex.RLock()
+	collection, err := r.state.resolvePath(itemsPath)
+	r.mutex.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("map koleksiyonu çözülemedi (%s): %w", step.ID, err)
+	}
+	items, err := toItems(collection)
+	if err != nil {
+		return nil, fmt.Errorf("map koleksiyonu geçersiz (%s): %w", step.ID, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := &MapResult{Results: make([]interface{}, len(items))}
+	var (
+		mutex    sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	indexes := make(chan int)
+	workers := config.parallelism
+	if workers > len(items) {
+		workers = len(items)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				value, err := r.executeMapItem(ctx, config, i, items[i])
+
+				mutex.Lock()
+				if err == nil {
+					result.Results[i] = value
+					mutex.Unlock()
+					continue
+				}
+				result.Errors = append(result.Errors, MapItemError{Index: i, Error: err.Error()})
+				if firstErr == nil && config.exceeded(len(result.Errors)) {
+					firstErr = fmt.Errorf("map öğesi başarısız (%s[%d]): %w", step.ID, i, err)
+					cancel()
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Index < result.Errors[j].Index
+	})
+	return result, nil
+}
+
+// executeMapItem tek bir öğeyi çalıştırır
+func (r *WorkflowRuntime) executeMapItem(ctx context.Context, config mapConfig, index int, item interface{}) (interface{}, error) {
+	if info, ok := StepInfoFromContext(ctx); ok {
+		ctx = withStepInfo(ctx, info.mapItem(index))
+	}
+	if config.stepID != "" {
+		return r.engine.ExecuteStep(ctx, config.stepID, item)
+	}
+
+	definition, err := r.engine.definitionFor(ctx, config.workflowID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	child := NewWorkflowRuntime(r.engine, definition)
+	child.state.Context["item"] = item
+	child.state.Context["index"] = index
+	if err := child.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	state := child.GetState()
+	if state.Status != StatusCompleted {
+		// Bekleme noktasına ulaşan alt akışlar map adımında desteklenmez
+		_ = child.Cancel()
+		return nil, fmt.Errorf("alt iş akışı tamamlanmadı (%s): %s", child.id, state.Status)
+	}
+	return state.StepResults, nil
+}
+
+// mapItem map adımının deneme bilgisinden öğe için bilgi türetir. Jeton öğenin
+// sırasıyla ayrılır; öğeler askıya alınamadığından tamamlama jetonu verilmez.
+func (info StepInfo) mapItem(index int) StepInfo {
+	info.IdempotencyToken += "/" + strconv.Itoa(index)
+	info.CompletionToken = ""
+	return info
+}
+
+// exceeded hata sayısının politikaya göre adımı başarısız kılıp kılmadığını döndürür
+func (c mapConfig) exceeded(failures int) bool {
+	switch c.policy {
+	case MapContinue:
+		return false
+	case MapTolerate:
+		return failures > c.maxFailures
+	}
+	return true
+}
+
+// parseMapConfig adım yapılandırmasını doğrular ve çözer
+func parseMapConfig(step *StepDefinition) (mapConfig, error) {
+	config := mapConfig{parallelism: 1, policy: MapFailFast}
+
+	config.stepID, _ = step.Config[MapConfigStep].(string)
+	config.workflowID, _ = step.Config[MapConfigWorkflow].(string)
+	if (config.stepID == "") == (config.workflowID == "") {
+		return config, fmt.Errorf("map adımı için step veya workflow belirtilmeli (%s)", step.ID)
+	}
+	if _, ok := step.Config[MapConfigItems].(string); !ok {
+		return config, fmt.Errorf("map adımında items yolu yok: %s", step.ID)
+	}
+
+	if raw, ok := step.Config[MapConfigParallelism]; ok {
+		n, err := toInt(raw)
+		if err != nil || n < 1 {
+			return config, fmt.Errorf("geçersiz map eşzamanlılığı (%s): %v", step.ID, raw)
+		}
+		config.parallelism = n
+	}
+
+	if raw, ok := step.Config[MapConfigFailurePolicy]; ok {
+		policy, _ := raw.(string)
+		switch MapFailurePolicy(policy) {
+		case MapFailFast, MapContinue, MapTolerate:
+			config.policy = MapFailurePolicy(policy)
+		default:
+			return config, fmt.Errorf("geçersiz map hata politikası (%s): %v", step.ID, raw)
+		}
+	}
+
+	if raw, ok := step.Config[MapConfigMaxFailures]; ok {
+		n, err := toInt(raw)
+		if err != nil || n < 0 {
+			return config, fmt.Errorf("geçersiz map hata sınırı (%s): %v", step.ID, raw)
+		}
+		config.maxFailures = n
+	}
+	return config, nil
+}
+
+// toItems bir dilim veya diziyi öğe listesine çevirir
+func toItems(collection interface{}) ([]interface{}, error) {
+	if items, ok := collection.([]interface{}); ok {
+		return items, nil
+	}
+
+	v := reflect.ValueOf(collection)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("koleksiyon dilim değil: %T", collection)
+	}
+
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// toInt yapılandırmadaki sayısal değeri çözer
+func toInt(raw interface{}) (int, error) {
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("desteklenmeyen sayı değeri: %v", raw)
+}
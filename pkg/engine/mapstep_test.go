@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type shareRequest struct {
+	Files []string
+}
+
+func newMapDefinition(config map[string]interface{}) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("share", "Share", "")
+	definition.AddStep(NewStepDefinition("fetch", "Fetch", StepTypeTask).WithNextSteps("process"))
+	definition.AddStep(NewStepDefinition("process", "Process", StepTypeMap).WithConfig(config))
+	return definition
+}
+
+func registerFetch(engine *WorkflowEngine, files ...string) {
+	engine.RegisterStep("fetch", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return &shareRequest{Files: files}, nil
+	})
+}
+
+func TestMapStepOrderedResultsWithBoundedConcurrency(t *testing.T) {
+	engine := NewWorkflowEngine()
+	registerFetch(engine, "a", "b", "c", "d", "e", "f")
+
+	var running, maxRunning int32
+	engine.RegisterStep("upper", func(ctx context.Context, data interface{}) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return data.(string) + "!", nil
+	})
+
+	runtime := NewWorkflowRuntime(engine, newMapDefinition(map[string]interface{}{
+		MapConfigItems:       "steps.fetch.Files",
+		MapConfigStep:        "upper",
+		MapConfigParallelism: 3,
+	}))
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	result, ok := runtime.GetState().StepResults["process"].(*MapResult)
+	if !ok {
+		t.Fatalf("Expected *MapResult, got %T", runtime.GetState().StepResults["process"])
+	}
+	expected := []string{"a!", "b!", "c!", "d!", "e!", "f!"}
+	for i, value := range expected {
+		if result.Results[i] != value {
+			t.Errorf("Result %d: expected %s, got %v", i, value, result.Results[i])
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("Parallelism should be bounded to 3, observed %d", maxRunning)
+	}
+	if maxRunning < 2 {
+		t.Errorf("Items should run in parallel, observed %d", maxRunning)
+	}
+}
+
+func TestMapStepItemsGetOwnStepInfo(t *testing.T) {
+	engine := NewWorkflowEngine()
+	registerFetch(engine, "a", "b", "c")
+
+	var mutex sync.Mutex
+	infos := make(map[string]StepInfo)
+	engine.RegisterStep("upper", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := StepInfoFromContext(ctx)
+		mutex.Lock()
+		infos[data.(string)] = info
+		mutex.Unlock()
+		return data, nil
+	})
+
+	runtime := NewWorkflowRuntime(engine, newMapDefinition(map[string]interface{}{
+		MapConfigItems:       "steps.fetch.Files",
+		MapConfigStep:        "upper",
+		MapConfigParallelism: 2,
+	}))
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Her öğe map adımının jetonundan türetilen kendi jetonunu alır
+	parent := runtime.ID() + "/process/1/1"
+	for i, file := range []string{"a", "b", "c"} {
+		info := infos[file]
+		if expected := parent + "/" + strconv.Itoa(i); info.IdempotencyToken != expected {
+			t.Errorf("Item %s: expected token %s, got %s", file, expected, info.IdempotencyToken)
+		}
+		if info.CompletionToken != "" {
+			t.Errorf("Item %s should not get a completion token", file)
+		}
+	}
+}
+
+func TestMapStepFailurePolicies(t *testing.T) {
+	failing := func(ctx context.Context, data interface{}) (interface{}, error) {
+		if data.(string) == "bad" {
+			return nil, fmt.Errorf("cannot process %v", data)
+		}
+		return data, nil
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		files       []string
+		expectFail  bool
+		expectedErr int
+	}{
+		{
+			name:       "fail fast",
+			config:     map[string]interface{}{},
+			files:      []string{"ok", "bad", "ok"},
+			expectFail: true,
+		},
+		{
+			name:        "continue",
+			config:      map[string]interface{}{MapConfigFailurePolicy: "continue"},
+			files:       []string{"bad", "ok", "bad"},
+			expectedErr: 2,
+		},
+		{
+			name:        "tolerate within limit",
+			config:      map[string]interface{}{MapConfigFailurePolicy: "tolerate", MapConfigMaxFailures: 1},
+			files:       []string{"ok", "bad", "ok"},
+			expectedErr: 1,
+		},
+		{
+			name:       "tolerate over limit",
+			config:     map[string]interface{}{MapConfigFailurePolicy: "tolerate", MapConfigMaxFailures: 1},
+			files:      []string{"bad", "ok", "bad"},
+			expectFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewWorkflowEngine()
+			registerFetch(engine, tt.files...)
+			engine.RegisterStep("check", failing)
+
+			tt.config[MapConfigItems] = "steps.fetch.Files"
+			tt.config[MapConfigStep] = "check"
+			runtime := NewWorkflowRuntime(engine, newMapDefinition(tt.config))
+			err := runtime.Start(context.Background())
+
+			state := runtime.GetState()
+			if tt.expectFail {
+				if err == nil || state.Status != StatusFailed {
+					t.Fatalf("Expected failure, got %v (%s)", err, state.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+
+			result := state.StepResults["process"].(*MapResult)
+			if len(result.Errors) != tt.expectedErr {
+				t.Fatalf("Expected %d errors, got %d", tt.expectedErr, len(result.Errors))
+			}
+			for i, item := range tt.files {
+				if item == "bad" && result.Results[i] != nil {
+					t.Errorf("Failed item %d should have a nil result", i)
+				}
+				if item == "ok" && result.Results[i] != "ok" {
+					t.Errorf("Item %d should succeed", i)
+				}
+			}
+			for i := 1; i < len(result.Errors); i++ {
+				if result.Errors[i].Index < result.Errors[i-1].Index {
+					t.Error("Errors should be ordered by item index")
+				}
+			}
+		})
+	}
+}
+
+func TestMapStepSubWorkflow(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	registerFetch(engine, "report.pdf", "photo.jpg")
+
+	var mutex sync.Mutex
+	notified := make(map[string]int)
+	engine.RegisterStep("notify", func(ctx context.Context, data interface{}) (interface{}, error) {
+		input := data.(map[string]interface{})
+		mutex.Lock()
+		defer mutex.Unlock()
+		notified[input["item"].(string)] = input["index"].(int)
+		return "notified", nil
+	})
+
+	child := NewWorkflowDefinition("notify-file", "Notify File", "")
+	child.AddStep(NewStepDefinition("notify", "Notify", StepTypeTask))
+	if err := engine.RegisterDefinition(ctx, child); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	runtime := NewWorkflowRuntime(engine, newMapDefinition(map[string]interface{}{
+		MapConfigItems:       "steps.fetch.Files",
+		MapConfigWorkflow:    "notify-file",
+		MapConfigParallelism: 2,
+	}))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if notified["report.pdf"] != 0 || notified["photo.jpg"] != 1 {
+		t.Errorf("Each item should run in its own sub-workflow, got %v", notified)
+	}
+	result := runtime.GetState().StepResults["process"].(*MapResult)
+	childResults, ok := result.Results[1].(map[string]interface{})
+	if !ok || childResults["notify"] != "notified" {
+		t.Errorf("Sub-workflow step results should be collected, got %v", result.Results[1])
+	}
+}
+
+func TestMapStepInvalidConfig(t *testing.T) {
+	engine := NewWorkflowEngine()
+	registerFetch(engine, "a")
+
+	runtime := NewWorkflowRuntime(engine, newMapDefinition(map[string]interface{}{
+		MapConfigItems: "steps.fetch.Files",
+	}))
+	if err := runtime.Start(context.Background()); err == nil {
+		t.Error("Map step without step or workflow should fail")
+	}
+
+	runtime = NewWorkflowRuntime(engine, newMapDefinition(map[string]interface{}{
+		MapConfigItems: "steps.fetch",
+		MapConfigStep:  "fetch",
+	}))
+	if err := runtime.Start(context.Background()); err == nil {
+		t.Error("Map step over a non-collection should fail")
+	}
+}
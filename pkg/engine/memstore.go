@@ -0,0 +1,330 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore süreç belleğinde tutulan WorkflowStore uygulamasıdır; motorun varsayılan deposudur
+type MemoryStore struct {
+	definitions map[string]map[int]*WorkflowDefinition
+	instances   map[string]*InstanceRecord
+	timers      map[string]Timer
+	history     map[string][]HistoryEvent
+	mutex       sync.RWMutex
+
+	// Kiralamalar ve son kayıt zamanları LeaseStore için tutulur
+	leases map[string]memoryLease
+	saved  map[string]time.Time
+
+	// events EventLog için tutulur; i. olayın sırası i+1'dir
+	events []Event
+}
+
+// memoryLease bir örneğin kiralamasını temsil eder
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// NewMemoryStore yeni bir bellek içi depo oluşturur
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		definitions: make(map[string]map[int]*WorkflowDefinition),
+		instances:   make(map[string]*InstanceRecord),
+		timers:      make(map[string]Timer),
+		history:     make(map[string][]HistoryEvent),
+		leases:      make(map[string]memoryLease),
+		saved:       make(map[string]time.Time),
+	}
+}
+
+// SaveDefinition tanımı kaydeder
+func (s *MemoryStore) SaveDefinition(ctx context.Context, definition *WorkflowDefinition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	versions, ok := s.definitions[definition.ID]
+	if !ok {
+		versions = make(map[int]*WorkflowDefinition)
+		s.definitions[definition.ID] = versions
+	}
+	versions[definition.Version] = cloneDefinition(definition)
+	return nil
+}
+
+// GetDefinition tanımı döndürür
+func (s *MemoryStore) GetDefinition(ctx context.Context, id string, version int) (*WorkflowDefinition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versions, ok := s.definitions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if version == 0 {
+		for v := range versions {
+			if v > version {
+				version = v
+			}
+		}
+	}
+	definition, ok := versions[version]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneDefinition(definition), nil
+}
+
+// ListDefinitions tüm tanımları ID ve sürüme göre sıralı döndürür
+func (s *MemoryStore) ListDefinitions(ctx context.Context) ([]*WorkflowDefinition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]*WorkflowDefinition, 0)
+	for _, versions := range s.definitions {
+		for _, definition := range versions {
+			result = append(result, cloneDefinition(definition))
+		}
+	}
+	sortDefinitions(result)
+	return result, nil
+}
+
+// DeleteDefinition tanımın tüm sürümlerini siler
+func (s *MemoryStore) DeleteDefinition(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.definitions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.definitions, id)
+	return nil
+}
+
+// SaveInstance örnek kaydını saklar
+func (s *MemoryStore) SaveInstance(ctx context.Context, record *InstanceRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.instances[record.ID] = cloneRecord(record)
+	s.saved[record.ID] = time.Now()
+	return nil
+}
+
+// GetInstance örnek kaydını döndürür
+func (s *MemoryStore) GetInstance(ctx context.Context, id string) (*InstanceRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	record, ok := s.instances[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneRecord(record), nil
+}
+
+// ListInstances filtreye uyan örnekleri oluşturulma zamanına göre sıralı döndürür
+func (s *MemoryStore) ListInstances(ctx context.Context, filter InstanceFilter) ([]*InstanceRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]*InstanceRecord, 0)
+	for _, record := range s.instances {
+		if filter.Match(record) {
+			result = append(result, cloneRecord(record))
+		}
+	}
+	sortRecords(result)
+	return result, nil
+}
+
+// AppendHistory olayları örneğin geçmişine ekler
+func (s *MemoryStore) AppendHistory(ctx context.Context, instanceID string, events []HistoryEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	history := s.history[instanceID]
+	if err := CheckHistoryAppend(int64(len(history)), events); err != nil {
+		return err
+	}
+	s.history[instanceID] = append(history, events...)
+	return nil
+}
+
+// LoadHistory örneğin geçmişini döndürür
+func (s *MemoryStore) LoadHistory(ctx context.Context, instanceID string) ([]HistoryEvent, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]HistoryEvent{}, s.history[instanceID]...), nil
+}
+
+// AppendEvent olayı olay günlüğüne ekler
+func (s *MemoryStore) AppendEvent(ctx context.Context, event Event) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event.Sequence = int64(len(s.events)) + 1
+	s.events = append(s.events, event)
+	return event.Sequence, nil
+}
+
+// LoadEvents sırası after'dan büyük olayları döndürür
+func (s *MemoryStore) LoadEvents(ctx context.Context, after int64, limit int) ([]Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if after < 0 {
+		after = 0
+	}
+	if after >= int64(len(s.events)) {
+		return []Event{}, nil
+	}
+	events := s.events[after:]
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return append([]Event{}, events...), nil
+}
+
+// ClaimInstances kiralaması boşta olan çalışır örnekleri oluşturulma sırasıyla kiralar
+func (s *MemoryStore) ClaimInstances(ctx context.Context, owner string, lease time.Duration, limit int) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	candidates := make([]*InstanceRecord, 0)
+	for id, record := range s.instances {
+		if record.State.Status != StatusRunning {
+			continue
+		}
+		current, leased := s.leases[id]
+		if leased && current.expiresAt.After(now) {
+			continue
+		}
+		if !leased && now.Sub(s.saved[id]) < lease {
+			continue
+		}
+		candidates = append(candidates, record)
+	}
+	sortRecords(candidates)
+
+	ids := make([]string, 0, len(candidates))
+	for _, record := range candidates {
+		if limit > 0 && len(ids) >= limit {
+			break
+		}
+		s.leases[record.ID] = memoryLease{owner: owner, expiresAt: now.Add(lease)}
+		ids = append(ids, record.ID)
+	}
+	return ids, nil
+}
+
+// AcquireLease örneğin kiralamasını alır veya uzatır
+func (s *MemoryStore) AcquireLease(ctx context.Context, owner, instanceID string, lease time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if current, ok := s.leases[instanceID]; ok && current.owner != owner && current.expiresAt.After(now) {
+		return ErrLeaseLost
+	}
+	s.leases[instanceID] = memoryLease{owner: owner, expiresAt: now.Add(lease)}
+	return nil
+}
+
+// ReleaseLease owner'ın kiralamasını bırakır
+func (s *MemoryStore) ReleaseLease(ctx context.Context, owner, instanceID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if current, ok := s.leases[instanceID]; ok && current.owner == owner {
+		s.leases[instanceID] = memoryLease{expiresAt: time.Now()}
+	}
+	return nil
+}
+
+// SaveTimer zamanlayıcıyı kaydeder
+func (s *MemoryStore) SaveTimer(ctx context.Context, timer Timer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.timers[timer.ID] = timer
+	return nil
+}
+
+// DeleteTimer zamanlayıcıyı siler
+func (s *MemoryStore) DeleteTimer(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.timers, id)
+	return nil
+}
+
+// DueTimers vadesi gelmiş zamanlayıcıları döndürür
+func (s *MemoryStore) DueTimers(ctx context.Context, now time.Time, limit int) ([]Timer, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]Timer, 0)
+	for _, timer := range s.timers {
+		if !timer.WakeAt.After(now) {
+			result = append(result, timer)
+		}
+	}
+	return limitTimers(sortTimers(result), limit), nil
+}
+
+// cloneDefinition tanımın adım listesini paylaşmayan bir kopyasını döndürür
+func cloneDefinition(definition *WorkflowDefinition) *WorkflowDefinition {
+	clone := *definition
+	clone.Steps = append([]StepDefinition(nil), definition.Steps...)
+	return &clone
+}
+
+// cloneRecord kaydın haritalarını paylaşmayan bir kopyasını döndürür
+func cloneRecord(record *InstanceRecord) *InstanceRecord {
+	clone := *record
+	clone.State = record.State.clone()
+	return &clone
+}
+
+// sortDefinitions tanımları ID ve sürüme göre sıralar
+func sortDefinitions(definitions []*WorkflowDefinition) {
+	sort.Slice(definitions, func(i, j int) bool {
+		if definitions[i].ID != definitions[j].ID {
+			return definitions[i].ID < definitions[j].ID
+		}
+		return definitions[i].Version < definitions[j].Version
+	})
+}
+
+// sortRecords kayıtları oluşturulma zamanına göre sıralar
+func sortRecords(records []*InstanceRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.Before(records[j].CreatedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+}
+
+// sortTimers zamanlayıcıları uyanma zamanına göre sıralar
+func sortTimers(timers []Timer) []Timer {
+	sort.Slice(timers, func(i, j int) bool {
+		if !timers[i].WakeAt.Equal(timers[j].WakeAt) {
+			return timers[i].WakeAt.Before(timers[j].WakeAt)
+		}
+		return timers[i].ID < timers[j].ID
+	})
+	return timers
+}
+
+// limitTimers sonucu limit ile sınırlar; limit 0 ise sınır yoktur
+func limitTimers(timers []Timer, limit int) []Timer {
+	if limit > 0 && len(timers) > limit {
+		return timers[:limit]
+	}
+	return timers
+}
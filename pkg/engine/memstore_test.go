@@ -0,0 +1,14 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/storetest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) engine.WorkflowStore {
+		return engine.NewMemoryStore()
+	})
+}
@@ -0,0 +1,32 @@
+package engine
+
+import "context"
+
+// StepHandler adım fonksiyonunu çalıştıran zincir halkasıdır
+type StepHandler func(ctx context.Context, stepID string, data interface{}) (interface{}, error)
+
+// StepMiddleware adım fonksiyonunun çağrısını sarmalar. Ara katmanlar havuzda
+// yer alındıktan ve hız sınırı beklendikten sonra, adım fonksiyonuyla aynı
+// bağlamda çalışır; deneme bilgisine StepInfoFromContext ile ulaşılır.
+type StepMiddleware func(next StepHandler) StepHandler
+
+// AddStepMiddleware adım çağrılarına bir ara katman ekler. İlk eklenen ara
+// katman en dışta çalışır; ölçüm, izleme ve kayıt gibi kesişen işler adım
+// fonksiyonlarına dokunmadan buradan eklenir.
+func (e *WorkflowEngine) AddStepMiddleware(middleware StepMiddleware) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.middlewares = append(e.middlewares, middleware)
+}
+
+// stepHandlerLocked adım fonksiyonunu kayıtlı ara katmanlarla sarmalar; çağıran
+// motorun kilidini tutmalıdır
+func (e *WorkflowEngine) stepHandlerLocked(step StepFunc) StepHandler {
+	handler := func(ctx context.Context, stepID string, data interface{}) (interface{}, error) {
+		return step(ctx, data)
+	}
+	for i := len(e.middlewares) - 1; i >= 0; i-- {
+		handler = e.middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStepMiddlewareWrapsStepCalls(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+
+	var calls []string
+	trace := func(name string) StepMiddleware {
+		return func(next StepHandler) StepHandler {
+			return func(ctx context.Context, stepID string, data interface{}) (interface{}, error) {
+				info, _ := StepInfoFromContext(ctx)
+				calls = append(calls, name+":"+stepID+":"+info.StepID)
+				return next(ctx, stepID, data)
+			}
+		}
+	}
+	engine.AddStepMiddleware(trace("outer"))
+	engine.AddStepMiddleware(trace("inner"))
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		calls = append(calls, "step")
+		return "charged", nil
+	})
+	if err := engine.RegisterDefinition(ctx, singleStepDefinition("charge", NewStepDefinition("charge", "Charge", StepTypeTask))); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	runtime, err := engine.StartWorkflow(ctx, "charge", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if runtime.GetState().StepResults["charge"] != "charged" {
+		t.Errorf("Middleware should pass the step result through, got %v", runtime.GetState().StepResults)
+	}
+
+	// İlk eklenen ara katman en dışta çalışır ve adımın bağlamını görür
+	expected := []string{"outer:charge:charge", "inner:charge:charge", "step"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestStepMiddlewareCanShortCircuit(t *testing.T) {
+	engine := NewWorkflowEngine()
+	denied := errors.New("denied")
+	engine.AddStepMiddleware(func(next StepHandler) StepHandler {
+		return func(ctx context.Context, stepID string, data interface{}) (interface{}, error) {
+			return nil, denied
+		}
+	})
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		t.Error("Step should not run when the middleware rejects it")
+		return nil, nil
+	})
+
+	var failed bool
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventStepFailed {
+			failed = true
+		}
+	})
+	if _, err := engine.ExecuteStep(context.Background(), "charge", nil); !errors.Is(err, denied) {
+		t.Errorf("Expected middleware error, got %v", err)
+	}
+	if !failed {
+		t.Error("Middleware errors should be reported as step failures")
+	}
+	if engine.PendingEvents() != 0 {
+		t.Errorf("Expected no pending events, got %d", engine.PendingEvents())
+	}
+}
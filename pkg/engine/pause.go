@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pause örneği bir sonraki adım sınırında duraklatır. Çalışmakta olan adım
+// tamamlanır ve sonucu kaydedilir; ardından örnek StatusPaused durumunda yeni
+// adım başlatmadan bekler. Zamanlayıcı, sinyal veya geri çağrı bekleyen örnek
+// beklemeye devam eder ve bekleme sona erdiğinde bir sonraki adıma geçmeden
+// duraklar. Duraklatılmış örneği yeniden duraklatmak hata değildir.
+func (e *WorkflowEngine) Pause(ctx context.Context, instanceID string) error {
+	runtime, err := e.runtimeFor(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	return runtime.pause(ctx)
+}
+
+// Unpause duraklatılmış örneği kaldığı yerden devam ettirir ve çağrı bir sonraki
+// bekleme noktasına veya sona kadar sürer. Bekleme sırasında duraklatılmış
+// örnek yalnızca beklemeye geri döner. Duraklatılmamış örnek için hata değildir.
+func (e *WorkflowEngine) Unpause(ctx context.Context, instanceID string) error {
+	runtime, err := e.runtimeFor(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	return runtime.unpause(ctx)
+}
+
+// pause duraklatmayı kaydeder; çalışan örnek hemen StatusPaused durumuna geçer
+func (r *WorkflowRuntime) pause(ctx context.Context) error {
+	r.mutex.Lock()
+	if err := r.checkActiveLocked(); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	if r.state.Paused {
+		r.mutex.Unlock()
+		return nil
+	}
+	r.state.Paused = true
+	if r.state.Status == StatusRunning {
+		r.state.Status = StatusPaused
+	}
+	r.recordLocked(HistoryEvent{Type: HistoryWorkflowPaused, Timestamp: r.engine.clock.Now()})
+	status := r.state.Status
+	r.mutex.Unlock()
+
+	r.log.Info("workflow paused", "status", string(status))
+	return r.persist(ctx)
+}
+
+// unpause duraklatmayı kaldırır. Yürütme döngüsü hâlâ çalışıyorsa (duraklatma
+// anında çalışan adım henüz bitmemişse) döngü durmadan devam eder; yeni bir
+// döngü başlatılmaz.
+func (r *WorkflowRuntime) unpause(ctx context.Context) error {
+	r.mutex.Lock()
+	if err := r.checkActiveLocked(); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	if !r.state.Paused {
+		r.mutex.Unlock()
+		return nil
+	}
+	r.state.Paused = false
+	start := false
+	if r.state.Status == StatusPaused {
+		r.state.Status = StatusRunning
+		if r.active {
+			r.unpaused = true
+		} else {
+			start = true
+		}
+	}
+	r.recordLocked(HistoryEvent{Type: HistoryWorkflowUnpaused, Timestamp: r.engine.clock.Now()})
+	r.mutex.Unlock()
+
+	r.log.Info("workflow unpaused")
+	if err := r.persist(ctx); err != nil {
+		return err
+	}
+	if !start {
+		return nil
+	}
+	return r.run(ctx)
+}
+
+// checkActiveLocked örneğin başlatılmış ve sona ermemiş olduğunu doğrular;
+// çağıran kilidi tutmalıdır
+func (r *WorkflowRuntime) checkActiveLocked() error {
+	if r.state.Status.IsTerminal() {
+		return fmt.Errorf("%w: %s", ErrInstanceFinished, r.id)
+	}
+	if r.state.Status == StatusPending {
+		return fmt.Errorf("%w: %s", ErrInstanceNotActive, r.id)
+	}
+	return nil
+}
+
+// wake bekleme noktasından çıkan örneği çalışır duruma alır; bekleme sırasında
+// duraklatılmış örnek StatusPaused durumuna geçer
+func (s *WorkflowState) wake() {
+	if s.Paused {
+		s.Status = StatusPaused
+	} else {
+		s.Status = StatusRunning
+	}
+	s.WakeAt = nil
+}
+
+// advancing örneğin adım sonuçlarını kaydedebileceği durumda olup olmadığını
+// döndürür; duraklatılmış örnekte çalışmakta olan adımın sonucu da kaydedilir
+func (s WorkflowStatus) advancing() bool {
+	return s == StatusRunning || s == StatusPaused
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// newBlockingDefinition ilk adımı release kapanana kadar bekleyen iki adımlı bir tanım kaydeder
+func newBlockingDefinition(t *testing.T, engine *WorkflowEngine, started chan<- string, release <-chan struct{}) *int {
+	t.Helper()
+	shipped := 0
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := StepInfoFromContext(ctx)
+		started <- info.InstanceID
+		<-release
+		return "charged", nil
+	})
+	engine.RegisterStep("ship", func(ctx context.Context, data interface{}) (interface{}, error) {
+		shipped++
+		return "shipped", nil
+	})
+	definition := NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(NewStepDefinition("charge", "Charge", StepTypeTask).WithNextSteps("ship"))
+	definition.AddStep(NewStepDefinition("ship", "Ship", StepTypeTask))
+	if err := engine.RegisterDefinition(context.Background(), definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	return &shipped
+}
+
+// startBlocked örneği ayrı bir goroutine'de başlatır; kimlik ilk adım başladığında döner
+func startBlocked(engine *WorkflowEngine, started <-chan string) (string, <-chan error) {
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.StartWorkflow(context.Background(), "order", nil)
+		done <- err
+	}()
+	return <-started, done
+}
+
+func TestPauseStopsAtNextStepBoundary(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	started, release := make(chan string, 1), make(chan struct{})
+	shipped := newBlockingDefinition(t, engine, started, release)
+
+	id, done := startBlocked(engine, started)
+	if err := engine.Pause(ctx, id); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Çalışan adımın sonucu kaydedilir ama sonraki adım başlatılmaz
+	runtime, _ := engine.GetRuntime(id)
+	state := runtime.GetState()
+	if state.Status != StatusPaused || state.StepResults["charge"] != "charged" || *shipped != 0 {
+		t.Fatalf("Expected a paused instance after charge, got %s with %v", state.Status, state.StepResults)
+	}
+
+	history, err := engine.History(ctx, id)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	folded, err := FoldHistory(history)
+	if err != nil || folded.Status != StatusPaused || !folded.Paused {
+		t.Errorf("History should fold to the paused state, got %s (%v)", folded.Status, err)
+	}
+
+	if err := engine.Unpause(ctx, id); err != nil {
+		t.Fatalf("Unpause failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != StatusCompleted || state.Paused || *shipped != 1 {
+		t.Errorf("Expected the instance to complete after unpause, got %s", state.Status)
+	}
+}
+
+func TestUnpauseDuringRunningStepContinuesLoop(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	started, release := make(chan string, 1), make(chan struct{})
+	shipped := newBlockingDefinition(t, engine, started, release)
+
+	id, done := startBlocked(engine, started)
+	if err := engine.Pause(ctx, id); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	// Döngü hâlâ çalışırken duraklatma kaldırılır; yeni döngü başlatılmaz
+	if err := engine.Unpause(ctx, id); err != nil {
+		t.Fatalf("Unpause failed: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	runtime, _ := engine.GetRuntime(id)
+	if runtime != nil {
+		t.Fatalf("Completed instance should not be tracked, got %s", runtime.GetState().Status)
+	}
+	record, err := engine.Store().GetInstance(ctx, id)
+	if err != nil || record.State.Status != StatusCompleted || *shipped != 1 {
+		t.Errorf("Expected one completed run, got %v (shipped %d)", record, *shipped)
+	}
+}
+
+func TestPauseWhileWaitingHoldsAfterSignal(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	shipped := make(chan interface{}, 1)
+	registerPaymentSteps(engine, shipped)
+	if err := engine.RegisterDefinition(ctx, newPaymentDefinition(map[string]interface{}{SignalConfigName: "payment_received"})); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := engine.StartWorkflow(ctx, "payment", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	if err := engine.Pause(ctx, runtime.ID()); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	// Bekleyen örnek beklemeye devam eder
+	if state := runtime.GetState(); state.Status != StatusWaiting || !state.Paused {
+		t.Fatalf("Expected a waiting paused instance, got %s", state.Status)
+	}
+
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", "paid"); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != StatusPaused || state.StepResults["await-payment"] != "paid" {
+		t.Fatalf("Expected the signal to be recorded and the instance paused, got %s", state.Status)
+	}
+	if len(shipped) != 0 {
+		t.Fatal("Paused instance should not start the next step")
+	}
+
+	if err := engine.Unpause(ctx, runtime.ID()); err != nil {
+		t.Fatalf("Unpause failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != StatusCompleted || len(shipped) != 1 {
+		t.Errorf("Expected completion after unpause, got %s", state.Status)
+	}
+}
+
+func TestPauseFinishedInstanceFails(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "charged", nil
+	})
+	if err := engine.RegisterDefinition(ctx, singleStepDefinition("order", NewStepDefinition("charge", "Charge", StepTypeTask))); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := engine.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	if err := engine.Pause(ctx, runtime.ID()); !errors.Is(err, ErrInstanceFinished) {
+		t.Errorf("Expected ErrInstanceFinished, got %v", err)
+	}
+	if err := engine.Unpause(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WithMaxConcurrency aynı anda çalışabilecek adım fonksiyonu sayısını sınırlar.
+// Sınıra ulaşıldığında yeni adımlar öncelik sırasıyla kuyrukta bekler; 0 sınırsız
+// demektir.
+func WithMaxConcurrency(limit int) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.pool().maxConcurrency = limit
+	}
+}
+
+// WithStepConcurrency stepID adımının aynı anda en fazla limit kez çalışmasını
+// sağlar. Sınırı dolu adımlar kuyrukta beklerken diğer adımlar çalışmaya devam eder.
+func WithStepConcurrency(stepID string, limit int) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.pool().stepLimits[stepID] = limit
+	}
+}
+
+// WorkerPoolStats adım çalıştırma havuzunun anlık görüntüsüdür
+type WorkerPoolStats struct {
+	// MaxConcurrency genel eşzamanlılık sınırıdır; 0 sınırsız demektir
+	MaxConcurrency int
+	Running        int
+	Queued         int
+	// Started havuzdan yer alan adım sayısı, TotalWait ve MaxWait bunların
+	// kuyrukta geçirdiği toplam ve en uzun süredir
+	Started   uint64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+	Steps     map[string]StepPoolStats
+}
+
+// StepPoolStats bir adımın havuzdaki anlık görüntüsüdür
+type StepPoolStats struct {
+	// Limit adımın eşzamanlılık sınırıdır; 0 yalnızca genel sınırın uygulandığını gösterir
+	Limit     int
+	Running   int
+	Queued    int
+	Started   uint64
+	TotalWait time.Duration
+}
+
+// PoolStats havuzun anlık görüntüsünü döndürür; havuz yapılandırılmamışsa
+// boş bir görüntü döner
+func (e *WorkflowEngine) PoolStats() WorkerPoolStats {
+	if e.workers == nil {
+		return WorkerPoolStats{Steps: map[string]StepPoolStats{}}
+	}
+	return e.workers.stats()
+}
+
+// pool motorun havuzunu gerekirse oluşturarak döndürür
+func (e *WorkflowEngine) pool() *workerPool {
+	if e.workers == nil {
+		e.workers = &workerPool{
+			stepLimits: make(map[string]int),
+			running:    make(map[string]int),
+			steps:      make(map[string]*StepPoolStats),
+		}
+	}
+	return e.workers
+}
+
+// workerPool adım fonksiyonlarının eşzamanlılığını genel ve adım bazlı
+// sınırlarla denetler. Yer bekleyen adımlar önceliğe, eşit öncelikte geliş
+// sırasına göre sıralanır; adım sınırı dolu olan bekleyen, arkasındakilerin
+// önünü kesmez.
+type workerPool struct {
+	maxConcurrency int
+	stepLimits     map[string]int
+
+	mutex   sync.Mutex
+	active  int
+	running map[string]int
+	queue   []*poolWaiter
+	seq     uint64
+
+	started   uint64
+	totalWait time.Duration
+	maxWait   time.Duration
+	steps     map[string]*StepPoolStats
+}
+
+// poolWaiter kuyrukta yer bekleyen bir adım çalıştırmasıdır
+type poolWaiter struct {
+	stepID   string
+	priority int
+	seq      uint64
+	enqueued time.Time
+	ready    chan struct{}
+	granted  bool
+}
+
+// acquire adım için havuzda yer alır ve yeri geri veren fonksiyonu döndürür.
+// ctx yer beklenirken iptal edilirse kuyruktan çıkılır ve ctx hatası döner.
+func (p *workerPool) acquire(ctx context.Context, stepID string, priority int) (func(), error) {
+	p.mutex.Lock()
+	p.seq++
+	waiter := &poolWaiter{
+		stepID:   stepID,
+		priority: priority,
+		seq:      p.seq,
+		enqueued: time.Now(),
+		ready:    make(chan struct{}),
+	}
+	index := sort.Search(len(p.queue), func(i int) bool {
+		return !p.queue[i].before(waiter)
+	})
+	p.queue = append(p.queue, nil)
+	copy(p.queue[index+1:], p.queue[index:])
+	p.queue[index] = waiter
+	p.dispatchLocked()
+	p.mutex.Unlock()
+
+	release := func() {
+		p.mutex.Lock()
+		p.active--
+		p.running[stepID]--
+		p.dispatchLocked()
+		p.mutex.Unlock()
+	}
+
+	select {
+	case <-waiter.ready:
+		return release, nil
+	case <-ctx.Done():
+		p.mutex.Lock()
+		if waiter.granted {
+			// Yer iptalle aynı anda verildi; kullanılmadan geri bırakılır
+			p.mutex.Unlock()
+			release()
+			return nil, ctx.Err()
+		}
+		p.removeLocked(waiter)
+		p.mutex.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// before bekleyenin o'dan önce yer alması gerekiyorsa true döner
+func (w *poolWaiter) before(o *poolWaiter) bool {
+	if w.priority != o.priority {
+		return w.priority > o.priority
+	}
+	return w.seq < o.seq
+}
+
+// dispatchLocked boş yerleri kuyruk sırasıyla, adım sınırı izin veren
+// bekleyenlere dağıtır; çağıran kilidi tutmalıdır
+func (p *workerPool) dispatchLocked() {
+	now := time.Now()
+	kept := p.queue[:0]
+	for _, waiter := range p.queue {
+		if p.maxConcurrency > 0 && p.active >= p.maxConcurrency {
+			kept = append(kept, waiter)
+			continue
+		}
+		if limit := p.stepLimits[waiter.stepID]; limit > 0 && p.running[waiter.stepID] >= limit {
+			kept = append(kept, waiter)
+			continue
+		}
+
+		p.active++
+		p.running[waiter.stepID]++
+		waiter.granted = true
+		close(waiter.ready)
+
+		wait := now.Sub(waiter.enqueued)
+		p.started++
+		p.totalWait += wait
+		if wait > p.maxWait {
+			p.maxWait = wait
+		}
+		step := p.stepStatsLocked(waiter.stepID)
+		step.Started++
+		step.TotalWait += wait
+	}
+	for i := len(kept); i < len(p.queue); i++ {
+		p.queue[i] = nil
+	}
+	p.queue = kept
+}
+
+// removeLocked iptal edilen bekleyeni kuyruktan çıkarır
+func (p *workerPool) removeLocked(waiter *poolWaiter) {
+	for i, queued := range p.queue {
+		if queued == waiter {
+			p.queue = append(p.queue[:i], p.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// stepStatsLocked adımın birikimli istatistiklerini döndürür
+func (p *workerPool) stepStatsLocked(stepID string) *StepPoolStats {
+	stats, ok := p.steps[stepID]
+	if !ok {
+		stats = &StepPoolStats{}
+		p.steps[stepID] = stats
+	}
+	return stats
+}
+
+// stats havuzun anlık görüntüsünü oluşturur
+func (p *workerPool) stats() WorkerPoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	result := WorkerPoolStats{
+		MaxConcurrency: p.maxConcurrency,
+		Running:        p.active,
+		Queued:         len(p.queue),
+		Started:        p.started,
+		TotalWait:      p.totalWait,
+		MaxWait:        p.maxWait,
+		Steps:          make(map[string]StepPoolStats),
+	}
+	for stepID, stats := range p.steps {
+		result.Steps[stepID] = *stats
+	}
+	for stepID, limit := range p.stepLimits {
+		stats := result.Steps[stepID]
+		stats.Limit = limit
+		result.Steps[stepID] = stats
+	}
+	for stepID, running := range p.running {
+		if running == 0 {
+			continue
+		}
+		stats := result.Steps[stepID]
+		stats.Running = running
+		result.Steps[stepID] = stats
+	}
+	for _, waiter := range p.queue {
+		stats := result.Steps[waiter.stepID]
+		stats.Queued++
+		result.Steps[waiter.stepID] = stats
+	}
+	return result
+}
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// singleStepDefinition tek adımlı bir tanım oluşturur
+func singleStepDefinition(id string, step StepDefinition) *WorkflowDefinition {
+	definition := NewWorkflowDefinition(id, id, "")
+	definition.AddStep(step)
+	return definition
+}
+
+// waitForQueue havuz kuyruğunda depth adım bekleyene kadar bekler
+func waitForQueue(t *testing.T, engine *WorkflowEngine, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for engine.PoolStats().Queued != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued steps, got %d", depth, engine.PoolStats().Queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMaxConcurrencyLimitsRunningSteps(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine(WithMaxConcurrency(3))
+	if err := engine.RegisterDefinition(ctx, singleStepDefinition("query", NewStepDefinition("query", "Query", StepTypeTask))); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	var running, peak atomic.Int32
+	engine.RegisterStep("query", func(ctx context.Context, data interface{}) (interface{}, error) {
+		current := running.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		running.Add(-1)
+		return nil, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := engine.StartWorkflow(ctx, "query", nil); err != nil {
+				t.Errorf("StartWorkflow failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 3 {
+		t.Errorf("At most 3 steps should run at once, got %d", peak.Load())
+	}
+	stats := engine.PoolStats()
+	if stats.Started != 50 || stats.Running != 0 || stats.Queued != 0 {
+		t.Errorf("Unexpected pool stats: %+v", stats)
+	}
+	if stats.MaxWait <= 0 || stats.TotalWait < stats.MaxWait {
+		t.Errorf("Queued steps should record wait time, got %+v", stats)
+	}
+}
+
+func TestStepConcurrencyDoesNotBlockOtherSteps(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine(WithStepConcurrency("send-email", 1))
+
+	unblock := make(chan struct{})
+	engine.RegisterStep("send-email", func(ctx context.Context, data interface{}) (interface{}, error) {
+		<-unblock
+		return "sent", nil
+	})
+	engine.RegisterStep("resize", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "resized", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			engine.ExecuteStep(ctx, "send-email", nil)
+		}()
+	}
+	waitForQueue(t, engine, 1)
+
+	// Sınırı dolu adım kuyrukta beklerken diğer adımlar çalışır
+	if _, err := engine.ExecuteStep(ctx, "resize", nil); err != nil {
+		t.Errorf("Unlimited step should run, got %v", err)
+	}
+	stats := engine.PoolStats().Steps["send-email"]
+	if stats.Limit != 1 || stats.Running != 1 || stats.Queued != 1 {
+		t.Errorf("Unexpected send-email stats: %+v", stats)
+	}
+
+	close(unblock)
+	wg.Wait()
+	if stats := engine.PoolStats().Steps["send-email"]; stats.Started != 2 || stats.Running != 0 {
+		t.Errorf("Both emails should have been sent, got %+v", stats)
+	}
+}
+
+func TestQueuedStepsRunByPriority(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine(WithMaxConcurrency(1))
+
+	var (
+		mutex sync.Mutex
+		order []string
+	)
+	unblock := make(chan struct{})
+	engine.RegisterStep("blocker", func(ctx context.Context, data interface{}) (interface{}, error) {
+		<-unblock
+		return nil, nil
+	})
+	for _, id := range []string{"report", "invoice", "alert"} {
+		id := id
+		engine.RegisterStep(id, func(ctx context.Context, data interface{}) (interface{}, error) {
+			mutex.Lock()
+			order = append(order, id)
+			mutex.Unlock()
+			return nil, nil
+		})
+	}
+
+	definitions := []*WorkflowDefinition{
+		singleStepDefinition("blocker", NewStepDefinition("blocker", "Blocker", StepTypeTask)),
+		singleStepDefinition("report", NewStepDefinition("report", "Report", StepTypeTask)),
+		singleStepDefinition("invoice", NewStepDefinition("invoice", "Invoice", StepTypeTask).WithPriority(5)),
+		singleStepDefinition("alert", NewStepDefinition("alert", "Alert", StepTypeTask).WithPriority(10)),
+	}
+	for _, definition := range definitions {
+		if err := engine.RegisterDefinition(ctx, definition); err != nil {
+			t.Fatalf("RegisterDefinition failed: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	start := func(workflowID string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := engine.StartWorkflow(ctx, workflowID, nil); err != nil {
+				t.Errorf("StartWorkflow failed: %v", err)
+			}
+		}()
+	}
+
+	// Tek yer dolu tutulurken adımlar geliş sırasından farklı öncelikle sıraya girer
+	start("blocker")
+	for engine.PoolStats().Running != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	for i, workflowID := range []string{"report", "invoice", "alert"} {
+		start(workflowID)
+		waitForQueue(t, engine, i+1)
+	}
+	close(unblock)
+	wg.Wait()
+
+	if fmt.Sprint(order) != fmt.Sprint([]string{"alert", "invoice", "report"}) {
+		t.Errorf("Expected higher priority steps first, got %v", order)
+	}
+}
+
+func TestQueuedStepHonorsCancellation(t *testing.T) {
+	engine := NewWorkflowEngine(WithMaxConcurrency(1))
+	unblock := make(chan struct{})
+	engine.RegisterStep("slow", func(ctx context.Context, data interface{}) (interface{}, error) {
+		<-unblock
+		return nil, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		engine.ExecuteStep(context.Background(), "slow", nil)
+	}()
+	for engine.PoolStats().Running != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Kuyrukta bekleyen adımın bağlamı iptal edilince adım çalışmadan döner
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := engine.ExecuteStep(ctx, "slow", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if stats := engine.PoolStats(); stats.Queued != 0 || stats.Running != 1 {
+		t.Errorf("Canceled step should leave the queue, got %+v", stats)
+	}
+
+	close(unblock)
+	<-done
+	if stats := engine.PoolStats(); stats.Running != 0 || stats.Started != 1 {
+		t.Errorf("Unexpected pool stats after completion: %+v", stats)
+	}
+}
+
+func TestPoolStatsWithoutLimits(t *testing.T) {
+	engine := NewWorkflowEngine()
+	if stats := engine.PoolStats(); stats.Running != 0 || stats.Steps == nil {
+		t.Errorf("Unconfigured pool should report empty stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrQueryNotFound tanım için kayıtlı olmayan bir sorgu çağrıldığında döner
+var ErrQueryNotFound = errors.New("sorgu bulunamadı")
+
+// QueryFunc bir örneğin durum anlık görüntüsünden hesaplanmış bir görünüm döndürür
+// (ilerleme yüzdesi, bekleyen onaylayıcılar gibi). Durumu değiştirmemelidir.
+type QueryFunc func(state WorkflowState, args interface{}) (interface{}, error)
+
+// RegisterQuery bir iş akışı tanımı için adlandırılmış bir sorgu kaydeder
+func (e *WorkflowEngine) RegisterQuery(definitionID, name string, query QueryFunc) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	queries, ok := e.queries[definitionID]
+	if !ok {
+		queries = make(map[string]QueryFunc)
+		e.queries[definitionID] = queries
+	}
+	queries[name] = query
+}
+
+// Query örneğin durumunun tutarlı bir anlık görüntüsü üzerinde kayıtlı sorguyu
+// çalıştırır. Görüntü çalışma zamanının okuma kilidi altında kopyalanır; sorgu
+// kilit dışında çalıştığından yürütmeyi bekletmez. Bellekte olmayan (örneğin
+// sona ermiş) örnekler için depodaki son kayıt kullanılır.
+func (e *WorkflowEngine) Query(ctx context.Context, instanceID, name string, args interface{}) (interface{}, error) {
+	var (
+		workflowID string
+		state      WorkflowState
+	)
+
+	if runtime, ok := e.GetRuntime(instanceID); ok {
+		workflowID = runtime.definition.ID
+		state = runtime.GetState()
+	} else {
+		record, err := e.store.GetInstance(ctx, instanceID)
+		if err != nil {
+			return nil, err
+		}
+		workflowID = record.WorkflowID
+		state = record.State
+	}
+
+	e.mutex.RLock()
+	query, ok := e.queries[workflowID][name]
+	e.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrQueryNotFound, workflowID, name)
+	}
+
+	return query(state, args)
+}
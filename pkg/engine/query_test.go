@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func progressQuery(definition *WorkflowDefinition) QueryFunc {
+	return func(state WorkflowState, args interface{}) (interface{}, error) {
+		return len(state.StepResults) * 100 / len(definition.Steps), nil
+	}
+}
+
+func TestQueryRunningInstance(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	engine.RegisterStep("first", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "done", nil
+	})
+	engine.RegisterStep("slow", func(ctx context.Context, data interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return "done", nil
+	})
+
+	definition := NewWorkflowDefinition("progress", "Progress", "")
+	definition.AddStep(NewStepDefinition("first", "First", StepTypeTask).WithNextSteps("slow"))
+	definition.AddStep(NewStepDefinition("slow", "Slow", StepTypeTask))
+	engine.RegisterQuery("progress", "percent", progressQuery(definition))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	done := make(chan error, 1)
+	go func() {
+		done <- runtime.Start(ctx)
+	}()
+
+	// Adım çalışırken sorgu beklemeden yanıt vermeli
+	<-started
+	result := make(chan interface{}, 1)
+	go func() {
+		value, err := engine.Query(ctx, runtime.ID(), "percent", nil)
+		if err != nil {
+			t.Errorf("Query failed: %v", err)
+		}
+		result <- value
+	}()
+
+	select {
+	case value := <-result:
+		if value != 50 {
+			t.Errorf("Expected 50%% progress, got %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Query should not block on a running step")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Sona ermiş örnekler depodaki kayıttan sorgulanır
+	value, err := engine.Query(ctx, runtime.ID(), "percent", nil)
+	if err != nil {
+		t.Fatalf("Query on completed instance failed: %v", err)
+	}
+	if value != 100 {
+		t.Errorf("Expected 100%% progress, got %v", value)
+	}
+}
+
+func TestQueryArgs(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+
+	definition := NewWorkflowDefinition("approval", "Approval", "")
+	definition.AddStep(NewStepDefinition("await", "Await", StepTypeSignal).
+		WithConfig(map[string]interface{}{SignalConfigName: "approved"}))
+
+	engine.RegisterQuery("approval", "received", func(state WorkflowState, args interface{}) (interface{}, error) {
+		name, ok := args.(string)
+		if !ok {
+			return nil, errors.New("signal name required")
+		}
+		count := 0
+		for _, signal := range state.Signals {
+			if signal.Name == name {
+				count++
+			}
+		}
+		return count, nil
+	})
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := engine.Signal(ctx, runtime.ID(), "comment", nil); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	value, err := engine.Query(ctx, runtime.ID(), "received", "comment")
+	if err != nil || value != 1 {
+		t.Errorf("Expected one comment signal, got %v (%v)", value, err)
+	}
+	if _, err := engine.Query(ctx, runtime.ID(), "received", 42); err == nil {
+		t.Error("Query errors should be returned to the caller")
+	}
+}
+
+func TestQueryNotFound(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+
+	definition := NewWorkflowDefinition("empty", "Empty", "")
+	runtime := NewWorkflowRuntime(engine, definition)
+
+	if _, err := engine.Query(ctx, runtime.ID(), "missing", nil); !errors.Is(err, ErrQueryNotFound) {
+		t.Errorf("Expected ErrQueryNotFound, got %v", err)
+	}
+	if _, err := engine.Query(ctx, "missing", "missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit bir adımın veya paylaşılan bir dış kaynağın token bucket sınırıdır.
+// Her Per süresinde Limit kez çalışmaya izin verilir; Burst kovanın kapasitesidir
+// ve 0 ise Limit kullanılır. Aynı Resource anahtarını taşıyan adımlar tek bir
+// kovayı paylaşır; anahtar boşsa kova adıma aittir. Sınıra takılan adım hata
+// almaz, jeton açılana kadar bekletilir.
+type RateLimit struct {
+	Resource string        `json:"resource,omitempty"`
+	Limit    int           `json:"limit"`
+	Per      time.Duration `json:"per"`
+	Burst    int           `json:"burst,omitempty"`
+}
+
+// Throttle EventStepThrottled olayının verisidir
+type Throttle struct {
+	Resource string
+	Delay    time.Duration
+}
+
+// StepOption adım kaydı seçeneğini temsil eder
+type StepOption func(*stepOptions)
+
+type stepOptions struct {
+	rateLimit *RateLimit
+}
+
+// WithStepRateLimit kaydedilen adım fonksiyonunun her çağrısını sınırlar. Tanım
+// düzeyindeki sınırdan farklı olarak map adımlarının her öğesine ayrı uygulanır.
+func WithStepRateLimit(resource string, limit int, per time.Duration) StepOption {
+	return func(o *stepOptions) {
+		o.rateLimit = &RateLimit{Resource: resource, Limit: limit, Per: per}
+	}
+}
+
+// validate sınırın kullanılabilir olduğunu doğrular
+func (l *RateLimit) validate() error {
+	if l.Limit < 1 || l.Per <= 0 {
+		return fmt.Errorf("hız sınırı pozitif olmalı: %d/%s", l.Limit, l.Per)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("hız sınırı kapasitesi negatif olamaz: %d", l.Burst)
+	}
+	return nil
+}
+
+// bucketKey sınırın kovasını adlandırır; adıma ait kovalar kaynak anahtarlarıyla çakışmaz
+func (l *RateLimit) bucketKey(stepID string) string {
+	if l.Resource != "" {
+		return l.Resource
+	}
+	return "step:" + stepID
+}
+
+// tokenBucket motor saatine göre dolan bir jeton kovasıdır
+type tokenBucket struct {
+	mutex    sync.Mutex
+	rate     float64 // saniyedeki jeton
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket dolu bir kova oluşturur
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	burst := limit.Burst
+	if burst == 0 {
+		burst = limit.Limit
+	}
+	return &tokenBucket{
+		rate:     float64(limit.Limit) / limit.Per.Seconds(),
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		last:     now,
+	}
+}
+
+// reserve bir jeton ayırır ve jetonun kullanılabilmesi için beklenmesi gereken
+// süreyi döndürür. Jetonlar borç olarak ayrıldığından bekleyenler geliş sırasıyla
+// açılır.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel kullanılmayan bir ayırmayı kovaya geri verir
+func (b *tokenBucket) cancel() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tokens++
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+}
+
+// bucket anahtarın kovasını döndürür; kova ilk kullanan sınırla oluşturulur ve
+// aynı anahtarı paylaşan sonraki sınırlar bu kovayı kullanır
+func (e *WorkflowEngine) bucket(key string, limit RateLimit) *tokenBucket {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	bucket, ok := e.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(limit, e.clock.Now())
+		e.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// throttle adımı sınırın izin verdiği ana kadar bekletir. Bekleme gerekiyorsa
+// EventStepThrottled bildirilir; ctx bekleme sırasında iptal edilirse jeton geri
+// verilir ve ctx hatası döner.
+func (e *WorkflowEngine) throttle(ctx context.Context, stepID string, limit *RateLimit) error {
+	if limit == nil {
+		return nil
+	}
+	if err := limit.validate(); err != nil {
+		return fmt.Errorf("adım %s: %w", stepID, err)
+	}
+	key := limit.bucketKey(stepID)
+	bucket := e.bucket(key, *limit)
+	delay := bucket.reserve(e.clock.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	// Bekleme olay bildirilmeden kurulur; gözlemci saati ilerletse de uyanma kaçmaz
+	wake := e.clock.After(delay)
+	info, _ := StepInfoFromContext(ctx)
+	e.notifyObservers(Event{
+		Type:       EventStepThrottled,
+		InstanceID: info.InstanceID,
+		WorkflowID: info.WorkflowID,
+		StepID:     stepID,
+		Data:       Throttle{Resource: key, Delay: delay},
+		Timestamp:  e.clock.Now(),
+	})
+
+	select {
+	case <-ctx.Done():
+		bucket.cancel()
+		return ctx.Err()
+	case <-wake:
+		return nil
+	}
+}
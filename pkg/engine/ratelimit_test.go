@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// throttleRecorder kısıtlama olaylarını kanala aktaran bir gözlemci ekler
+func throttleRecorder(engine *WorkflowEngine) <-chan Event {
+	events := make(chan Event, 16)
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventStepThrottled {
+			events <- event
+		}
+	})
+	return events
+}
+
+func TestRateLimitDelaysInsteadOfFailing(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	throttled := throttleRecorder(engine)
+
+	sent := 0
+	engine.RegisterStep("send-notifications", func(ctx context.Context, data interface{}) (interface{}, error) {
+		sent++
+		return sent, nil
+	}, WithStepRateLimit("", 2, time.Minute))
+
+	// Kovadaki iki jeton hemen kullanılır
+	for i := 0; i < 2; i++ {
+		if _, err := engine.ExecuteStep(ctx, "send-notifications", nil); err != nil {
+			t.Fatalf("ExecuteStep failed: %v", err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.ExecuteStep(ctx, "send-notifications", nil)
+		done <- err
+	}()
+
+	event := <-throttled
+	throttle, ok := event.Data.(Throttle)
+	if !ok || throttle.Delay != 30*time.Second || throttle.Resource != "step:send-notifications" {
+		t.Errorf("Unexpected throttle event: %+v", event.Data)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Throttled step should wait, returned %v", err)
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("Throttled step should run after the delay, got %v", err)
+	}
+	if sent != 3 {
+		t.Errorf("Expected 3 calls, got %d", sent)
+	}
+}
+
+func TestRateLimitSharedAcrossSteps(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	throttled := throttleRecorder(engine)
+	registerRecorder(engine, new([]string), "welcome", "digest")
+
+	// İki adım aynı sağlayıcının dakikada bir isteklik kotasını paylaşır
+	definition := NewWorkflowDefinition("mail", "Mail", "")
+	definition.AddStep(NewStepDefinition("welcome", "Welcome", StepTypeTask).
+		WithRateLimit("mail-provider", 1, time.Minute).
+		WithNextSteps("digest"))
+	definition.AddStep(NewStepDefinition("digest", "Digest", StepTypeTask).
+		WithRateLimit("mail-provider", 1, time.Minute))
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	done := make(chan *WorkflowRuntime, 1)
+	go func() {
+		runtime, err := engine.StartWorkflow(ctx, "mail", nil)
+		if err != nil {
+			t.Errorf("StartWorkflow failed: %v", err)
+		}
+		done <- runtime
+	}()
+
+	event := <-throttled
+	if event.StepID != "digest" || event.InstanceID == "" {
+		t.Errorf("Expected digest to be throttled within the instance, got %+v", event)
+	}
+	if throttle := event.Data.(Throttle); throttle.Resource != "mail-provider" || throttle.Delay != time.Minute {
+		t.Errorf("Unexpected throttle: %+v", throttle)
+	}
+
+	clock.Advance(time.Minute)
+	runtime := <-done
+	if runtime.GetState().Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", runtime.GetState().Status)
+	}
+}
+
+func TestCanceledThrottleReturnsToken(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	throttled := throttleRecorder(engine)
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, nil
+	}, WithStepRateLimit("psp", 1, time.Minute))
+
+	if _, err := engine.ExecuteStep(context.Background(), "charge", nil); err != nil {
+		t.Fatalf("ExecuteStep failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.ExecuteStep(ctx, "charge", nil)
+		done <- err
+	}()
+	<-throttled
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context canceled, got %v", err)
+	}
+
+	// İptal edilen bekleme jetonunu geri verdiğinden bir dakika sonra beklemeden çalışılır
+	clock.Advance(time.Minute)
+	if _, err := engine.ExecuteStep(context.Background(), "charge", nil); err != nil {
+		t.Fatalf("ExecuteStep failed: %v", err)
+	}
+	select {
+	case event := <-throttled:
+		t.Errorf("Step should not be throttled again, got %+v", event.Data)
+	default:
+	}
+}
+
+func TestValidateRejectsInvalidRateLimit(t *testing.T) {
+	definition := NewWorkflowDefinition("mail", "Mail", "")
+	definition.AddStep(NewStepDefinition("send", "Send", StepTypeTask).WithRateLimit("", 0, time.Minute))
+	if err := definition.Validate(); err == nil {
+		t.Error("Validate should reject a zero rate limit")
+	}
+}
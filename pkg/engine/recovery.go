@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RegisterDefinition iş akışı tanımını motora ve kalıcı depoya kaydeder. Süreç
+// yeniden başladığında örnekler tanımlarına ID ve sürüm ile buradan ulaşır.
+func (e *WorkflowEngine) RegisterDefinition(ctx context.Context, definition *WorkflowDefinition) error {
+	if err := e.store.SaveDefinition(ctx, definition); err != nil {
+		return fmt.Errorf("tanım kaydedilemedi: %w", err)
+	}
+	e.cacheDefinition(definition)
+	return nil
+}
+
+// ErrDefinitionInUse sona ermemiş örnekleri olan bir tanım silinmek istendiğinde döner
+var ErrDefinitionInUse = errors.New("tanımın sona ermemiş örnekleri var")
+
+// DeleteDefinition tanımın tüm sürümlerini motordan ve depodan siler. Sona
+// ermemiş örneği olan tanım silinmez ve ErrDefinitionInUse döner; sona ermiş
+// örneklerin kayıtları ve geçmişleri korunur ama artık yeniden oynatılamaz.
+func (e *WorkflowEngine) DeleteDefinition(ctx context.Context, id string) error {
+	active, err := e.store.ListInstances(ctx, InstanceFilter{
+		WorkflowID: id,
+		Statuses:   []WorkflowStatus{StatusPending, StatusRunning, StatusWaiting, StatusPaused},
+	})
+	if err != nil {
+		return err
+	}
+	if len(active) > 0 {
+		return fmt.Errorf("%w: %s (%d örnek)", ErrDefinitionInUse, id, len(active))
+	}
+	if err := e.store.DeleteDefinition(ctx, id); err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	delete(e.definitions, id)
+	e.mutex.Unlock()
+	return nil
+}
+
+// cacheDefinition tanımı yalnızca bellekte tutar
+func (e *WorkflowEngine) cacheDefinition(definition *WorkflowDefinition) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	versions, ok := e.definitions[definition.ID]
+	if !ok {
+		versions = make(map[int]*WorkflowDefinition)
+		e.definitions[definition.ID] = versions
+	}
+	versions[definition.Version] = definition
+}
+
+// definitionFor tanımı önce bellekten, bulunamazsa depodan yükler
+func (e *WorkflowEngine) definitionFor(ctx context.Context, id string, version int) (*WorkflowDefinition, error) {
+	e.mutex.RLock()
+	definition := e.definitions[id][version]
+	e.mutex.RUnlock()
+	if definition != nil {
+		return definition, nil
+	}
+
+	definition, err := e.store.GetDefinition(ctx, id, version)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("tanım bulunamadı: %s (sürüm %d): %w", id, version, ErrNotFound)
+		}
+		return nil, err
+	}
+	e.cacheDefinition(definition)
+	return definition, nil
+}
+
+// trackRuntime çalışma zamanını motorun örnek kaydına ekler
+func (e *WorkflowEngine) trackRuntime(runtime *WorkflowRuntime) {
+	e.cacheDefinition(runtime.definition)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.runtimes[runtime.id] = runtime
+}
+
+// untrackRuntime sona ermiş çalışma zamanını örnek kaydından çıkarır
+func (e *WorkflowEngine) untrackRuntime(id string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.runtimes, id)
+}
+
+// GetRuntime bellekte etkin olan çalışma zamanını ID ile döndürür
+func (e *WorkflowEngine) GetRuntime(id string) (*WorkflowRuntime, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	runtime, ok := e.runtimes[id]
+	return runtime, ok
+}
+
+// runtimeFor etkin çalışma zamanını döndürür; bellekte yoksa depodan yükler
+func (e *WorkflowEngine) runtimeFor(ctx context.Context, id string) (*WorkflowRuntime, error) {
+	if runtime, ok := e.GetRuntime(id); ok {
+		return runtime, nil
+	}
+	return e.loadRuntime(ctx, id)
+}
+
+// loadRuntime örneği depodaki kaydından yeniden oluşturur. Sona ermemiş örnekler
+// motorun örnek kaydına eklenir.
+func (e *WorkflowEngine) loadRuntime(ctx context.Context, id string) (*WorkflowRuntime, error) {
+	record, err := e.store.GetInstance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := e.definitionFor(ctx, record.WorkflowID, record.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	state, sequence, err := e.restoreState(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	runtime := newRuntime(e, definition, record.ID, &state)
+	runtime.createdAt = record.CreatedAt
+	runtime.idempotencyKey = record.IdempotencyKey
+	runtime.sequence = sequence
+
+	if state.Status.IsTerminal() {
+		return runtime, nil
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if existing, ok := e.runtimes[id]; ok {
+		return existing, nil
+	}
+	e.runtimes[id] = runtime
+	return runtime, nil
+}
+
+// restoreState örneğin durumunu geçmişini katlayarak yeniden kurar ve son olayın
+// sırasını döndürür. Geçmişi olmayan kayıtlar için kayıtlı durum kullanılır.
+func (e *WorkflowEngine) restoreState(ctx context.Context, record *InstanceRecord) (WorkflowState, int64, error) {
+	history, err := e.store.LoadHistory(ctx, record.ID)
+	if err != nil {
+		return WorkflowState{}, 0, err
+	}
+	if len(history) == 0 {
+		return record.State.clone(), 0, nil
+	}
+
+	state, err := FoldHistory(history)
+	if err != nil {
+		return WorkflowState{}, 0, fmt.Errorf("örnek durumu geçmişten kurulamadı (%s): %w", record.ID, err)
+	}
+	return state, history[len(history)-1].Sequence, nil
+}
+
+// Recover depodaki sona ermemiş örnekleri yeniden yükler ve motora kaydeder.
+// Örneklerin durumu kayıtlı anlık görüntüden değil geçmişlerinden kurulur.
+// Bekleyen örnekler zamanlayıcı servisi tarafından devam ettirilir; çökme anında
+// çalışır durumda olan örnekler için döndürülen çalışma zamanlarında Resume çağrılmalıdır.
+func (e *WorkflowEngine) Recover(ctx context.Context) ([]*WorkflowRuntime, error) {
+	records, err := e.store.ListInstances(ctx, InstanceFilter{
+		Statuses: []WorkflowStatus{StatusRunning, StatusWaiting},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runtimes := make([]*WorkflowRuntime, 0, len(records))
+	for _, record := range records {
+		runtime, err := e.runtimeFor(ctx, record.ID)
+		if err != nil {
+			return runtimes, fmt.Errorf("örnek kurtarılamadı (%s): %w", record.ID, err)
+		}
+
+		// Geçmişe göre sona ermiş ama kaydı güncellenememiş örneğin kaydı onarılır
+		if runtime.GetState().Status.IsTerminal() {
+			if err := runtime.persist(ctx); err != nil {
+				return runtimes, err
+			}
+			continue
+		}
+		runtimes = append(runtimes, runtime)
+	}
+	return runtimes, nil
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Replay bir örneği kayıtlı geçmişiyle yalıtılmış bir motorda yeniden kurar. Geçmiş
+// önce örneğin tanımıyla karşılaştırılır; tanımda olmayan adımlar veya tanımın
+// izin vermediği geçişler ErrInvalidHistory ile reddedilir. Geçmiş bir
+// workflow_failed olayıyla bitiyorsa bu olay atlanır ve örnek başarısız olan
+// adımda çalışır durumda döner; Resume çağrıldığında adım yerel adım
+// fonksiyonlarıyla yeniden çalıştırılır.
+//
+// Dönen çalışma zamanı bu motorun adım fonksiyonlarını ve sorgularını paylaşan
+// ancak bellek içi kendi deposunu kullanan ayrı bir motora bağlıdır; asıl depo ve
+// gözlemciler değişmez.
+func (e *WorkflowEngine) Replay(ctx context.Context, instanceID string) (*WorkflowRuntime, error) {
+	record, err := e.store.GetInstance(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := e.definitionFor(ctx, record.WorkflowID, record.Version)
+	if err != nil {
+		return nil, err
+	}
+	history, err := e.store.LoadHistory(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: örneğin geçmişi yok: %s", ErrInvalidHistory, instanceID)
+	}
+	if err := verifyHistory(definition, history); err != nil {
+		return nil, err
+	}
+
+	if last := history[len(history)-1]; last.Type == HistoryWorkflowFailed {
+		history = history[:len(history)-1]
+	}
+	state, err := FoldHistory(history)
+	if err != nil {
+		return nil, err
+	}
+
+	// Yalıtılmış motorun deposu yeniden kurulan geçmişle başlatılır
+	sandbox := e.sandbox()
+	if err := sandbox.store.SaveDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	if err := sandbox.store.AppendHistory(ctx, instanceID, history); err != nil {
+		return nil, err
+	}
+
+	runtime := newRuntime(sandbox, definition, instanceID, &state)
+	runtime.createdAt = record.CreatedAt
+	runtime.idempotencyKey = record.IdempotencyKey
+	runtime.sequence = history[len(history)-1].Sequence
+	if err := runtime.persist(ctx); err != nil {
+		return nil, err
+	}
+	if !state.Status.IsTerminal() {
+		sandbox.trackRuntime(runtime)
+	}
+	return runtime, nil
+}
+
+// sandbox adım fonksiyonlarını, sorguları ve önbellekteki tanımları paylaşan,
+// bellek içi depolu yeni bir motor oluşturur
+func (e *WorkflowEngine) sandbox() *WorkflowEngine {
+	sandbox := NewWorkflowEngine(WithClock(e.clock), WithTimerPollInterval(e.timerPollInterval))
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	for id, step := range e.steps {
+		sandbox.steps[id] = step
+	}
+	for id, queries := range e.queries {
+		sandbox.queries[id] = queries
+	}
+	for id, versions := range e.definitions {
+		sandbox.definitions[id] = make(map[int]*WorkflowDefinition, len(versions))
+		for version, definition := range versions {
+			sandbox.definitions[id][version] = definition
+		}
+	}
+	return sandbox
+}
+
+// verifyHistory geçmişteki adımların ve geçişlerin tanımla uyumlu olduğunu doğrular
+func verifyHistory(definition *WorkflowDefinition, history []HistoryEvent) error {
+	steps := make(map[string]*StepDefinition, len(definition.Steps))
+	for i := range definition.Steps {
+		steps[definition.Steps[i].ID] = &definition.Steps[i]
+	}
+
+	for _, event := range history {
+		if event.StepID == "" {
+			continue
+		}
+		step, ok := steps[event.StepID]
+		if !ok {
+			return fmt.Errorf("%w: %d. olaydaki adım tanımda yok: %s", ErrInvalidHistory, event.Sequence, event.StepID)
+		}
+		if event.Type != HistoryStepCompleted {
+			continue
+		}
+
+		allowed := forwardEdges(step)
+		if step.Loop != nil {
+			allowed = append(allowed, step.Loop.Target)
+		}
+		for _, next := range event.NextSteps {
+			if !slices.Contains(allowed, next) {
+				return fmt.Errorf("%w: %d. olayda %s adımından %s adımına geçiş tanımda yok",
+					ErrInvalidHistory, event.Sequence, step.ID, next)
+			}
+		}
+	}
+	return nil
+}
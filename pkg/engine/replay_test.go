@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplayFailedInstance(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	definition := newRetryDefinition(1, 0)
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	broken := true
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		if broken {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	engine.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(ctx); err == nil {
+		t.Fatal("Start should fail")
+	}
+
+	// Hata yerelde giderildikten sonra örnek aynı geçmişle yeniden oynatılır
+	broken = false
+	replayed, err := engine.Replay(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	state := replayed.GetState()
+	if state.Status != StatusRunning || state.CurrentStepID != "charge" {
+		t.Fatalf("Replay should stop before the failure, got %s at %s", state.Status, state.CurrentStepID)
+	}
+	if err := replayed.Resume(ctx); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if replayed.GetState().Status != StatusCompleted {
+		t.Errorf("Replayed instance should complete, got %s", replayed.GetState().Status)
+	}
+
+	// Asıl depo değişmemeli
+	record, err := engine.Store().GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != StatusFailed {
+		t.Errorf("Original instance should remain failed, got %s", record.State.Status)
+	}
+	history, _ := engine.History(ctx, runtime.ID())
+	if history[len(history)-1].Type != HistoryWorkflowFailed {
+		t.Error("Original history should be untouched")
+	}
+}
+
+func TestReplayRejectsHistoryNotMatchingDefinition(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	definition := newRetryDefinition(1, 0)
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	now := time.Now()
+	record := &InstanceRecord{ID: "tampered", WorkflowID: definition.ID, Version: definition.Version}
+	if err := engine.Store().SaveInstance(ctx, record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+
+	// receipt adımından charge adımına tanımda bir geçiş yok
+	history := []HistoryEvent{
+		{Sequence: 1, Type: HistoryWorkflowStarted, Timestamp: now},
+		{Sequence: 2, Type: HistoryStepScheduled, StepID: "receipt", Timestamp: now},
+		{Sequence: 3, Type: HistoryStepCompleted, StepID: "receipt", NextSteps: []string{"charge"}, Timestamp: now},
+	}
+	if err := engine.Store().AppendHistory(ctx, record.ID, history); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	if _, err := engine.Replay(ctx, record.ID); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("Expected ErrInvalidHistory, got %v", err)
+	}
+}
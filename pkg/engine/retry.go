@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Delay attempt. başarısız denemeden sonra beklenecek süreyi döndürür. Süre
+// InitialInterval'dan başlar, her denemede Multiplier ile çarpılır ve
+// MaxInterval ile sınırlanır; Multiplier 1'den küçükse süre sabit kalır.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.InitialInterval)
+	for i := 1; i < attempt && p.Multiplier > 1; i++ {
+		delay *= p.Multiplier
+		if p.MaxInterval > 0 && delay >= float64(p.MaxInterval) {
+			break
+		}
+	}
+	if p.MaxInterval > 0 && delay > float64(p.MaxInterval) {
+		return p.MaxInterval
+	}
+	return time.Duration(delay)
+}
+
+// retry başarısız denemeyi geçmişe kaydeder ve politika izin veriyorsa bekleme
+// süresi dolduktan sonra true döner. Bekleme süreç içinde yapılır, ancak uyanma
+// zamanı önce kalıcı bir zamanlayıcı olarak yazılır: süreç bekleme sırasında
+// çökerse veya ctx iptal edilirse zamanlayıcı servisi örneği vadesinde aynı
+// denemeden devam ettirir.
+func (r *WorkflowRuntime) retry(ctx context.Context, step *StepDefinition, cause error) (bool, error) {
+	policy := step.RetryPolicy
+	if policy == nil {
+		return false, nil
+	}
+
+	r.mutex.Lock()
+	attempt := r.state.Attempts[step.ID] + 1
+	if !r.state.Status.advancing() || attempt >= policy.MaxAttempts {
+		r.mutex.Unlock()
+		return false, nil
+	}
+	if r.state.Attempts == nil {
+		r.state.Attempts = make(map[string]int)
+	}
+	r.state.Attempts[step.ID] = attempt
+
+	// Sonraki deneme kısmi ilerlemeden devam edebilsin diye son kalp atışı
+	// ayrıntıları denemeyle birlikte kaydedilir
+	details := r.lastHeartbeatLocked(step.ID)
+	delete(r.progress, step.ID)
+	r.state.setHeartbeatDetails(step.ID, details)
+
+	now := r.engine.clock.Now()
+	delay := policy.Delay(attempt)
+	wakeAt := now.Add(delay)
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryStepRetried,
+		StepID:    step.ID,
+		Timestamp: now,
+		Attempt:   attempt,
+		Error:     cause.Error(),
+		Details:   details,
+		WakeAt:    &wakeAt,
+	})
+	r.mutex.Unlock()
+
+	r.log.Warn("step retrying",
+		LogKeyStepID, step.ID,
+		LogKeyAttempt, attempt+1,
+		"delay", delay,
+		"error", cause)
+	r.engine.notifyObservers(Event{
+		Type:       EventStepRetried,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     step.ID,
+		Data:       attempt,
+		Timestamp:  now,
+	})
+	if err := r.flushHistory(ctx); err != nil {
+		return false, err
+	}
+	timer := Timer{
+		ID:         timerID(r.id, step.ID, TimerKindRetry),
+		InstanceID: r.id,
+		StepID:     step.ID,
+		Kind:       TimerKindRetry,
+		WakeAt:     wakeAt,
+	}
+	if err := r.engine.store.SaveTimer(ctx, timer); err != nil {
+		return false, fmt.Errorf("zamanlayıcı kaydedilemedi: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-r.engine.clock.After(delay):
+	}
+	if err := r.engine.store.DeleteTimer(ctx, timer.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fireRetryTimer bekleme süreci çöktüğü için kalıcı zamanlayıcıdan tetiklenen
+// yeniden denemeyi çalıştırır. Bekleme bu süreçte hâlâ sürüyorsa zamanlayıcı
+// süreç içi beklemeye bırakılır; örnek başka bir yoldan ilerlemişse zamanlayıcı
+// eskimiştir ve yalnızca silinir.
+func (r *WorkflowRuntime) fireRetryTimer(ctx context.Context, timer Timer) error {
+	r.mutex.RLock()
+	active := r.active
+	current := r.state.Status == StatusRunning && r.state.CurrentStepID == timer.StepID
+	r.mutex.RUnlock()
+	if active {
+		return nil
+	}
+	if err := r.engine.store.DeleteTimer(ctx, timer.ID); err != nil {
+		return err
+	}
+	if !current {
+		return nil
+	}
+	r.log.Info("workflow resumed", LogKeyStepID, timer.StepID, "reason", string(timer.Kind))
+	return r.run(ctx)
+}
+
+// ErrInstanceNotFailed yalnızca başarısız örneklere uygulanabilen bir işlem
+// başka durumdaki bir örnek için istendiğinde döner
+var ErrInstanceNotFailed = errors.New("iş akışı başarısız durumda değil")
+
+// Retry başarısız bir örneği başarısız olan adımdan yeniden başlatır. Adımın
+// deneme sayacı sıfırlanır ve yeniden deneme politikası baştan uygulanır; önceki
+// adımların sonuçları korunur. Çağrı örnek bir sonraki bekleme noktasına veya
+// sona ulaşana kadar sürer. Örnek başarısız olduğu sırada duraklatılmışsa
+// yalnızca StatusPaused durumuna geçer ve Unpause ile devam eder.
+func (e *WorkflowEngine) Retry(ctx context.Context, instanceID string) error {
+	runtime, err := e.runtimeFor(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	return runtime.restart(ctx)
+}
+
+// restart başarısız örneği başarısız adımıyla yeniden çalışır duruma alır
+func (r *WorkflowRuntime) restart(ctx context.Context) error {
+	r.mutex.Lock()
+	if r.state.Status != StatusFailed {
+		r.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrInstanceNotFailed, r.id)
+	}
+	stepID := r.state.CurrentStepID
+	if stepID == "" {
+		r.mutex.Unlock()
+		return fmt.Errorf("örnek bir adımda başarısız olmadığı için yeniden denenemez: %s", r.id)
+	}
+
+	r.state.wake()
+	r.state.Error = nil
+	r.state.countExecution(stepID)
+	delete(r.state.Attempts, stepID)
+	delete(r.state.HeartbeatDetails, stepID)
+	r.recordLocked(HistoryEvent{Type: HistoryWorkflowRetried, StepID: stepID, Timestamp: r.engine.clock.Now()})
+	status := r.state.Status
+	r.mutex.Unlock()
+
+	// Aynı örneği eşzamanlı yeniden deneyen ikinci çağrı geçmiş çakışmasıyla döner
+	if err := r.persist(ctx); err != nil {
+		return err
+	}
+	r.engine.trackRuntime(r)
+	r.log.Info("workflow retried", LogKeyStepID, stepID)
+	if status != StatusRunning {
+		return nil
+	}
+	return r.run(ctx)
+}
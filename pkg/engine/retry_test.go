@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newRetryDefinition(maxAttempts int, interval time.Duration) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("charge", "Charge", "")
+	definition.AddStep(NewStepDefinition("charge", "Charge", StepTypeTask).
+		WithRetryPolicy(maxAttempts, interval, time.Minute, 2).
+		WithNextSteps("receipt"))
+	definition.AddStep(NewStepDefinition("receipt", "Receipt", StepTypeTask))
+	return definition
+}
+
+// registerFlakySteps ilk failures denemesi başarısız olan bir charge adımı kaydeder
+func registerFlakySteps(engine *WorkflowEngine, failures int) *int {
+	attempts := 0
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		attempts++
+		if attempts <= failures {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	engine.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+	return &attempts
+}
+
+func TestRetryPolicySucceedsAfterFailures(t *testing.T) {
+	engine := NewWorkflowEngine()
+	attempts := registerFlakySteps(engine, 2)
+
+	retried := make([]interface{}, 0)
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventStepRetried {
+			retried = append(retried, event.Data)
+		}
+	})
+
+	runtime := NewWorkflowRuntime(engine, newRetryDefinition(3, 0))
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Fatalf("Expected completed status, got %s", state.Status)
+	}
+	if *attempts != 3 {
+		t.Errorf("Expected three attempts, got %d", *attempts)
+	}
+	if len(retried) != 2 || retried[0] != 1 || retried[1] != 2 {
+		t.Errorf("Expected retry events for attempts 1 and 2, got %v", retried)
+	}
+	if len(state.Attempts) != 0 {
+		t.Errorf("Attempt counter should be cleared after success, got %v", state.Attempts)
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	attempts := registerFlakySteps(engine, 10)
+
+	runtime := NewWorkflowRuntime(engine, newRetryDefinition(3, 0))
+	if err := runtime.Start(ctx); err == nil {
+		t.Fatal("Start should fail once retries are exhausted")
+	}
+	if *attempts != 3 {
+		t.Errorf("Step should run MaxAttempts times, ran %d", *attempts)
+	}
+	if runtime.GetState().Status != StatusFailed {
+		t.Errorf("Expected failed status, got %s", runtime.GetState().Status)
+	}
+
+	history, _ := engine.History(ctx, runtime.ID())
+	retries := 0
+	for _, event := range history {
+		if event.Type == HistoryStepRetried {
+			retries++
+			if event.Error != "gateway unavailable" {
+				t.Errorf("Retry event should record the cause, got %q", event.Error)
+			}
+		}
+	}
+	if retries != 2 {
+		t.Errorf("Expected two retry events, got %d", retries)
+	}
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestRetryWaitsForBackoff(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	registerFlakySteps(engine, 1)
+
+	retried := make(chan struct{}, 1)
+	engine.AddObserver(func(event Event) {
+		if event.Type == EventStepRetried {
+			retried <- struct{}{}
+		}
+	})
+
+	runtime := NewWorkflowRuntime(engine, newRetryDefinition(2, 10*time.Second))
+	done := make(chan error, 1)
+	go func() {
+		done <- runtime.Start(context.Background())
+	}()
+
+	<-retried
+	select {
+	case <-done:
+		t.Fatal("Retry should wait for the backoff interval")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Bekleme kanalı kaydedilene kadar saat ilerletilmeye devam edilir
+	for finished := false; !finished; {
+		clock.Advance(10 * time.Second)
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+			finished = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if runtime.GetState().Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", runtime.GetState().Status)
+	}
+}
+
+func TestRetryBackoffSurvivesProcessRestart(t *testing.T) {
+	store := NewMemoryStore()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	definition := newRetryDefinition(2, 10*time.Second)
+
+	// İlk süreç yeniden deneme beklemesindeyken sona erer
+	first := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	registerFlakySteps(first, 1)
+	if err := first.RegisterDefinition(context.Background(), definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	ctx, crash := context.WithCancel(context.Background())
+	first.AddObserver(func(event Event) {
+		if event.Type == EventStepRetried {
+			crash()
+		}
+	})
+	runtime := NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the backoff to be interrupted, got %v", err)
+	}
+
+	// Uyanma zamanı depoda kaldığı için ikinci süreç denemeyi vadesinde çalıştırır
+	second := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	attempts := registerFlakySteps(second, 0)
+	if fired, err := second.FireDueTimers(context.Background()); err != nil || fired != 0 {
+		t.Fatalf("Expected no due timers before the backoff, got %d: %v", fired, err)
+	}
+	clock.Advance(10 * time.Second)
+	if fired, err := second.FireDueTimers(context.Background()); err != nil || fired != 1 {
+		t.Fatalf("Expected the retry timer to fire, got %d: %v", fired, err)
+	}
+
+	record, err := store.GetInstance(context.Background(), runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != StatusCompleted || *attempts != 1 {
+		t.Errorf("Expected completion on the retried attempt, got %s after %d attempts", record.State.Status, *attempts)
+	}
+	if timers, _ := store.DueTimers(context.Background(), clock.Now(), 0); len(timers) != 0 {
+		t.Errorf("Retry timer should be deleted, got %v", timers)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: time.Second, MaxInterval: 5 * time.Second, Multiplier: 2}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if actual := policy.Delay(i + 1); actual != delay {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, delay, actual)
+		}
+	}
+
+	constant := RetryPolicy{InitialInterval: time.Second}
+	if constant.Delay(4) != time.Second {
+		t.Errorf("Delay without multiplier should stay constant, got %v", constant.Delay(4))
+	}
+}
+
+func TestRetryFailedInstanceFromFailedStep(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	attempts := registerFlakySteps(engine, 3)
+	definition := newRetryDefinition(3, 0)
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	runtime, err := engine.StartWorkflow(ctx, "charge", nil)
+	if err == nil || runtime.GetState().Status != StatusFailed {
+		t.Fatalf("Expected the instance to fail, got %v", err)
+	}
+
+	// Yeniden deneme politikası baştan uygulanır; dördüncü deneme başarılı olur
+	if err := engine.Retry(ctx, runtime.ID()); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	if *attempts != 4 {
+		t.Errorf("Expected one more attempt, got %d in total", *attempts)
+	}
+
+	record, err := engine.Store().GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != StatusCompleted || record.State.StepResults["receipt"] != "sent" || record.State.Error != nil {
+		t.Errorf("Expected completed instance after retry, got %+v", record.State)
+	}
+
+	history, _ := engine.History(ctx, runtime.ID())
+	folded, err := FoldHistory(history)
+	if err != nil {
+		t.Fatalf("FoldHistory failed: %v", err)
+	}
+	if folded.Status != StatusCompleted {
+		t.Errorf("Folded history should complete, got %s", folded.Status)
+	}
+
+	if err := engine.Retry(ctx, runtime.ID()); !errors.Is(err, ErrInstanceNotFailed) {
+		t.Errorf("Expected ErrInstanceNotFailed for a completed instance, got %v", err)
+	}
+}
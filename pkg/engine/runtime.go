@@ -2,134 +2,399 @@ package engine
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WorkflowRuntime iş akışı çalışma zamanını temsil eder
 type WorkflowRuntime struct {
+	id         string
 	engine     *WorkflowEngine
 	definition *WorkflowDefinition
 	state      *WorkflowState
-	mutex      sync.RWMutex
+	steps      map[string]*StepDefinition
+	queued     map[string]bool
+	createdAt  time.Time
+	outbox     []Event
+	// idempotencyKey örneği başlatan isteğin anahtarıdır; kayıtla birlikte saklanır
+	idempotencyKey string
+	mutex          sync.RWMutex
+
+	// Geçmiş olayları kilit altında numaralanıp arabelleğe alınır ve
+	// historyMutex ile sıralı olarak depoya yazılır
+	sequence     int64
+	history      []HistoryEvent
+	historyMutex sync.Mutex
+
+	// joins her adımdan ulaşılabilen birleşme adımlarıdır; blockers her birleşme
+	// adımı için kuyrukta bekleyen ve ona ulaşabilen adımların sayısıdır
+	joins    map[string][]string
+	blockers map[string]int
+
+	// recordedContext geçmişe en son yazılan bağlamın JSON kodlamasıdır; adım
+	// fonksiyonlarının bağlamda yaptığı değişiklikler buna göre saptanır
+	recordedContext []byte
+
+	// progress çalışan denemelerin son kalp atışı ayrıntılarıdır; deneme yeniden
+	// denenirken geçmişe yazılır
+	progress map[string]interface{}
+
+	// early adım fonksiyonu Pending döndürmeden gelen geri çağrının sonucudur;
+	// deneme askıya alınırken uygulanır
+	early *stepCompletion
+
+	// span bu süreçte başlatılan örneğin ilk yürütmesi bitene kadar açık kalan
+	// kök span'idir
+	span trace.Span
+
+	// log örneğin kayıtlarını iş akışı ve örnek kimliğiyle yazar
+	log *slog.Logger
+
+	// active yürütme döngüsü çalışırken true'dur; unpaused döngü çalışırken
+	// kaldırılan duraklatmayı döngüye bildirir
+	active   bool
+	unpaused bool
 }
 
 // WorkflowState iş akışının durumunu temsil eder
 type WorkflowState struct {
-	CurrentStepID string
-	Status        WorkflowStatus
-	Context       map[string]interface{}
-	StepResults   map[string]interface{}
-	StartedAt     time.Time
-	CompletedAt   *time.Time
-	Error         error
+	CurrentStepID  string                   `json:"current_step_id"`
+	Status         WorkflowStatus           `json:"status"`
+	Context        map[string]interface{}   `json:"context"`
+	StepResults    map[string]interface{}   `json:"step_results"`
+	StartedAt      time.Time                `json:"started_at"`
+	CompletedAt    *time.Time               `json:"completed_at,omitempty"`
+	WakeAt         *time.Time               `json:"wake_at,omitempty"`
+	PendingSteps   []string                 `json:"pending_steps,omitempty"`
+	Signals        []SignalRecord           `json:"signals,omitempty"`
+	StepHistory    map[string][]interface{} `json:"step_history,omitempty"`
+	LoopIterations map[string]int           `json:"loop_iterations,omitempty"`
+	Attempts       map[string]int           `json:"attempts,omitempty"`
+	// Executions adımların biten yürütmelerinin sayısıdır: adım tamamlandığında
+	// veya başarısız adım Retry ile yeniden başlatıldığında artar. Denemelere
+	// verilen jetonlar aynı adımın yürütmelerini bununla ayırt eder.
+	Executions map[string]int `json:"executions,omitempty"`
+	// HeartbeatDetails yeniden denenen adımların önceki denemelerinden kalan son
+	// kalp atışı ayrıntılarıdır
+	HeartbeatDetails map[string]interface{} `json:"heartbeat_details,omitempty"`
+	// TraceContext örneğin kök span'inin W3C iz bağlamıdır; devam ettirilen
+	// yürütmeler aynı ize eklenir
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// Paused örnek duraklatılmışsa true'dur; bekleyen örnek beklemesi bittiğinde
+	// StatusPaused durumuna geçer
+	Paused bool  `json:"paused,omitempty"`
+	Error  error `json:"-"`
 }
 
+// ErrInstanceNotActive örnek istenen işlem için çalışır durumda değilse döner
+var ErrInstanceNotActive = errors.New("iş akışı çalışır durumda değil")
+
+// ErrInstanceFinished sona ermiş bir örnek üzerinde işlem yapılmak istendiğinde döner
+var ErrInstanceFinished = errors.New("iş akışı sona ermiş")
+
+// ErrLoopLimitExceeded döngü gövdesi MaxIterations kez çalıştıktan sonra koşul
+// hâlâ doğruysa örneğin hatasıdır
+var ErrLoopLimitExceeded = errors.New("döngü yineleme sınırı aşıldı")
+
 // WorkflowStatus iş akışı durumunu temsil eder
 type WorkflowStatus string
 
 const (
 	StatusPending   WorkflowStatus = "pending"
 	StatusRunning   WorkflowStatus = "running"
+	StatusWaiting   WorkflowStatus = "waiting"
+	StatusPaused    WorkflowStatus = "paused"
 	StatusCompleted WorkflowStatus = "completed"
 	StatusFailed    WorkflowStatus = "failed"
 	StatusCanceled  WorkflowStatus = "canceled"
 )
 
+// IsTerminal durumun son durum olup olmadığını döndürür
+func (s WorkflowStatus) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCanceled
+}
+
+// workflowStateJSON WorkflowState'in metotsuz takma adıdır
+type workflowStateJSON WorkflowState
+
+// MarshalJSON hatayı metin olarak yazarak durumu JSON'a çevirir
+func (s WorkflowState) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		workflowStateJSON
+		Error string `json:"error,omitempty"`
+	}{workflowStateJSON: workflowStateJSON(s)}
+	if s.Error != nil {
+		aux.Error = s.Error.Error()
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON JSON'dan durumu okur; hata metni error değerine çevrilir
+func (s *WorkflowState) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		workflowStateJSON
+		Error string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*s = WorkflowState(aux.workflowStateJSON)
+	if aux.Error != "" {
+		s.Error = errors.New(aux.Error)
+	}
+	return nil
+}
+
+// clone haritaları paylaşmayan bir durum kopyası döndürür
+func (s WorkflowState) clone() WorkflowState {
+	clone := s
+	clone.Context = copyMap(s.Context)
+	clone.StepResults = copyMap(s.StepResults)
+	clone.PendingSteps = append([]string(nil), s.PendingSteps...)
+	clone.Signals = append([]SignalRecord(nil), s.Signals...)
+	if s.StepHistory != nil {
+		clone.StepHistory = make(map[string][]interface{}, len(s.StepHistory))
+		for k, v := range s.StepHistory {
+			clone.StepHistory[k] = append([]interface{}(nil), v...)
+		}
+	}
+	clone.LoopIterations = copyCounters(s.LoopIterations)
+	clone.Attempts = copyCounters(s.Attempts)
+	clone.Executions = copyCounters(s.Executions)
+	if s.HeartbeatDetails != nil {
+		clone.HeartbeatDetails = copyMap(s.HeartbeatDetails)
+	}
+	clone.TraceContext = copyStrings(s.TraceContext)
+	return clone
+}
+
+// copyCounters sayaç haritasının kopyasını döndürür; nil harita nil kalır
+func copyCounters(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]int, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// copyStrings metin haritasının kopyasını döndürür; nil harita nil kalır
+func copyStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// copyMap haritanın sığ bir kopyasını döndürür
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
 // NewWorkflowRuntime yeni bir iş akışı çalışma zamanı oluşturur
 func NewWorkflowRuntime(engine *WorkflowEngine, definition *WorkflowDefinition) *WorkflowRuntime {
+	runtime := newRuntime(engine, definition, newID(), &WorkflowState{
+		Status:      StatusPending,
+		Context:     make(map[string]interface{}),
+		StepResults: make(map[string]interface{}),
+	})
+	runtime.createdAt = engine.clock.Now()
+	engine.trackRuntime(runtime)
+	return runtime
+}
+
+// newRuntime verilen kimlik ve durumla bir çalışma zamanı oluşturur. Adım indeksi
+// burada bir kez kurulur; tanım bu noktadan sonra değiştirilmemelidir.
+func newRuntime(engine *WorkflowEngine, definition *WorkflowDefinition, id string, state *WorkflowState) *WorkflowRuntime {
+	steps := make(map[string]*StepDefinition, len(definition.Steps))
+	for i := range definition.Steps {
+		steps[definition.Steps[i].ID] = &definition.Steps[i]
+	}
+
+	joins := joinAncestors(steps)
+	queued := make(map[string]bool, len(state.PendingSteps))
+	blockers := make(map[string]int)
+	for _, id := range state.PendingSteps {
+		queued[id] = true
+		for _, join := range joins[id] {
+			blockers[join]++
+		}
+	}
+
+	recordedContext, _ := json.Marshal(state.Context)
+
 	return &WorkflowRuntime{
-		engine:     engine,
-		definition: definition,
-		state: &WorkflowState{
-			Status:      StatusPending,
-			Context:     make(map[string]interface{}),
-			StepResults: make(map[string]interface{}),
-		},
+		id:              id,
+		engine:          engine,
+		definition:      definition,
+		state:           state,
+		steps:           steps,
+		queued:          queued,
+		joins:           joins,
+		blockers:        blockers,
+		recordedContext: recordedContext,
+		log:             engine.runtimeLogger(definition.ID, id),
 	}
 }
 
+// newID rastgele bir örnek kimliği üretir
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("rastgele kimlik üretilemedi: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// ID örneğin kimliğini döndürür
+func (r *WorkflowRuntime) ID() string {
+	return r.id
+}
+
+// Definition örneğin çalıştırdığı iş akışı tanımını döndürür
+func (r *WorkflowRuntime) Definition() *WorkflowDefinition {
+	return r.definition
+}
+
 // Start iş akışını başlatır
 func (r *WorkflowRuntime) Start(ctx context.Context) error {
+	if err := r.begin(ctx); err != nil {
+		return err
+	}
+	return r.run(ctx)
+}
+
+// begin örneği çalışır duruma alır, ilk adımı planlar ve kaydeder; adımlar run ile yürütülür
+func (r *WorkflowRuntime) begin(ctx context.Context) error {
 	r.mutex.Lock()
 	if r.state.Status != StatusPending {
 		r.mutex.Unlock()
 		return fmt.Errorf("iş akışı zaten başlatılmış")
 	}
 
+	if err := r.definition.Validate(); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	now := r.engine.clock.Now()
 	r.state.Status = StatusRunning
-	r.state.StartedAt = time.Now()
-	r.mutex.Unlock()
+	r.state.StartedAt = now
+	r.startRootSpanLocked(ctx)
+	r.recordedContext, _ = json.Marshal(r.state.Context)
+	r.recordLocked(HistoryEvent{
+		Type:         HistoryWorkflowStarted,
+		Timestamp:    now,
+		Context:      copyMap(r.state.Context),
+		TraceContext: copyStrings(r.state.TraceContext),
+	})
 
 	// İlk adımı başlat
-	if len(r.definition.Steps) > 0 {
-		r.state.CurrentStepID = r.definition.Steps[0].ID
-		return r.executeCurrentStep(ctx)
-	}
+	r.state.CurrentStepID = r.definition.Steps[0].ID
+	r.recordLocked(HistoryEvent{Type: HistoryStepScheduled, StepID: r.state.CurrentStepID, Timestamp: now})
+	r.mutex.Unlock()
 
-	return fmt.Errorf("iş akışında hiç adım yok")
+	if err := r.persist(ctx); err != nil {
+		// Kaydedilemeyen örnek yürütülmez; kök span burada kapatılır
+		_, span := r.startRunSpan(ctx)
+		r.endRunSpan(span, err)
+		return err
+	}
+	r.log.Info("workflow started", "version", r.definition.Version, LogKeyStepID, r.definition.Steps[0].ID)
+	return nil
 }
 
-// executeCurrentStep mevcut adımı çalıştırır
-func (r *WorkflowRuntime) executeCurrentStep(ctx context.Context) error {
+// Resume yarıda kalmış (örneğin süreç çökmesi sonrası kurtarılmış) bir örneği
+// mevcut adımından devam ettirir. Halihazırda yürütülen bir örnek için çağrılmamalıdır.
+func (r *WorkflowRuntime) Resume(ctx context.Context) error {
 	r.mutex.RLock()
-	currentStepID := r.state.CurrentStepID
+	status := r.state.Status
 	r.mutex.RUnlock()
 
-	var currentStep *StepDefinition
-	for _, step := range r.definition.Steps {
-		if step.ID == currentStepID {
-			currentStep = &step
-			break
-		}
+	if status != StatusRunning {
+		return fmt.Errorf("%w: %s", ErrInstanceNotActive, r.id)
 	}
+	return r.run(ctx)
+}
 
-	if currentStep == nil {
-		return fmt.Errorf("adım bulunamadı: %s", currentStepID)
-	}
+// fail örneği hata durumuna alır ve hatayı geri döndürür
+func (r *WorkflowRuntime) fail(ctx context.Context, err error) error {
+	r.mutex.Lock()
+	r.state.Status = StatusFailed
+	r.state.Error = err
+	stepID := r.state.CurrentStepID
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryWorkflowFailed,
+		StepID:    stepID,
+		Timestamp: r.engine.clock.Now(),
+		Error:     err.Error(),
+	})
+	r.mutex.Unlock()
 
-	// Adım için context hazırla
-	stepCtx := ctx
-	if currentStep.Timeout > 0 {
-		var cancel context.CancelFunc
-		stepCtx, cancel = context.WithTimeout(ctx, currentStep.Timeout)
-		defer cancel()
+	r.log.Error("workflow failed", LogKeyStepID, stepID, "error", err)
+	r.engine.untrackRuntime(r.id)
+	if perr := r.persist(ctx); perr != nil {
+		return errors.Join(err, perr)
 	}
+	return err
+}
 
-	// Adımı çalıştır
-	result, err := r.engine.ExecuteStep(stepCtx, currentStepID, r.state.Context)
-
-	r.mutex.Lock()
+// record örneğin kalıcı kaydını oluşturur
+func (r *WorkflowRuntime) record() *InstanceRecord {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.snapshotLocked()
+}
 
-	if err != nil {
-		if currentStep.RetryPolicy != nil {
-			// TODO: Retry logic implementation
-			r.mutex.Unlock()
-			return err
-		}
-		r.state.Status = StatusFailed
-		r.state.Error = err
-		r.mutex.Unlock()
-		return err
+// snapshotLocked örneğin kalıcı kaydını oluşturur; çağıran kilidi tutmalıdır
+func (r *WorkflowRuntime) snapshotLocked() *InstanceRecord {
+	return &InstanceRecord{
+		ID:             r.id,
+		WorkflowID:     r.definition.ID,
+		Version:        r.definition.Version,
+		IdempotencyKey: r.idempotencyKey,
+		State:          r.state.clone(),
+		CreatedAt:      r.createdAt,
+		UpdatedAt:      r.engine.clock.Now(),
 	}
+}
 
-	// Sonucu kaydet
-	r.state.StepResults[currentStepID] = result
-
-	// Sonraki adımı belirle
-	if len(currentStep.NextSteps) > 0 {
-		nextStepID := currentStep.NextSteps[0]
-		r.state.CurrentStepID = nextStepID
-		r.mutex.Unlock()
-		return r.executeCurrentStep(ctx)
+// persist bekleyen geçmiş olaylarını ve ardından örneğin mevcut durumunu depoya
+// yazar; kayıtlı durum hiçbir zaman geçmişin önüne geçmez. Depo TransitionStore
+// destekliyorsa ikisi tek işlemde yazılır. Değilse anlık görüntü ve kayıt
+// historyMutex altında alınır; eşzamanlı iki persist çağrısında eski görüntü
+// yenisinin üzerine yazılamaz.
+func (r *WorkflowRuntime) persist(ctx context.Context) error {
+	if store, ok := r.engine.store.(TransitionStore); ok {
+		return r.saveTransition(ctx, store)
 	}
 
-	// İş akışı tamamlandı
-	now := time.Now()
-	r.state.CompletedAt = &now
-	r.state.Status = StatusCompleted
-	r.mutex.Unlock()
+	r.historyMutex.Lock()
+	defer r.historyMutex.Unlock()
+	if err := r.flushHistoryLocked(ctx); err != nil {
+		return err
+	}
+	if err := r.engine.store.SaveInstance(ctx, r.record()); err != nil {
+		return fmt.Errorf("örnek durumu kaydedilemedi: %w", err)
+	}
 	return nil
 }
 
@@ -137,20 +402,47 @@ func (r *WorkflowRuntime) executeCurrentStep(ctx context.Context) error {
 func (r *WorkflowRuntime) GetState() WorkflowState {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
-	return *r.state
+	return r.state.clone()
+}
+
+// Cancel örneği kimliğiyle iptal eder; bellekte olmayan örnek depodan yüklenir
+func (e *WorkflowEngine) Cancel(ctx context.Context, instanceID string) error {
+	runtime, err := e.runtimeFor(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	return runtime.Cancel()
 }
 
 // Cancel iş akışını iptal eder
 func (r *WorkflowRuntime) Cancel() error {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
 
-	if r.state.Status != StatusRunning {
-		return fmt.Errorf("iş akışı çalışır durumda değil")
+	if r.state.Status != StatusRunning && r.state.Status != StatusWaiting && r.state.Status != StatusPaused {
+		r.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrInstanceNotActive, r.id)
+	}
+
+	waitingStepID := ""
+	if r.state.Status == StatusWaiting {
+		waitingStepID = r.state.CurrentStepID
 	}
 
 	r.state.Status = StatusCanceled
-	now := time.Now()
+	r.state.WakeAt = nil
+	now := r.engine.clock.Now()
 	r.state.CompletedAt = &now
-	return nil
+	r.recordLocked(HistoryEvent{Type: HistoryWorkflowCanceled, Timestamp: now})
+	r.mutex.Unlock()
+
+	r.log.Info("workflow canceled")
+
+	ctx := context.Background()
+	if waitingStepID != "" {
+		if err := r.deleteTimers(ctx, waitingStepID); err != nil {
+			return err
+		}
+	}
+	r.engine.untrackRuntime(r.id)
+	return r.persist(ctx)
 }
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -126,6 +127,46 @@ func TestWorkflowCancellation(t *testing.T) {
 	}
 }
 
+func TestEngineCancelLoadsInstanceFromStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	definition := newPaymentDefinition(map[string]interface{}{SignalConfigName: "payment_received"})
+
+	first := NewWorkflowEngine(WithStore(store))
+	registerPaymentSteps(first, make(chan interface{}, 1))
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := first.StartWorkflow(ctx, "payment", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	// Örneği bellekte tutmayan ikinci bir motor iptal eder
+	second := NewWorkflowEngine(WithStore(store))
+	if err := second.DeleteDefinition(ctx, "payment"); !errors.Is(err, ErrDefinitionInUse) {
+		t.Errorf("Expected ErrDefinitionInUse for a waiting instance, got %v", err)
+	}
+	if err := second.Cancel(ctx, runtime.ID()); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	record, err := store.GetInstance(ctx, runtime.ID())
+	if err != nil || record.State.Status != StatusCanceled {
+		t.Fatalf("Expected a canceled record, got %v (%v)", record, err)
+	}
+	if err := second.Cancel(ctx, runtime.ID()); !errors.Is(err, ErrInstanceNotActive) {
+		t.Errorf("Expected ErrInstanceNotActive for a canceled instance, got %v", err)
+	}
+
+	// Sona ermiş örneklerin tanımı silinebilir
+	if err := second.DeleteDefinition(ctx, "payment"); err != nil {
+		t.Fatalf("DeleteDefinition failed: %v", err)
+	}
+	if _, err := second.StartWorkflow(ctx, "payment", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
 func TestWorkflowTimeout(t *testing.T) {
 	engine := NewWorkflowEngine()
 	definition := NewWorkflowDefinition("test", "Test Workflow", "Test Description")
@@ -0,0 +1,408 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// run hazır kuyruğundaki adımları örnek beklemeye geçene veya sona erene kadar
+// sırayla çalıştırır. Her adım geçişi bir döngü turudur; yığın derinliği adım
+// sayısından bağımsızdır. Kiralama açıksa örnek yürütme süresince kiralanır.
+func (r *WorkflowRuntime) run(ctx context.Context) (err error) {
+	ctx, span := r.startRunSpan(ctx)
+	defer func() { r.endRunSpan(span, err) }()
+
+	ctx, release, err := r.engine.holdLease(ctx, r.id)
+	if err != nil {
+		// Kiralamayı tutan başka bir düğüm örneği yürütüyor
+		if errors.Is(err, ErrLeaseLost) {
+			r.log.Debug("instance leased by another node")
+			return nil
+		}
+		return err
+	}
+	defer release()
+
+	r.mutex.Lock()
+	r.active = true
+	r.mutex.Unlock()
+	defer func() {
+		r.mutex.Lock()
+		r.active = false
+		r.unpaused = false
+		r.mutex.Unlock()
+	}()
+
+	for {
+		more, err := r.executeCurrentStep(ctx)
+		if err != nil {
+			return err
+		}
+		if !more && !r.takeUnpaused() {
+			return nil
+		}
+	}
+}
+
+// takeUnpaused döngü çalışırken duraklatmanın kaldırılıp kaldırılmadığını
+// döndürür ve bildirimi temizler
+func (r *WorkflowRuntime) takeUnpaused() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	unpaused := r.unpaused
+	r.unpaused = false
+	return unpaused
+}
+
+// resume bekleme noktasından çıkan örneğin yeni durumunu kaydeder, tüketilen
+// zamanlayıcıları siler ve çalıştırılacak adım varsa yürütmeye devam eder. Durum
+// zamanlayıcılar silinmeden önce kaydedilir; arada çöken bir süreç eskimiş bir
+// zamanlayıcı bırakır ve bu zamanlayıcı tetiklendiğinde yok sayılır.
+func (r *WorkflowRuntime) resume(ctx context.Context, more bool, timerIDs ...string) error {
+	if more {
+		if err := r.persist(ctx); err != nil {
+			return err
+		}
+	}
+	for _, id := range timerIDs {
+		if err := r.engine.store.DeleteTimer(ctx, id); err != nil {
+			return err
+		}
+	}
+	if !more {
+		return nil
+	}
+	return r.run(ctx)
+}
+
+// stepByID tanımdaki adımı indeks üzerinden bulur
+func (r *WorkflowRuntime) stepByID(id string) *StepDefinition {
+	return r.steps[id]
+}
+
+// executeCurrentStep mevcut adımı çalıştırır ve geçişi uygular. Çalıştırılacak
+// başka adım varsa true döner.
+func (r *WorkflowRuntime) executeCurrentStep(ctx context.Context) (bool, error) {
+	r.mutex.RLock()
+	status := r.state.Status
+	currentStepID := r.state.CurrentStepID
+	r.mutex.RUnlock()
+
+	// İptal edilmiş örnekler ilerletilmez
+	if status != StatusRunning {
+		return false, nil
+	}
+
+	// Sonucu kalıcı olan ama ilerletilmemiş adımdan devam ediliyor
+	if currentStepID == "" {
+		return r.advance(ctx)
+	}
+
+	currentStep := r.stepByID(currentStepID)
+	if currentStep == nil {
+		return false, r.fail(ctx, fmt.Errorf("adım bulunamadı: %s", currentStepID))
+	}
+
+	// Zamanlayıcı ve sinyal adımları goroutine tutmadan örneği bekletir
+	switch currentStep.Type {
+	case StepTypeTimer:
+		return false, r.scheduleTimer(ctx, currentStep)
+	case StepTypeSignal:
+		return r.waitForSignal(ctx, currentStep)
+	}
+
+	attempt, err := r.startAttempt(ctx, currentStep)
+	if err != nil {
+		return false, err
+	}
+	result, err := r.executeAttempt(ctx, currentStep, attempt)
+	// Sonucu dış bir geri çağrıyla gelecek adım goroutine tutmadan bekletilir
+	if err == nil && result == Pending {
+		early, serr := r.suspend(ctx, currentStep, attempt)
+		if serr != nil || early == nil {
+			return false, serr
+		}
+		// Geri çağrı adım fonksiyonu dönmeden geldi
+		result, err = early.result, early.err
+	}
+	if err != nil {
+		// Kiralamasını kaybeden düğüm denemeyi bırakır; yeni sahip aynı denemeyi yeniden çalıştırır
+		if cause := context.Cause(ctx); errors.Is(cause, ErrLeaseLost) {
+			r.log.Warn("lease lost during step", LogKeyStepID, currentStep.ID, LogKeyAttempt, attempt, "error", cause)
+			return false, cause
+		}
+		// Yeniden denenecekse mevcut adım değişmeden döngü devam eder
+		retry, rerr := r.retry(ctx, currentStep, err)
+		if rerr != nil || retry {
+			return retry, rerr
+		}
+		return false, r.fail(ctx, err)
+	}
+
+	return r.completeStep(ctx, currentStep, result)
+}
+
+// executeAttempt adımı bir kez çalıştırır; adım zaman aşımı her denemeye ayrı
+// uygulanır ve hız sınırı varsa adım jeton açılana kadar bekletilir. Adım fonksiyonu denemenin bilgisine StepInfoFromContext ile ulaşır.
+// HeartbeatTimeout süresince kalp atışı göndermeyen deneme ErrHeartbeatTimeout
+// ile başarısız olur.
+func (r *WorkflowRuntime) executeAttempt(ctx context.Context, step *StepDefinition, attempt int) (result interface{}, err error) {
+	ctx, span := r.startStepSpan(ctx, step, attempt)
+	defer func() { endStepSpan(span, result, err) }()
+	ctx = withStepInfo(ctx, r.stepInfo(step.ID, attempt))
+	ctx = ContextWithLogger(ctx, r.stepLogger(step.ID, attempt))
+
+	started := r.engine.clock.Now()
+	r.log.Debug("step started", LogKeyStepID, step.ID, LogKeyAttempt, attempt)
+	defer func() { r.logAttempt(step, attempt, started, result, err) }()
+
+	// Tanımdaki hız sınırı beklemesi adımın zaman aşımına sayılmaz
+	if err := r.engine.throttle(ctx, step.ID, step.RateLimit); err != nil {
+		return nil, err
+	}
+	ctx, stop := r.withHeartbeats(ctx, step)
+	defer stop()
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+
+	if step.Type == StepTypeMap {
+		result, err = r.executeMap(ctx, step)
+	} else {
+		r.mutex.RLock()
+		data := r.state.Context
+		r.mutex.RUnlock()
+		result, err = r.engine.ExecuteStep(ctx, step.ID, data)
+	}
+	if err != nil && errors.Is(context.Cause(ctx), ErrHeartbeatTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrHeartbeatTimeout, step.ID)
+	}
+	return result, err
+}
+
+// completeStep adım sonucunu kaydeder ve bir sonraki adıma geçer. Adımın döngü
+// politikası varsa koşul doğru olduğunda döngü hedefine dönülür. Çalıştırılacak
+// başka adım varsa true döner.
+func (r *WorkflowRuntime) completeStep(ctx context.Context, step *StepDefinition, result interface{}) (bool, error) {
+	return r.transition(ctx, step, result, func() ([]string, error) {
+		return r.routeLocked(step)
+	})
+}
+
+// transition adım sonucunu kaydeder, route ile belirlenen adımları hazır kuyruğuna
+// ekler ve sonucu kalıcı hale getirdikten sonra bir sonraki adıma ilerler. route,
+// sonuç kaydedildikten sonra kilit altında çağrılır.
+func (r *WorkflowRuntime) transition(ctx context.Context, step *StepDefinition, result interface{}, route func() ([]string, error)) (bool, error) {
+	r.mutex.Lock()
+
+	// İptal edilmiş örnekler ilerletilmez; duraklatılmış örnekte çalışan adımın
+	// sonucu kaydedilir
+	if !r.state.Status.advancing() {
+		r.mutex.Unlock()
+		return false, nil
+	}
+
+	// Sonucu kaydet
+	r.state.StepResults[step.ID] = result
+	if r.state.StepHistory == nil {
+		r.state.StepHistory = make(map[string][]interface{})
+	}
+	r.state.StepHistory[step.ID] = append(r.state.StepHistory[step.ID], result)
+	r.state.countExecution(step.ID)
+	delete(r.state.Attempts, step.ID)
+	delete(r.state.HeartbeatDetails, step.ID)
+	delete(r.progress, step.ID)
+
+	// Sonraki adımları kuyruğa al
+	nextSteps, err := route()
+	events := r.takeOutboxLocked()
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryStepCompleted,
+		StepID:    step.ID,
+		Timestamp: r.engine.clock.Now(),
+		Result:    result,
+		NextSteps: nextSteps,
+		Iteration: r.state.LoopIterations[step.ID],
+		Context:   r.changedContextLocked(),
+	})
+	if err != nil {
+		r.mutex.Unlock()
+		return false, r.fail(ctx, err)
+	}
+	r.enqueueLocked(nextSteps)
+	r.state.CurrentStepID = ""
+	r.mutex.Unlock()
+
+	r.engine.notifyObservers(events...)
+
+	// Sonuç, mevcut adım ilerletilmeden önce kalıcı hale getirilir; bu noktadan
+	// sonra çöken bir süreçte adım yeniden çalıştırılmaz
+	if err := r.flushHistory(ctx); err != nil {
+		return false, err
+	}
+	return r.advance(ctx)
+}
+
+// changedContextLocked adım fonksiyonları bağlamı son kayıttan bu yana
+// değiştirdiyse bağlamın bir kopyasını döndürür, değiştirmediyse nil döner. Adım
+// fonksiyonları canlı bağlamı aldığından iç içe değerlerdeki değişiklikler de
+// JSON kodlaması karşılaştırılarak saptanır; kopya kodlamadan çözüldüğü için
+// sonraki değişikliklerden etkilenmez.
+func (r *WorkflowRuntime) changedContextLocked() map[string]interface{} {
+	data, err := json.Marshal(r.state.Context)
+	if err != nil {
+		// Kodlanamayan bağlam örnek kaydedilirken de hata verir; sığ kopya yazılır
+		return copyMap(r.state.Context)
+	}
+	if bytes.Equal(data, r.recordedContext) {
+		return nil
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return copyMap(r.state.Context)
+	}
+	r.recordedContext = data
+	return snapshot
+}
+
+// enqueueLocked adımları hazır kuyruğuna ekler ve yeni eklenen adımların
+// ulaşabildiği birleşme adımlarının sayaçlarını artırır
+func (r *WorkflowRuntime) enqueueLocked(stepIDs []string) {
+	for _, id := range stepIDs {
+		if r.queued[id] {
+			continue
+		}
+		for _, join := range r.joins[id] {
+			r.blockers[join]++
+		}
+		r.state.enqueue(r.queued, []string{id})
+	}
+}
+
+// dequeueLocked hazır kuyruğundan çalıştırılacak sonraki adımı çıkarır. Kuyrukta
+// bekleyen başka bir adımın ulaşabildiği birleşme adımları ertelenir; böylece
+// birden fazla daldan gelinen adım, ona ulaşabilecek tüm dallar bittikten sonra
+// bir kez çalışır. İleri geçişler döngüsüz olduğundan böyle bir adım her zaman
+// bulunur; uygun adımlar arasında FIFO sırası korunur.
+func (r *WorkflowRuntime) dequeueLocked() (string, bool) {
+	if len(r.state.PendingSteps) == 0 {
+		return "", false
+	}
+	next := r.state.PendingSteps[0]
+	for _, candidate := range r.state.PendingSteps {
+		if r.blockers[candidate] == 0 {
+			next = candidate
+			break
+		}
+	}
+	r.state.take(r.queued, next)
+	for _, join := range r.joins[next] {
+		r.blockers[join]--
+		if r.blockers[join] == 0 {
+			delete(r.blockers, join)
+		}
+	}
+	return next, true
+}
+
+// advance hazır kuyruğundan dequeueLocked ile seçilen adımı mevcut adım yapar; kuyruk boşsa örnek
+// tamamlanır. Çalıştırılacak başka adım varsa true döner.
+func (r *WorkflowRuntime) advance(ctx context.Context) (bool, error) {
+	r.mutex.Lock()
+	if r.state.Status == StatusPaused {
+		// Duraklatılmış örnek kaydedilen sonuçla birlikte bekler
+		r.mutex.Unlock()
+		return false, r.persist(ctx)
+	}
+	if r.state.Status != StatusRunning {
+		r.mutex.Unlock()
+		return false, nil
+	}
+
+	now := r.engine.clock.Now()
+	if next, ok := r.dequeueLocked(); ok {
+		r.state.CurrentStepID = next
+		r.recordLocked(HistoryEvent{Type: HistoryStepScheduled, StepID: next, Timestamp: now})
+		r.mutex.Unlock()
+		return true, nil
+	}
+
+	// İş akışı tamamlandı
+	r.state.CompletedAt = &now
+	r.state.Status = StatusCompleted
+	r.recordLocked(HistoryEvent{Type: HistoryWorkflowCompleted, Timestamp: now})
+	r.mutex.Unlock()
+
+	r.log.Info("workflow completed", "duration", now.Sub(r.state.StartedAt))
+
+	r.engine.untrackRuntime(r.id)
+	return false, r.persist(ctx)
+}
+
+// startAttempt adımın yeni denemesini çalıştırmadan önce geçmişe yazar ve deneme
+// numarasını döndürür. Süreç adım çalışırken çökerse kurtarılan örnek aynı
+// denemeyi aynı numarayla yeniden çalıştırır.
+func (r *WorkflowRuntime) startAttempt(ctx context.Context, step *StepDefinition) (int, error) {
+	r.mutex.Lock()
+	attempt := r.state.Attempts[step.ID] + 1
+	r.early = nil
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryStepStarted,
+		StepID:    step.ID,
+		Timestamp: r.engine.clock.Now(),
+		Attempt:   attempt,
+	})
+	r.mutex.Unlock()
+	return attempt, r.flushHistory(ctx)
+}
+
+// takeOutboxLocked kilit altında biriken olayları alır; olaylar gözlemcilerin
+// çalışma zamanını kilitlememesi için kilit bırakıldıktan sonra bildirilir
+func (r *WorkflowRuntime) takeOutboxLocked() []Event {
+	events := r.outbox
+	r.outbox = nil
+	return events
+}
+
+// routeLocked adımdan sonra gidilecek adımları döngü politikasını uygulayarak
+// belirler; çağıran kilidi tutmalıdır
+func (r *WorkflowRuntime) routeLocked(step *StepDefinition) ([]string, error) {
+	loop := step.Loop
+	if loop == nil {
+		return successors(step), nil
+	}
+
+	repeat, err := r.state.evalCondition(loop.Condition)
+	if err != nil {
+		return nil, fmt.Errorf("döngü koşulu değerlendirilemedi (%s): %w", step.ID, err)
+	}
+	if !repeat {
+		// Döngüden çıkıldı; dıştaki bir döngü gövdeyi yeniden başlatabilir
+		delete(r.state.LoopIterations, step.ID)
+		return successors(step), nil
+	}
+
+	iteration := r.state.LoopIterations[step.ID] + 1
+	if iteration >= loop.MaxIterations {
+		return nil, fmt.Errorf("%w: %s adımı %d yinelemeye ulaştı", ErrLoopLimitExceeded, step.ID, loop.MaxIterations)
+	}
+	if r.state.LoopIterations == nil {
+		r.state.LoopIterations = make(map[string]int)
+	}
+	r.state.LoopIterations[step.ID] = iteration
+
+	r.outbox = append(r.outbox, Event{
+		Type:       EventLoopIteration,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     step.ID,
+		Data:       iteration + 1,
+		Timestamp:  r.engine.clock.Now(),
+	})
+	return []string{loop.Target}, nil
+}
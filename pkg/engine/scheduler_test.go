@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// registerRecorder adımları çalıştırıldıkları sırayla kaydeden bir adım fonksiyonu kaydeder
+func registerRecorder(engine *WorkflowEngine, order *[]string, ids ...string) {
+	for _, id := range ids {
+		id := id
+		engine.RegisterStep(id, func(ctx context.Context, data interface{}) (interface{}, error) {
+			*order = append(*order, id)
+			return id, nil
+		})
+	}
+}
+
+func TestSchedulerFansOutToAllNextSteps(t *testing.T) {
+	engine := NewWorkflowEngine()
+	var order []string
+	registerRecorder(engine, &order, "start", "a", "b", "c", "end")
+
+	definition := NewWorkflowDefinition("fanout", "Fan Out", "")
+	definition.AddStep(NewStepDefinition("start", "Start", StepTypeTask).WithNextSteps("a", "b").WithFanOut())
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask).WithNextSteps("c"))
+	definition.AddStep(NewStepDefinition("b", "B", StepTypeTask).WithNextSteps("end"))
+	definition.AddStep(NewStepDefinition("c", "C", StepTypeTask))
+	definition.AddStep(NewStepDefinition("end", "End", StepTypeTask))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Dallar FIFO sırasıyla yürütülür
+	expected := []string{"start", "a", "b", "c", "end"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", state.Status)
+	}
+	if len(state.PendingSteps) != 0 {
+		t.Errorf("Ready queue should be empty, got %v", state.PendingSteps)
+	}
+}
+
+func TestSchedulerRunsFirstNextStepWithoutFanOut(t *testing.T) {
+	engine := NewWorkflowEngine()
+	var order []string
+	registerRecorder(engine, &order, "start", "a", "b")
+
+	definition := NewWorkflowDefinition("first-edge", "First Edge", "")
+	definition.AddStep(NewStepDefinition("start", "Start", StepTypeDecision).WithNextSteps("a", "b"))
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask))
+	definition.AddStep(NewStepDefinition("b", "B", StepTypeTask))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Fan-out kapalıyken yalnızca ilk sonraki adım çalışır
+	expected := []string{"start", "a"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+	if state := runtime.GetState(); state.Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", state.Status)
+	}
+}
+
+func TestSchedulerRunsQueuedJoinOnce(t *testing.T) {
+	engine := NewWorkflowEngine()
+	var order []string
+	registerRecorder(engine, &order, "start", "left", "right", "join")
+
+	definition := NewWorkflowDefinition("diamond", "Diamond", "")
+	definition.AddStep(NewStepDefinition("start", "Start", StepTypeTask).WithNextSteps("left", "right").WithFanOut())
+	definition.AddStep(NewStepDefinition("left", "Left", StepTypeTask).WithNextSteps("join"))
+	definition.AddStep(NewStepDefinition("right", "Right", StepTypeTask).WithNextSteps("join"))
+	definition.AddStep(NewStepDefinition("join", "Join", StepTypeTask))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Kuyrukta bekleyen birleşme adımı ikinci kez eklenmez
+	expected := []string{"start", "left", "right", "join"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+}
+
+func TestSchedulerRunsUnbalancedJoinAfterAllBranches(t *testing.T) {
+	engine := NewWorkflowEngine()
+	var order []string
+	registerRecorder(engine, &order, "start", "a", "b", "b2", "join")
+
+	definition := NewWorkflowDefinition("unbalanced", "Unbalanced", "")
+	definition.AddStep(NewStepDefinition("start", "Start", StepTypeTask).WithNextSteps("a", "b").WithFanOut())
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask).WithNextSteps("join"))
+	definition.AddStep(NewStepDefinition("b", "B", StepTypeTask).WithNextSteps("b2"))
+	definition.AddStep(NewStepDefinition("b2", "B2", StepTypeTask).WithNextSteps("join"))
+	definition.AddStep(NewStepDefinition("join", "Join", StepTypeTask))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Birleşme adımı uzun dal bitene kadar bekler ve bir kez çalışır
+	expected := []string{"start", "a", "b", "b2", "join"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+
+	// Geçmişten kurulan durum aynı sırayı izler
+	assertFoldMatches(t, engine, runtime)
+}
+
+func TestSchedulerDeepLinearWorkflow(t *testing.T) {
+	engine := NewWorkflowEngine()
+	definition := newLinearDefinition(engine, 10000)
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Fatalf("Expected completed status, got %s", state.Status)
+	}
+	if len(state.StepResults) != 10000 {
+		t.Errorf("Expected 10000 step results, got %d", len(state.StepResults))
+	}
+}
+
+func TestSchedulerResumesPendingStepsAfterRecovery(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	var order []string
+	registerRecorder(engine, &order, "start", "a", "b")
+
+	definition := NewWorkflowDefinition("recover-queue", "Recover Queue", "")
+	definition.AddStep(NewStepDefinition("start", "Start", StepTypeTask).WithNextSteps("a", "b").WithFanOut())
+	definition.AddStep(NewStepDefinition("a", "A", StepTypeTask))
+	definition.AddStep(NewStepDefinition("b", "B", StepTypeTask))
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	// "a" çalışırken çökmüş ve "b" kuyrukta kalmış bir örnek
+	state := &WorkflowState{
+		CurrentStepID: "a",
+		Status:        StatusRunning,
+		Context:       map[string]interface{}{},
+		StepResults:   map[string]interface{}{"start": "start"},
+		PendingSteps:  []string{"b"},
+	}
+	record := InstanceRecord{ID: "crashed", WorkflowID: definition.ID, Version: definition.Version, State: *state}
+	if err := engine.Store().SaveInstance(ctx, &record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+
+	runtimes, err := engine.Recover(ctx)
+	if err != nil || len(runtimes) != 1 {
+		t.Fatalf("Recover failed: %v (%d runtimes)", err, len(runtimes))
+	}
+	if err := runtimes[0].Resume(ctx); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	expected := []string{"a", "b"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+	if status := runtimes[0].GetState().Status; status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", status)
+	}
+}
+
+// newLinearDefinition n adımlık düz bir zincir tanımı oluşturur
+func newLinearDefinition(engine *WorkflowEngine, n int) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("linear", "Linear", "")
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("step-%d", i)
+		step := NewStepDefinition(id, id, StepTypeTask)
+		if i < n-1 {
+			step = step.WithNextSteps(fmt.Sprintf("step-%d", i+1))
+		}
+		definition.AddStep(step)
+		engine.RegisterStep(id, func(ctx context.Context, data interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	}
+	return definition
+}
+
+// newWideDefinition kök adımı n paralel dala açılan bir tanım oluşturur
+func newWideDefinition(engine *WorkflowEngine, n int) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("wide", "Wide", "")
+	branches := make([]string, n)
+	for i := range branches {
+		branches[i] = fmt.Sprintf("branch-%d", i)
+	}
+	definition.AddStep(NewStepDefinition("root", "Root", StepTypeTask).WithNextSteps(branches...).WithFanOut())
+	for _, id := range branches {
+		definition.AddStep(NewStepDefinition(id, id, StepTypeTask))
+	}
+
+	noop := func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	engine.RegisterStep("root", noop)
+	for _, id := range branches {
+		engine.RegisterStep(id, noop)
+	}
+	return definition
+}
+
+func BenchmarkLinearWorkflow10k(b *testing.B) {
+	engine := NewWorkflowEngine()
+	definition := newLinearDefinition(engine, 10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime := NewWorkflowRuntime(engine, definition)
+		if err := runtime.Start(context.Background()); err != nil {
+			b.Fatalf("Start failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkWideWorkflow10k(b *testing.B) {
+	engine := NewWorkflowEngine()
+	definition := newWideDefinition(engine, 10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime := NewWorkflowRuntime(engine, definition)
+		if err := runtime.Start(context.Background()); err != nil {
+			b.Fatalf("Start failed: %v", err)
+		}
+	}
+}
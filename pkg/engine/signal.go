@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sinyal bekleme adımı yapılandırma anahtarları.
+//
+//	{"signal": "payment_received"}                                         // süresiz bekle
+//	{"signal": "payment_received", "timeout": "24h"}                       // zaman aşımında başarısız ol
+//	{"signal": "payment_received", "timeout": "24h", "timeout_step": "x"}  // zaman aşımında x adımına geç
+const (
+	SignalConfigName        = "signal"
+	SignalConfigTimeout     = "timeout"
+	SignalConfigTimeoutStep = "timeout_step"
+)
+
+// ErrSignalTimeout sinyal zaman aşımında bir dal tanımlanmamışsa örneğin hatasıdır
+var ErrSignalTimeout = errors.New("sinyal zaman aşımı")
+
+// SignalRecord örneğe teslim edilmiş bir sinyali temsil eder. Henüz bir adım
+// tarafından tüketilmemiş kayıtlar arabellekte bekleyen sinyallerdir.
+type SignalRecord struct {
+	Name       string      `json:"name"`
+	Payload    interface{} `json:"payload,omitempty"`
+	ReceivedAt time.Time   `json:"received_at"`
+	ConsumedBy string      `json:"consumed_by,omitempty"`
+	ConsumedAt *time.Time  `json:"consumed_at,omitempty"`
+}
+
+// Signal çalışan bir örneğe adlandırılmış bir dış mesaj teslim eder. Örnek bu
+// sinyali bekliyorsa kaldığı yerden devam eder ve çağrı bir sonraki bekleme
+// noktasına veya sona kadar sürer; beklemiyorsa sinyal arabelleğe alınır.
+func (e *WorkflowEngine) Signal(ctx context.Context, instanceID, name string, payload interface{}) error {
+	runtime, err := e.runtimeFor(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	return runtime.signal(ctx, name, payload)
+}
+
+// signal sinyali kaydeder ve bekleyen adımı devam ettirir
+func (r *WorkflowRuntime) signal(ctx context.Context, name string, payload interface{}) error {
+	now := r.engine.clock.Now()
+
+	r.mutex.Lock()
+	if r.state.Status.IsTerminal() {
+		r.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrInstanceFinished, r.id)
+	}
+
+	received := SignalRecord{
+		Name:       name,
+		Payload:    payload,
+		ReceivedAt: now,
+	}
+	r.state.Signals = append(r.state.Signals, received)
+	r.recordLocked(HistoryEvent{Type: HistorySignalReceived, Timestamp: now, Signal: &received})
+
+	currentStepID := r.state.CurrentStepID
+	step := r.stepByID(currentStepID)
+	waiting := r.state.Status == StatusWaiting && step != nil &&
+		step.Type == StepTypeSignal && signalName(step) == name
+	if waiting {
+		payload = r.consumeSignalLocked(name, step.ID, now)
+		r.state.wake()
+	}
+	r.mutex.Unlock()
+
+	r.engine.notifyObservers(Event{
+		Type:       EventSignalReceived,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     currentStepID,
+		Data:       name,
+		Timestamp:  now,
+	})
+
+	if !waiting {
+		return r.persist(ctx)
+	}
+	r.log.Info("workflow resumed", LogKeyStepID, step.ID, "reason", "signal", "signal", name)
+
+	more, err := r.completeStep(ctx, step, payload)
+	if err != nil {
+		return err
+	}
+	return r.resume(ctx, more, timerID(r.id, step.ID, TimerKindSignalTimeout))
+}
+
+// waitForSignal arabellekte bekleyen sinyal varsa onu tüketir; yoksa örneği
+// bekleme durumuna alır ve varsa zaman aşımı zamanlayıcısını kaydeder. Sinyal
+// tüketildiyse ve çalıştırılacak başka adım varsa true döner.
+func (r *WorkflowRuntime) waitForSignal(ctx context.Context, step *StepDefinition) (bool, error) {
+	name := signalName(step)
+	if name == "" {
+		return false, r.fail(ctx, fmt.Errorf("sinyal adımında sinyal adı yok: %s", step.ID))
+	}
+
+	var timeout time.Duration
+	if raw, ok := step.Config[SignalConfigTimeout]; ok {
+		d, err := parseDuration(raw)
+		if err != nil {
+			return false, r.fail(ctx, fmt.Errorf("geçersiz sinyal zaman aşımı (%s): %w", step.ID, err))
+		}
+		timeout = d
+	}
+
+	now := r.engine.clock.Now()
+
+	r.mutex.Lock()
+	if r.state.Status != StatusRunning {
+		r.mutex.Unlock()
+		return false, nil
+	}
+
+	// Adıma ulaşılmadan önce gelmiş sinyal hemen tüketilir
+	if r.state.hasPendingSignal(name) {
+		payload := r.consumeSignalLocked(name, step.ID, now)
+		r.mutex.Unlock()
+
+		return r.completeStep(ctx, step, payload)
+	}
+
+	r.state.Status = StatusWaiting
+	var wakeAt time.Time
+	if timeout > 0 {
+		wakeAt = now.Add(timeout)
+		r.state.WakeAt = &wakeAt
+	}
+	r.recordLocked(HistoryEvent{
+		Type:      HistorySignalWaiting,
+		StepID:    step.ID,
+		Timestamp: now,
+		WakeAt:    copyTime(r.state.WakeAt),
+	})
+	r.mutex.Unlock()
+
+	if timeout > 0 {
+		timer := Timer{
+			ID:         timerID(r.id, step.ID, TimerKindSignalTimeout),
+			InstanceID: r.id,
+			StepID:     step.ID,
+			Kind:       TimerKindSignalTimeout,
+			WakeAt:     wakeAt,
+		}
+		if err := r.engine.store.SaveTimer(ctx, timer); err != nil {
+			return false, fmt.Errorf("zamanlayıcı kaydedilemedi: %w", err)
+		}
+	}
+	if err := r.persist(ctx); err != nil {
+		return false, err
+	}
+	r.log.Info("workflow waiting", LogKeyStepID, step.ID, "reason", "signal", "signal", name)
+	return false, nil
+}
+
+// signalTimeout sinyal beklemesi zaman aşımına uğradığında zaman aşımı dalına
+// geçer; dal tanımlı değilse örnek başarısız olur
+func (r *WorkflowRuntime) signalTimeout(ctx context.Context, step *StepDefinition) error {
+	r.engine.notifyObservers(Event{
+		Type:       EventSignalTimeout,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     step.ID,
+		Data:       signalName(step),
+		Timestamp:  r.engine.clock.Now(),
+	})
+
+	timeoutStep, _ := step.Config[SignalConfigTimeoutStep].(string)
+	if timeoutStep == "" {
+		return r.fail(ctx, fmt.Errorf("%w: %s", ErrSignalTimeout, signalName(step)))
+	}
+
+	more, err := r.transition(ctx, step, nil, func() ([]string, error) {
+		return []string{timeoutStep}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.resume(ctx, more)
+}
+
+// signalName adımın beklediği sinyalin adını döndürür
+func signalName(step *StepDefinition) string {
+	name, _ := step.Config[SignalConfigName].(string)
+	return name
+}
+
+// hasPendingSignal adı verilen sinyalin arabellekte olup olmadığını döndürür
+func (s *WorkflowState) hasPendingSignal(name string) bool {
+	for _, signal := range s.Signals {
+		if signal.Name == name && signal.ConsumedBy == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// consumeSignalLocked sinyali tüketir ve tüketimi geçmişe kaydeder; çağıran kilidi tutmalıdır
+func (r *WorkflowRuntime) consumeSignalLocked(name, stepID string, at time.Time) interface{} {
+	r.recordLocked(HistoryEvent{
+		Type:      HistorySignalConsumed,
+		StepID:    stepID,
+		Timestamp: at,
+		Signal:    &SignalRecord{Name: name},
+	})
+	return r.state.consumeSignal(name, stepID, at)
+}
+
+// consumeSignal arabellekteki en eski sinyali adım adına tüketir ve yükünü döndürür
+func (s *WorkflowState) consumeSignal(name, stepID string, at time.Time) interface{} {
+	for i := range s.Signals {
+		if s.Signals[i].Name == name && s.Signals[i].ConsumedBy == "" {
+			s.Signals[i].ConsumedBy = stepID
+			s.Signals[i].ConsumedAt = &at
+			return s.Signals[i].Payload
+		}
+	}
+	return nil
+}
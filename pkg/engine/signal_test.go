@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newPaymentDefinition(config map[string]interface{}) *WorkflowDefinition {
+	definition := NewWorkflowDefinition("payment", "Payment Workflow", "Wait for payment")
+	definition.AddStep(NewStepDefinition("invoice", "Invoice", StepTypeTask).
+		WithNextSteps("await-payment"))
+	definition.AddStep(NewStepDefinition("await-payment", "Await Payment", StepTypeSignal).
+		WithConfig(config).
+		WithNextSteps("ship"))
+	definition.AddStep(NewStepDefinition("ship", "Ship", StepTypeTask))
+	definition.AddStep(NewStepDefinition("escalate", "Escalate", StepTypeTask))
+	return definition
+}
+
+func registerPaymentSteps(engine *WorkflowEngine, shipped chan<- interface{}) {
+	engine.RegisterStep("invoice", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "invoiced", nil
+	})
+	engine.RegisterStep("ship", func(ctx context.Context, data interface{}) (interface{}, error) {
+		shipped <- "shipped"
+		return "shipped", nil
+	})
+	engine.RegisterStep("escalate", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "escalated", nil
+	})
+}
+
+func TestSignalResumesWaitingInstance(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	shipped := make(chan interface{}, 1)
+	registerPaymentSteps(engine, shipped)
+
+	runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+		SignalConfigName: "payment_received",
+	}))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if runtime.GetState().Status != StatusWaiting {
+		t.Fatalf("Expected waiting status, got %s", runtime.GetState().Status)
+	}
+
+	// Başka bir sinyal örneği ilerletmemeli
+	if err := engine.Signal(ctx, runtime.ID(), "file_uploaded", nil); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	if runtime.GetState().Status != StatusWaiting {
+		t.Error("Unrelated signal should not resume the instance")
+	}
+
+	payload := map[string]interface{}{"amount": 42}
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", payload); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	select {
+	case <-shipped:
+	default:
+		t.Error("Ship step should run after the signal")
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", state.Status)
+	}
+	if result, ok := state.StepResults["await-payment"].(map[string]interface{}); !ok || result["amount"] != 42 {
+		t.Errorf("Signal payload should be the step result, got %v", state.StepResults["await-payment"])
+	}
+
+	// Sinyal geçmişi örnek durumunda tutulmalı
+	if len(state.Signals) != 2 {
+		t.Fatalf("Expected two signals in history, got %d", len(state.Signals))
+	}
+	if state.Signals[0].ConsumedBy != "" {
+		t.Error("Unrelated signal should remain buffered")
+	}
+	if state.Signals[1].ConsumedBy != "await-payment" || state.Signals[1].ConsumedAt == nil {
+		t.Error("Payment signal should be consumed by the waiting step")
+	}
+}
+
+func TestSignalBufferedBeforeStep(t *testing.T) {
+	ctx := context.Background()
+	engine := NewWorkflowEngine()
+	shipped := make(chan interface{}, 1)
+	registerPaymentSteps(engine, shipped)
+
+	runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+		SignalConfigName: "payment_received",
+	}))
+
+	// Sinyal adıma ulaşılmadan gelir
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", "early"); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Fatalf("Buffered signal should be consumed immediately, got %s", state.Status)
+	}
+	if state.StepResults["await-payment"] != "early" {
+		t.Errorf("Expected buffered payload, got %v", state.StepResults["await-payment"])
+	}
+}
+
+func TestSignalTimeoutBranch(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	registerPaymentSteps(engine, make(chan interface{}, 1))
+
+	runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+		SignalConfigName:        "payment_received",
+		SignalConfigTimeout:     "24h",
+		SignalConfigTimeoutStep: "escalate",
+	}))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	clock.Advance(24 * time.Hour)
+	fired, err := engine.FireDueTimers(ctx)
+	if err != nil || fired != 1 {
+		t.Fatalf("Expected timeout timer to fire, got %d (%v)", fired, err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Fatalf("Expected completed status, got %s", state.Status)
+	}
+	if state.StepResults["escalate"] != "escalated" {
+		t.Error("Timeout branch should run")
+	}
+	if _, ok := state.StepResults["ship"]; ok {
+		t.Error("Ship step should not run after timeout")
+	}
+
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", nil); err == nil {
+		t.Error("Signal to a completed instance should fail")
+	}
+}
+
+func TestSignalTimeoutWithoutBranchFails(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	registerPaymentSteps(engine, make(chan interface{}, 1))
+
+	runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+		SignalConfigName:    "payment_received",
+		SignalConfigTimeout: "1h",
+	}))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err := engine.FireDueTimers(ctx); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.Status != StatusFailed {
+		t.Fatalf("Expected failed status, got %s", state.Status)
+	}
+	if !errors.Is(state.Error, ErrSignalTimeout) {
+		t.Errorf("Expected ErrSignalTimeout, got %v", state.Error)
+	}
+}
+
+func TestSignalBeforeTimeoutCancelsTimer(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	registerPaymentSteps(engine, make(chan interface{}, 1))
+
+	runtime := NewWorkflowRuntime(engine, newPaymentDefinition(map[string]interface{}{
+		SignalConfigName:        "payment_received",
+		SignalConfigTimeout:     "1h",
+		SignalConfigTimeoutStep: "escalate",
+	}))
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := engine.Signal(ctx, runtime.ID(), "payment_received", "paid"); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	fired, err := engine.FireDueTimers(ctx)
+	if err != nil || fired != 0 {
+		t.Errorf("Timeout timer should be removed once the signal arrives, fired %d (%v)", fired, err)
+	}
+	if _, ok := runtime.GetState().StepResults["escalate"]; ok {
+		t.Error("Timeout branch should not run")
+	}
+}
+
+func TestSignalUnknownInstance(t *testing.T) {
+	engine := NewWorkflowEngine()
+	err := engine.Signal(context.Background(), "missing", "payment_received", nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+// slowSaveStore sinyal içeren çalışan örnek kaydını yazmadan önce bekleyen bir
+// MemoryStore'dur; eşzamanlı persist çağrılarının sırasını zorlamak için kullanılır
+type slowSaveStore struct {
+	*MemoryStore
+	once   sync.Once
+	saving chan struct{}
+}
+
+func (s *slowSaveStore) SaveInstance(ctx context.Context, record *InstanceRecord) error {
+	if record.State.Status == StatusRunning && len(record.State.Signals) > 0 {
+		s.once.Do(func() { close(s.saving) })
+		time.Sleep(50 * time.Millisecond)
+	}
+	return s.MemoryStore.SaveInstance(ctx, record)
+}
+
+func TestConcurrentSignalKeepsStoredStateCurrent(t *testing.T) {
+	ctx := context.Background()
+	store := &slowSaveStore{MemoryStore: NewMemoryStore(), saving: make(chan struct{})}
+	engine := NewWorkflowEngine(WithStore(store))
+	definition := newLinearDefinition(engine, 2)
+
+	runtime := NewWorkflowRuntime(engine, definition)
+
+	signaled := make(chan error, 1)
+	engine.RegisterStep("step-1", func(ctx context.Context, data interface{}) (interface{}, error) {
+		go func() { signaled <- engine.Signal(ctx, runtime.ID(), "note", "late") }()
+		// Sinyalin görüntüsü yazılırken adım tamamlanır
+		<-store.saving
+		return "done", nil
+	})
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := <-signaled; err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	// Depodaki kayıt son durumu göstermeli; sinyalin eski görüntüsü tamamlanan
+	// örneğin kaydının üzerine yazılmamalı
+	record, err := store.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	stored, _ := json.Marshal(record.State)
+	expected, _ := json.Marshal(runtime.GetState())
+	if string(stored) != string(expected) {
+		t.Errorf("Stored state differs from live state:\nlive:   %s\nstored: %s", expected, stored)
+	}
+}
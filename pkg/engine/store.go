@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound aranan kayıt depoda bulunmadığında döner
+var ErrNotFound = errors.New("kayıt bulunamadı")
+
+// WorkflowStore iş akışı tanımlarını, örneklerini ve zamanlayıcılarını kalıcı olarak saklar
+type WorkflowStore interface {
+	// SaveDefinition tanımı ID ve sürümüyle birlikte kaydeder
+	SaveDefinition(ctx context.Context, definition *WorkflowDefinition) error
+	// GetDefinition tanımı döndürür; version 0 ise en güncel sürüm döner
+	GetDefinition(ctx context.Context, id string, version int) (*WorkflowDefinition, error)
+	// ListDefinitions her tanımın tüm sürümlerini döndürür
+	ListDefinitions(ctx context.Context) ([]*WorkflowDefinition, error)
+	// DeleteDefinition tanımın tüm sürümlerini siler; tanım yoksa ErrNotFound döner
+	DeleteDefinition(ctx context.Context, id string) error
+
+	// SaveInstance örneğin son durumunu kaydeder
+	SaveInstance(ctx context.Context, record *InstanceRecord) error
+	// GetInstance örneği ID ile döndürür
+	GetInstance(ctx context.Context, id string) (*InstanceRecord, error)
+	// ListInstances filtreye uyan örnekleri döndürür
+	ListInstances(ctx context.Context, filter InstanceFilter) ([]*InstanceRecord, error)
+
+	// AppendHistory olayları örneğin geçmişinin sonuna ekler. İlk olayın sırası
+	// kayıtlı son olayın bir fazlası değilse hiçbir olay yazılmaz ve ErrHistoryConflict döner.
+	AppendHistory(ctx context.Context, instanceID string, events []HistoryEvent) error
+	// LoadHistory örneğin geçmişini sıralı döndürür; geçmişi olmayan örnek için boş liste döner
+	LoadHistory(ctx context.Context, instanceID string) ([]HistoryEvent, error)
+
+	// SaveTimer zamanlayıcıyı kaydeder; aynı ID ile yeniden kayıt üzerine yazar
+	SaveTimer(ctx context.Context, timer Timer) error
+	// DeleteTimer zamanlayıcıyı siler; olmayan zamanlayıcı hata değildir
+	DeleteTimer(ctx context.Context, id string) error
+	// DueTimers uyanma zamanı now veya öncesi olan zamanlayıcıları sıralı döndürür
+	DueTimers(ctx context.Context, now time.Time, limit int) ([]Timer, error)
+}
+
+// TransitionStore geçmiş olaylarını ve örnek kaydını tek bir işlemde yazabilen
+// depoların isteğe bağlı arayüzüdür. Destekleyen depolarda örnek kaydı hiçbir
+// zaman geçmişinden ayrı yazılmaz.
+type TransitionStore interface {
+	// SaveTransition olayları AppendHistory kurallarıyla ekler ve kaydı aynı
+	// işlemde saklar; olaylardan biri eklenemezse kayıt da yazılmaz
+	SaveTransition(ctx context.Context, record *InstanceRecord, events []HistoryEvent) error
+}
+
+// EventLog gözlemci olaylarını tüm örnekler için tek bir sırayla saklayan
+// depoların isteğe bağlı arayüzüdür. Olay akışına yeniden bağlanan istemciler
+// kaçırdıkları olayları buradan okur. Kalıcı depolar olayları JSON olarak
+// saklar; okunan olayların Data alanı JSON'dan çözülmüş değerdir.
+type EventLog interface {
+	// AppendEvent olayı günlüğün sonuna ekler ve verilen sırayı döndürür. Sıralar
+	// 1'den başlar ve kesin artar; olayın Sequence alanı yok sayılır.
+	AppendEvent(ctx context.Context, event Event) (int64, error)
+	// LoadEvents sırası after'dan büyük olayları sıralı döndürür; limit 0 ise
+	// tüm olaylar döner
+	LoadEvents(ctx context.Context, after int64, limit int) ([]Event, error)
+}
+
+// InstanceRecord bir iş akışı örneğinin kalıcı kaydını temsil eder
+type InstanceRecord struct {
+	ID             string        `json:"id"`
+	WorkflowID     string        `json:"workflow_id"`
+	Version        int           `json:"version"`
+	IdempotencyKey string        `json:"idempotency_key,omitempty"`
+	State          WorkflowState `json:"state"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// InstanceFilter örnek listeleme filtresini temsil eder
+type InstanceFilter struct {
+	WorkflowID     string
+	IdempotencyKey string
+	Statuses       []WorkflowStatus
+}
+
+// Match kaydın filtreye uyup uymadığını döndürür
+func (f InstanceFilter) Match(record *InstanceRecord) bool {
+	if f.WorkflowID != "" && record.WorkflowID != f.WorkflowID {
+		return false
+	}
+	if f.IdempotencyKey != "" && record.IdempotencyKey != f.IdempotencyKey {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if record.State.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Timer kalıcı bir uyanma zamanını temsil eder
+type Timer struct {
+	ID         string    `json:"id"`
+	InstanceID string    `json:"instance_id"`
+	StepID     string    `json:"step_id"`
+	Kind       TimerKind `json:"kind"`
+	WakeAt     time.Time `json:"wake_at"`
+}
+
+// TimerKind zamanlayıcı tiplerini temsil eder
+type TimerKind string
+
+const (
+	TimerKindSleep             TimerKind = "sleep"
+	TimerKindSignalTimeout     TimerKind = "signal_timeout"
+	TimerKindCompletionTimeout TimerKind = "completion_timeout"
+	TimerKindRetry             TimerKind = "retry"
+)
+
+// timerKinds bir adım için kaydedilebilecek tüm zamanlayıcı tipleridir
+var timerKinds = []TimerKind{TimerKindSleep, TimerKindSignalTimeout, TimerKindCompletionTimeout, TimerKindRetry}
+
+// CheckHistoryAppend eklenecek olayların last sıra numarasından sonra kesintisiz
+// devam ettiğini doğrular; depo uygulamaları AppendHistory içinde kullanır
+func CheckHistoryAppend(last int64, events []HistoryEvent) error {
+	for i, event := range events {
+		if event.Sequence != last+int64(i)+1 {
+			return fmt.Errorf("%w: %d. olay beklenirken %d geldi", ErrHistoryConflict, last+int64(i)+1, event.Sequence)
+		}
+	}
+	return nil
+}
+
+// timerID bir örneğin adımı için deterministik zamanlayıcı kimliği üretir
+func timerID(instanceID, stepID string, kind TimerKind) string {
+	return instanceID + "/" + stepID + "/" + string(kind)
+}
@@ -0,0 +1,376 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTaskLease uzak bir işçinin aldığı görevi kalp atışı göndermeden
+// tutabileceği varsayılan süredir
+const DefaultTaskLease = 30 * time.Second
+
+var (
+	// ErrTaskNotFound görev bilinmediğinde, tamamlandığında veya kiralaması
+	// dolduğunda döner
+	ErrTaskNotFound = errors.New("görev bulunamadı")
+	// ErrTaskLeaseExpired işçi kiralama süresi içinde kalp atışı göndermediğinde
+	// denemenin hatasıdır; adımın yeniden deneme politikası uygulanır
+	ErrTaskLeaseExpired = errors.New("görev kiralamasının süresi doldu")
+	// ErrStepRegistered uzak işçi yerel bir fonksiyona kayıtlı adımı üstlenmek
+	// istediğinde döner
+	ErrStepRegistered = errors.New("adım yerel bir fonksiyona kayıtlı")
+)
+
+// Task uzak işçilere dağıtılan bir adım denemesidir
+type Task struct {
+	ID               string      `json:"id"`
+	Queue            string      `json:"queue"`
+	InstanceID       string      `json:"instance_id,omitempty"`
+	WorkflowID       string      `json:"workflow_id,omitempty"`
+	StepID           string      `json:"step_id"`
+	Attempt          int         `json:"attempt,omitempty"`
+	IdempotencyToken string      `json:"idempotency_token,omitempty"`
+	Input            interface{} `json:"input"`
+	HeartbeatDetails interface{} `json:"heartbeat_details,omitempty"`
+	Worker           string      `json:"worker,omitempty"`
+	LeaseExpiresAt   time.Time   `json:"lease_expires_at"`
+}
+
+// TaskError uzak işçinin bildirdiği adım hatasıdır
+type TaskError struct {
+	TaskID  string
+	Message string
+}
+
+func (e *TaskError) Error() string {
+	return e.Message
+}
+
+// WithTaskLease uzak görevlerin kiralama süresini belirler
+func WithTaskLease(lease time.Duration) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.tasks.lease = lease
+	}
+}
+
+// RegisterRemoteStep stepID adımını yerel bir fonksiyon yerine queue görev
+// kuyruğuna bağlar. Adım çalıştığında her deneme bir görev olarak kuyruğa
+// eklenir ve işçi sonucu bildirene kadar beklenir. Zaman aşımı, yeniden deneme,
+// hız sınırı ve eşzamanlılık sınırları yerel adımlardaki gibi uygulanır.
+func (e *WorkflowEngine) RegisterRemoteStep(stepID, queue string, opts ...StepOption) {
+	e.registerStep(stepID, e.remoteStep(stepID, queue), queue, opts)
+}
+
+// EnsureRemoteStep stepID adımı henüz kayıtlı değilse onu queue kuyruğuna bağlar.
+// Adım zaten uzak bir adımsa kuyruğu korunur; yerel bir fonksiyona kayıtlıysa
+// ErrStepRegistered döner. Adım kimlikleriyle kaydolan işçiler için kullanılır.
+func (e *WorkflowEngine) EnsureRemoteStep(stepID, queue string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if _, remote := e.remoteSteps[stepID]; remote {
+		return nil
+	}
+	if _, local := e.steps[stepID]; local {
+		return fmt.Errorf("%w: %s", ErrStepRegistered, stepID)
+	}
+	e.steps[stepID] = e.remoteStep(stepID, queue)
+	e.remoteSteps[stepID] = queue
+	return nil
+}
+
+// RemoteQueue uzak adımın bağlı olduğu kuyruğu döndürür; adım uzak değilse
+// false döner
+func (e *WorkflowEngine) RemoteQueue(stepID string) (string, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	queue, ok := e.remoteSteps[stepID]
+	return queue, ok
+}
+
+// remoteStep denemeleri queue kuyruğuna görev olarak ekleyen adım fonksiyonudur
+func (e *WorkflowEngine) remoteStep(stepID, queue string) StepFunc {
+	return func(ctx context.Context, data interface{}) (interface{}, error) {
+		return e.tasks.dispatch(ctx, queue, stepID, data)
+	}
+}
+
+// PollTask queue kuyruğundan bir görev alır ve worker adına kiralar. Kuyruk
+// boşsa görev gelene veya ctx bitene kadar bekler; ctx biterse nil görev döner.
+func (e *WorkflowEngine) PollTask(ctx context.Context, queue, worker string) (*Task, error) {
+	return e.tasks.poll(ctx, worker, func(task *Task) bool {
+		return task.Queue == queue
+	})
+}
+
+// PollTaskForSteps stepIDs adımlarından birine ait ilk görevi hangi kuyrukta
+// olursa olsun alır ve worker adına kiralar; bekleme PollTask gibidir
+func (e *WorkflowEngine) PollTaskForSteps(ctx context.Context, stepIDs []string, worker string) (*Task, error) {
+	steps := make(map[string]bool, len(stepIDs))
+	for _, id := range stepIDs {
+		steps[id] = true
+	}
+	return e.tasks.poll(ctx, worker, func(task *Task) bool {
+		return steps[task.StepID]
+	})
+}
+
+// HeartbeatTask görevin kiralamasını uzatır ve yeni bitiş zamanını döndürür
+func (e *WorkflowEngine) HeartbeatTask(taskID string) (time.Time, error) {
+	expires, _, err := e.tasks.heartbeat(taskID)
+	return expires, err
+}
+
+// ReportTaskProgress görevin kiralamasını uzatır ve işçinin bildirdiği ayrıntıları
+// adımın Heartbeat çağrısı olarak iletir; adımın HeartbeatTimeout süresi yalnızca
+// bu çağrılarla yenilenir
+func (e *WorkflowEngine) ReportTaskProgress(taskID string, details interface{}) (time.Time, error) {
+	expires, watch, err := e.tasks.heartbeat(taskID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if watch != nil {
+		watch.beat(details)
+	}
+	return expires, nil
+}
+
+// CompleteTask görevi sonucuyla tamamlar
+func (e *WorkflowEngine) CompleteTask(taskID string, result interface{}) error {
+	return e.tasks.finish(taskID, taskResult{value: result})
+}
+
+// FailTask görevi hatayla sonlandırır; hata adım hatası olarak ele alınır
+func (e *WorkflowEngine) FailTask(taskID, message string) error {
+	return e.tasks.finish(taskID, taskResult{err: &TaskError{TaskID: taskID, Message: message}})
+}
+
+// taskQueues uzak görevleri kuyruklarda tutar ve bekleyen işçilere dağıtır.
+// Bekleyen görevler ve işçiler geliş sırasıyla eşleştirilir; işçi kuyruk veya
+// adım kimliğiyle hangi görevleri alabileceğini belirler.
+type taskQueues struct {
+	clock Clock
+	lease time.Duration
+
+	mutex   sync.Mutex
+	pending []*remoteTask
+	pollers []*taskPoller
+	active  map[string]*remoteTask
+}
+
+// taskPoller görev bekleyen bir işçidir
+type taskPoller struct {
+	worker string
+	match  func(*Task) bool
+	ch     chan *remoteTask
+}
+
+// remoteTask kuyruktaki veya bir işçide kiralı duran görevdir
+type remoteTask struct {
+	task     Task
+	watch    *heartbeatWatch
+	deadline time.Time
+	done     chan taskResult
+	// leased görev her kiralandığında bekleyen dispatch'i uyandırır
+	leased chan struct{}
+}
+
+// taskResult işçinin bildirdiği sonuçtur
+type taskResult struct {
+	value interface{}
+	err   error
+}
+
+func newTaskQueues() *taskQueues {
+	return &taskQueues{
+		lease:  DefaultTaskLease,
+		active: make(map[string]*remoteTask),
+	}
+}
+
+// dispatch denemeyi görev olarak kuyruğa ekler ve sonucunu bekler. Kiralaması
+// dolan görev ErrTaskLeaseExpired ile, ctx biten görev ctx hatasıyla sonlanır.
+func (q *taskQueues) dispatch(ctx context.Context, queue, stepID string, data interface{}) (interface{}, error) {
+	info, _ := StepInfoFromContext(ctx)
+	entry := &remoteTask{
+		task: Task{
+			ID:               newTaskID(),
+			Queue:            queue,
+			InstanceID:       info.InstanceID,
+			WorkflowID:       info.WorkflowID,
+			StepID:           stepID,
+			Attempt:          info.Attempt,
+			IdempotencyToken: info.IdempotencyToken,
+			Input:            data,
+			HeartbeatDetails: info.HeartbeatDetails,
+		},
+		watch:  heartbeatWatchFrom(ctx),
+		done:   make(chan taskResult, 1),
+		leased: make(chan struct{}, 1),
+	}
+
+	q.mutex.Lock()
+	q.offerLocked(entry)
+	q.mutex.Unlock()
+
+	for {
+		q.mutex.Lock()
+		var expiry <-chan time.Time
+		if _, leased := q.active[entry.task.ID]; leased {
+			expiry = q.clock.After(entry.deadline.Sub(q.clock.Now()))
+		}
+		q.mutex.Unlock()
+
+		select {
+		case result := <-entry.done:
+			return result.value, result.err
+		case <-entry.leased:
+			// Kiralama süresi yeni işçiye göre kurulur
+			continue
+		case <-ctx.Done():
+			q.mutex.Lock()
+			q.removeLocked(entry)
+			q.mutex.Unlock()
+			return nil, ctx.Err()
+		case <-expiry:
+			// Kalp atışı kiralamayı uzatmışsa veya görev kuyruğa geri konmuşsa
+			// bekleme sürer
+			q.mutex.Lock()
+			if _, leased := q.active[entry.task.ID]; !leased || q.clock.Now().Before(entry.deadline) {
+				q.mutex.Unlock()
+				continue
+			}
+			q.removeLocked(entry)
+			q.mutex.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrTaskLeaseExpired, entry.task.ID)
+		}
+	}
+}
+
+// offerLocked görevi onu alabilecek ilk işçiye verir, yoksa kuyruğun sonuna ekler
+func (q *taskQueues) offerLocked(entry *remoteTask) {
+	if !q.handOffLocked(entry) {
+		q.pending = append(q.pending, entry)
+	}
+}
+
+// handOffLocked görevi bekleyen ve onu alabilecek ilk işçiye verir
+func (q *taskQueues) handOffLocked(entry *remoteTask) bool {
+	for i, poller := range q.pollers {
+		if poller.match(&entry.task) {
+			q.pollers = append(q.pollers[:i], q.pollers[i+1:]...)
+			q.leaseLocked(entry, poller.worker)
+			poller.ch <- entry
+			return true
+		}
+	}
+	return false
+}
+
+// leaseLocked görevi worker adına kiralanmış olarak işaretler
+func (q *taskQueues) leaseLocked(entry *remoteTask, worker string) {
+	entry.task.Worker = worker
+	entry.deadline = q.clock.Now().Add(q.lease)
+	entry.task.LeaseExpiresAt = entry.deadline
+	q.active[entry.task.ID] = entry
+	select {
+	case entry.leased <- struct{}{}:
+	default:
+	}
+}
+
+// poll match'e uyan ilk görevi kiralar veya uygun bir görev gelene kadar bekler
+func (q *taskQueues) poll(ctx context.Context, worker string, match func(*Task) bool) (*Task, error) {
+	q.mutex.Lock()
+	for i, entry := range q.pending {
+		if match(&entry.task) {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.leaseLocked(entry, worker)
+			task := entry.task
+			q.mutex.Unlock()
+			return &task, nil
+		}
+	}
+	poller := &taskPoller{worker: worker, match: match, ch: make(chan *remoteTask, 1)}
+	q.pollers = append(q.pollers, poller)
+	q.mutex.Unlock()
+
+	select {
+	case entry := <-poller.ch:
+		q.mutex.Lock()
+		task := entry.task
+		q.mutex.Unlock()
+		return &task, nil
+	case <-ctx.Done():
+		q.mutex.Lock()
+		defer q.mutex.Unlock()
+		for i, waiting := range q.pollers {
+			if waiting == poller {
+				q.pollers = append(q.pollers[:i], q.pollers[i+1:]...)
+				return nil, nil
+			}
+		}
+		// Görev iptalle aynı anda verildi; adım hâlâ bekliyorsa başka bir işçiye
+		// verilir ya da sırasını kaybetmeden kuyruğun başına geri konur
+		entry := <-poller.ch
+		if _, leased := q.active[entry.task.ID]; leased {
+			delete(q.active, entry.task.ID)
+			entry.task.Worker = ""
+			if !q.handOffLocked(entry) {
+				q.pending = append([]*remoteTask{entry}, q.pending...)
+			}
+		}
+		return nil, nil
+	}
+}
+
+// heartbeat kiralı görevin süresini uzatır ve denemenin kalp atışı izleyicisini döndürür
+func (q *taskQueues) heartbeat(taskID string) (time.Time, *heartbeatWatch, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	entry, ok := q.active[taskID]
+	if !ok {
+		return time.Time{}, nil, ErrTaskNotFound
+	}
+	entry.deadline = q.clock.Now().Add(q.lease)
+	entry.task.LeaseExpiresAt = entry.deadline
+	return entry.deadline, entry.watch, nil
+}
+
+// finish kiralı görevin sonucunu bekleyen adıma iletir
+func (q *taskQueues) finish(taskID string, result taskResult) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	entry, ok := q.active[taskID]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	delete(q.active, taskID)
+	entry.done <- result
+	return nil
+}
+
+// removeLocked görevi kuyruktan ve kiralı görevlerden çıkarır
+func (q *taskQueues) removeLocked(entry *remoteTask) {
+	delete(q.active, entry.task.ID)
+	for i, queued := range q.pending {
+		if queued == entry {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// newTaskID tahmin edilemeyen bir görev kimliği üretir; kimlik işçinin görev
+// üzerindeki yetkisini de temsil eder
+func newTaskID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("görev kimliği üretilemedi: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
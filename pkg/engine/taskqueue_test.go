@@ -0,0 +1,290 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newRemoteEngine charge adımını payments kuyruğuna bağlayan bir motor oluşturur
+func newRemoteEngine(t *testing.T, clock *ManualClock, step StepDefinition, opts ...EngineOption) *WorkflowEngine {
+	t.Helper()
+	engine := NewWorkflowEngine(append([]EngineOption{WithClock(clock)}, opts...)...)
+	engine.RegisterRemoteStep("charge", "payments")
+	if err := engine.RegisterDefinition(context.Background(), singleStepDefinition("charge", step)); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	return engine
+}
+
+// startAsync örneği arka planda başlatır ve bittiğinde çalışma zamanını iletir
+func startAsync(t *testing.T, engine *WorkflowEngine, input map[string]interface{}) <-chan *WorkflowRuntime {
+	done := make(chan *WorkflowRuntime, 1)
+	go func() {
+		runtime, err := engine.StartWorkflow(context.Background(), "charge", input)
+		if err != nil && runtime == nil {
+			t.Errorf("StartWorkflow failed: %v", err)
+		}
+		done <- runtime
+	}()
+	return done
+}
+
+// mustPoll kuyruktan bir görev alır
+func mustPoll(t *testing.T, engine *WorkflowEngine, worker string) *Task {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	task, err := engine.PollTask(ctx, "payments", worker)
+	if err != nil || task == nil {
+		t.Fatalf("PollTask returned %v, %v", task, err)
+	}
+	return task
+}
+
+func TestRemoteStepRoundTrip(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := newRemoteEngine(t, clock, NewStepDefinition("charge", "Charge", StepTypeTask))
+	done := startAsync(t, engine, map[string]interface{}{"amount": 42})
+
+	task := mustPoll(t, engine, "worker-1")
+	if task.StepID != "charge" || task.WorkflowID != "charge" || task.InstanceID == "" || task.Worker != "worker-1" {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+	if !task.LeaseExpiresAt.Equal(clock.Now().Add(DefaultTaskLease)) {
+		t.Errorf("Expected lease until %s, got %s", clock.Now().Add(DefaultTaskLease), task.LeaseExpiresAt)
+	}
+	if task.IdempotencyToken == "" {
+		t.Error("Task should carry the attempt's idempotency token")
+	}
+
+	if err := engine.CompleteTask(task.ID, "charged"); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	runtime := <-done
+	state := runtime.GetState()
+	if state.Status != StatusCompleted || state.StepResults["charge"] != "charged" {
+		t.Errorf("Expected completed instance with worker result, got %s %v", state.Status, state.StepResults)
+	}
+
+	// Tamamlanan görev ikinci kez bildirilemez
+	if err := engine.CompleteTask(task.ID, "again"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestFailedTaskIsRetried(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := newRemoteEngine(t, clock, NewStepDefinition("charge", "Charge", StepTypeTask).
+		WithRetryPolicy(2, 0, 0, 1))
+	done := startAsync(t, engine, nil)
+
+	first := mustPoll(t, engine, "worker-1")
+	if err := engine.FailTask(first.ID, "card declined"); err != nil {
+		t.Fatalf("FailTask failed: %v", err)
+	}
+
+	// Yeniden deneme yeni bir görev olarak kuyruğa gelir
+	second := mustPoll(t, engine, "worker-2")
+	if second.ID == first.ID || second.Attempt != first.Attempt+1 || second.IdempotencyToken == first.IdempotencyToken {
+		t.Errorf("Retry should be a new attempt, got %+v after %+v", second, first)
+	}
+	if err := engine.CompleteTask(second.ID, "charged"); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	if runtime := <-done; runtime.GetState().Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", runtime.GetState().Status)
+	}
+}
+
+func TestExpiredLeaseFailsAttempt(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := newRemoteEngine(t, clock, NewStepDefinition("charge", "Charge", StepTypeTask), WithTaskLease(10*time.Second))
+	done := startAsync(t, engine, nil)
+
+	task := mustPoll(t, engine, "worker-1")
+	clock.Advance(10 * time.Second)
+
+	runtime := <-done
+	state := runtime.GetState()
+	if state.Status != StatusFailed || !errors.Is(state.Error, ErrTaskLeaseExpired) {
+		t.Errorf("Expected failed instance with lease error, got %s %v", state.Status, state.Error)
+	}
+
+	// Kiralaması dolan görev artık işçiye ait değildir
+	if _, err := engine.HeartbeatTask(task.ID); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for heartbeat, got %v", err)
+	}
+	if err := engine.CompleteTask(task.ID, "late"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for late completion, got %v", err)
+	}
+}
+
+func TestHeartbeatExtendsLease(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock), WithTaskLease(10*time.Second))
+	engine.RegisterRemoteStep("transcode", "media")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.ExecuteStep(context.Background(), "transcode", "video.mp4")
+		done <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	task, err := engine.PollTask(ctx, "media", "worker-1")
+	if err != nil || task == nil || task.Input != "video.mp4" {
+		t.Fatalf("PollTask returned %+v, %v", task, err)
+	}
+
+	// Her kalp atışı kiralamayı o andan itibaren uzatır
+	for i := 0; i < 3; i++ {
+		clock.Advance(8 * time.Second)
+		expires, err := engine.HeartbeatTask(task.ID)
+		if err != nil {
+			t.Fatalf("HeartbeatTask failed: %v", err)
+		}
+		if !expires.Equal(clock.Now().Add(10 * time.Second)) {
+			t.Errorf("Expected lease until %s, got %s", clock.Now().Add(10*time.Second), expires)
+		}
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Step should still be running, returned %v", err)
+	default:
+	}
+
+	if err := engine.CompleteTask(task.ID, "done"); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected step to succeed, got %v", err)
+	}
+}
+
+func TestCanceledStepWithdrawsTask(t *testing.T) {
+	engine := NewWorkflowEngine()
+	engine.RegisterRemoteStep("transcode", "media")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.ExecuteStep(ctx, "transcode", nil)
+		done <- err
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		engine.tasks.mutex.Lock()
+		queued := len(engine.tasks.pending)
+		engine.tasks.mutex.Unlock()
+		if queued == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Task was never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context canceled, got %v", err)
+	}
+
+	// İptal edilen adımın görevi kuyruktan çekilir; yoklama boş döner
+	pollCtx, pollCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer pollCancel()
+	task, err := engine.PollTask(pollCtx, "media", "worker-1")
+	if err != nil || task != nil {
+		t.Errorf("Expected empty poll, got %+v, %v", task, err)
+	}
+}
+
+func TestPollTaskForStepsMatchesAcrossQueues(t *testing.T) {
+	engine := NewWorkflowEngine()
+	engine.RegisterRemoteStep("transcode", "media")
+	engine.RegisterRemoteStep("thumbnail", "media")
+	if err := engine.EnsureRemoteStep("ocr", "ocr"); err != nil {
+		t.Fatalf("EnsureRemoteStep failed: %v", err)
+	}
+
+	for _, stepID := range []string{"transcode", "thumbnail", "ocr"} {
+		go engine.ExecuteStep(context.Background(), stepID, stepID)
+	}
+
+	// İşçi yalnızca kaydolduğu adımların görevlerini alır
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	seen := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		task, err := engine.PollTaskForSteps(ctx, []string{"thumbnail", "ocr"}, "worker-1")
+		if err != nil || task == nil {
+			t.Fatalf("PollTaskForSteps returned %v, %v", task, err)
+		}
+		seen[task.StepID] = true
+		engine.CompleteTask(task.ID, nil)
+	}
+	if !seen["thumbnail"] || !seen["ocr"] {
+		t.Errorf("Expected thumbnail and ocr tasks, got %v", seen)
+	}
+	task, err := engine.PollTask(ctx, "media", "worker-2")
+	if err != nil || task == nil || task.StepID != "transcode" {
+		t.Fatalf("Expected transcode task to stay queued, got %+v, %v", task, err)
+	}
+	engine.CompleteTask(task.ID, nil)
+}
+
+func TestEnsureRemoteStepKeepsLocalSteps(t *testing.T) {
+	engine := NewWorkflowEngine()
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if err := engine.EnsureRemoteStep("charge", "charge"); !errors.Is(err, ErrStepRegistered) {
+		t.Errorf("Expected ErrStepRegistered, got %v", err)
+	}
+
+	// Uzak adım yerel bir fonksiyonla yeniden kaydedilince artık uzak değildir
+	engine.RegisterRemoteStep("refund", "payments")
+	if queue, ok := engine.RemoteQueue("refund"); !ok || queue != "payments" {
+		t.Errorf("Expected refund on payments queue, got %q", queue)
+	}
+	engine.RegisterStep("refund", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if _, ok := engine.RemoteQueue("refund"); ok {
+		t.Error("Locally registered step should not be remote")
+	}
+}
+
+func TestTaskProgressResetsHeartbeatTimeout(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := newRemoteEngine(t, clock, NewStepDefinition("charge", "Charge", StepTypeTask).
+		WithHeartbeatTimeout(time.Minute), WithTaskLease(time.Hour))
+	done := startAsync(t, engine, nil)
+	task := mustPoll(t, engine, "worker-1")
+
+	// İlerleme bildirimi adımın kalp atışı süresini yeniler
+	clock.Advance(50 * time.Second)
+	if _, err := engine.ReportTaskProgress(task.ID, 50); err != nil {
+		t.Fatalf("ReportTaskProgress failed: %v", err)
+	}
+	clock.Advance(50 * time.Second)
+
+	// Ayrıntısız kalp atışı yalnızca kiralamayı uzatır
+	if _, err := engine.HeartbeatTask(task.ID); err != nil {
+		t.Fatalf("HeartbeatTask failed: %v", err)
+	}
+	select {
+	case runtime := <-done:
+		t.Fatalf("Step should still be running, got %s", runtime.GetState().Status)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(10 * time.Second)
+
+	runtime := <-done
+	if state := runtime.GetState(); state.Status != StatusFailed || !errors.Is(state.Error, ErrHeartbeatTimeout) {
+		t.Errorf("Expected heartbeat timeout, got %s %v", state.Status, state.Error)
+	}
+}
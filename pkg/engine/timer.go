@@ -0,0 +1,307 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Zamanlayıcı adımı yapılandırma anahtarları.
+//
+//	{"duration": "72h"}                  // adıma gelindiği andan itibaren bekle
+//	{"until": "2025-01-02T15:04:05Z"}    // sabit bir zamana kadar bekle
+//	{"until": "context.remind_at"}       // bağlamdaki veya bir adım sonucundaki zamana kadar bekle
+const (
+	TimerConfigDuration = "duration"
+	TimerConfigUntil    = "until"
+)
+
+// DefaultTimerPollInterval zamanlayıcı servisinin depoyu yoklama aralığıdır
+const DefaultTimerPollInterval = time.Second
+
+// scheduleTimer zamanlayıcı adımı için uyanma zamanını kalıcı olarak kaydeder ve
+// örneği bekleme durumuna alır; çalışan goroutine serbest bırakılır
+func (r *WorkflowRuntime) scheduleTimer(ctx context.Context, step *StepDefinition) error {
+	wakeAt, err := r.timerWakeAt(step)
+	if err != nil {
+		return r.fail(ctx, err)
+	}
+
+	r.mutex.Lock()
+	if r.state.Status != StatusRunning {
+		r.mutex.Unlock()
+		return nil
+	}
+	r.state.Status = StatusWaiting
+	r.state.WakeAt = &wakeAt
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryTimerScheduled,
+		StepID:    step.ID,
+		Timestamp: r.engine.clock.Now(),
+		TimerKind: TimerKindSleep,
+		WakeAt:    &wakeAt,
+	})
+	r.mutex.Unlock()
+
+	// Önce zamanlayıcı yazılır: durum kaydından önce çökülürse örnek çalışır
+	// durumda kalır ve Resume ile adım yeniden planlanır
+	timer := Timer{
+		ID:         timerID(r.id, step.ID, TimerKindSleep),
+		InstanceID: r.id,
+		StepID:     step.ID,
+		Kind:       TimerKindSleep,
+		WakeAt:     wakeAt,
+	}
+	if err := r.engine.store.SaveTimer(ctx, timer); err != nil {
+		return fmt.Errorf("zamanlayıcı kaydedilemedi: %w", err)
+	}
+	if err := r.persist(ctx); err != nil {
+		return err
+	}
+	r.log.Info("workflow waiting", LogKeyStepID, step.ID, "reason", "timer", "wake_at", wakeAt)
+
+	r.engine.notifyObservers(Event{
+		Type:       EventTimerScheduled,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     step.ID,
+		Data:       wakeAt,
+		Timestamp:  r.engine.clock.Now(),
+	})
+	return nil
+}
+
+// timerWakeAt adım yapılandırmasından uyanma zamanını hesaplar
+func (r *WorkflowRuntime) timerWakeAt(step *StepDefinition) (time.Time, error) {
+	if raw, ok := step.Config[TimerConfigDuration]; ok {
+		d, err := parseDuration(raw)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("geçersiz zamanlayıcı süresi (%s): %w", step.ID, err)
+		}
+		return r.engine.clock.Now().Add(d), nil
+	}
+
+	if raw, ok := step.Config[TimerConfigUntil]; ok {
+		expr, ok := raw.(string)
+		if !ok {
+			if t, ok := raw.(time.Time); ok {
+				return t, nil
+			}
+			return time.Time{}, fmt.Errorf("geçersiz zamanlayıcı bitişi (%s): %v", step.ID, raw)
+		}
+		if t, err := time.Parse(time.RFC3339, expr); err == nil {
+			return t, nil
+		}
+
+		r.mutex.RLock()
+		value, err := r.state.resolvePath(expr)
+		r.mutex.RUnlock()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("zamanlayıcı bitişi çözülemedi (%s): %w", step.ID, err)
+		}
+		t, err := parseTime(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("geçersiz zamanlayıcı bitişi (%s): %w", step.ID, err)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("zamanlayıcı adımında süre veya bitiş zamanı yok: %s", step.ID)
+}
+
+// fireTimer vadesi gelen zamanlayıcıyı işler ve örneği kaldığı yerden devam ettirir
+func (r *WorkflowRuntime) fireTimer(ctx context.Context, timer Timer) error {
+	if timer.Kind == TimerKindRetry {
+		return r.fireRetryTimer(ctx, timer)
+	}
+
+	r.mutex.Lock()
+	if r.state.Status != StatusWaiting || r.state.CurrentStepID != timer.StepID {
+		// Eskimiş zamanlayıcı: örnek başka bir yoldan ilerlemiş
+		r.mutex.Unlock()
+		return r.engine.store.DeleteTimer(ctx, timer.ID)
+	}
+	r.state.wake()
+	wakeAt := timer.WakeAt
+	r.recordLocked(HistoryEvent{
+		Type:      HistoryTimerFired,
+		StepID:    timer.StepID,
+		Timestamp: r.engine.clock.Now(),
+		TimerKind: timer.Kind,
+		WakeAt:    &wakeAt,
+	})
+	r.mutex.Unlock()
+
+	step := r.stepByID(timer.StepID)
+	if step == nil {
+		return r.fail(ctx, fmt.Errorf("adım bulunamadı: %s", timer.StepID))
+	}
+
+	r.log.Info("workflow resumed", LogKeyStepID, timer.StepID, "reason", string(timer.Kind))
+	r.engine.notifyObservers(Event{
+		Type:       EventTimerFired,
+		InstanceID: r.id,
+		WorkflowID: r.definition.ID,
+		StepID:     timer.StepID,
+		Data:       timer.WakeAt,
+		Timestamp:  r.engine.clock.Now(),
+	})
+
+	if timer.Kind == TimerKindSignalTimeout {
+		if err := r.engine.store.DeleteTimer(ctx, timer.ID); err != nil {
+			return err
+		}
+		return r.signalTimeout(ctx, step)
+	}
+	if timer.Kind == TimerKindCompletionTimeout {
+		if err := r.engine.store.DeleteTimer(ctx, timer.ID); err != nil {
+			return err
+		}
+		return r.completionTimeout(ctx, step)
+	}
+
+	more, err := r.completeStep(ctx, step, timer.WakeAt)
+	if err != nil {
+		return err
+	}
+	return r.resume(ctx, more, timer.ID)
+}
+
+// deleteTimers adım için kaydedilmiş tüm zamanlayıcıları siler
+func (r *WorkflowRuntime) deleteTimers(ctx context.Context, stepID string) error {
+	for _, kind := range timerKinds {
+		if err := r.engine.store.DeleteTimer(ctx, timerID(r.id, stepID, kind)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FireDueTimers vadesi gelmiş zamanlayıcıları tetikler ve ilgili örnekleri devam
+// ettirir. Devam eden örnekler bir sonraki bekleme noktasına veya sona ulaşana
+// kadar bekler; tetiklenen zamanlayıcı sayısını döndürür.
+func (e *WorkflowEngine) FireDueTimers(ctx context.Context) (int, error) {
+	var wg sync.WaitGroup
+	fired, err := e.fireDueTimers(ctx, &wg)
+	wg.Wait()
+	return fired, err
+}
+
+// RunTimers zamanlayıcı servisini ctx iptal edilene kadar çalıştırır. Süreç yeniden
+// başlatıldığında depodaki zamanlayıcılar buradan tetiklenir ve örnekler depodan yüklenir.
+func (e *WorkflowEngine) RunTimers(ctx context.Context) error {
+	for {
+		if _, err := e.fireDueTimers(ctx, nil); err != nil && ctx.Err() == nil {
+			e.diagnostics().Error("timer service failed", "error", err)
+			e.notifyObservers(Event{
+				Type:      EventTimerFailed,
+				Data:      err,
+				Timestamp: e.clock.Now(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.clock.After(e.timerPollInterval):
+		}
+	}
+}
+
+// fireDueTimers vadesi gelen zamanlayıcıları ayrı goroutine'lerde tetikler. Bir
+// zamanlayıcının örneği yüklenemezse hata kaydedilir ve kalan zamanlayıcılarla
+// devam edilir; zamanlayıcı depoda kaldığından sonraki turda yeniden denenir.
+// Kaydedilen hatalar birleştirilerek döndürülür.
+func (e *WorkflowEngine) fireDueTimers(ctx context.Context, wg *sync.WaitGroup) (int, error) {
+	timers, err := e.store.DueTimers(ctx, e.clock.Now(), 0)
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	var errs []error
+	for _, timer := range timers {
+		if !e.claimTimer(timer.ID) {
+			continue
+		}
+
+		runtime, err := e.runtimeFor(ctx, timer.InstanceID)
+		if err != nil {
+			e.releaseTimer(timer.ID)
+			if errors.Is(err, ErrNotFound) {
+				// Örneği olmayan zamanlayıcı temizlenir
+				err = e.store.DeleteTimer(ctx, timer.ID)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("zamanlayıcı %s: %w", timer.ID, err))
+			}
+			continue
+		}
+
+		fired++
+		if wg != nil {
+			wg.Add(1)
+		}
+		go func(runtime *WorkflowRuntime, timer Timer) {
+			if wg != nil {
+				defer wg.Done()
+			}
+			defer e.releaseTimer(timer.ID)
+			// Adım hataları örnek durumuna yazılır; servis çalışmaya devam eder
+			_ = runtime.fireTimer(ctx, timer)
+		}(runtime, timer)
+	}
+	return fired, errors.Join(errs...)
+}
+
+// claimTimer zamanlayıcıyı tetiklenmek üzere işaretler; zaten tetikleniyorsa false döner
+func (e *WorkflowEngine) claimTimer(id string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.firing[id] {
+		return false
+	}
+	e.firing[id] = true
+	return true
+}
+
+// releaseTimer zamanlayıcı üzerindeki tetikleme işaretini kaldırır
+func (e *WorkflowEngine) releaseTimer(id string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.firing, id)
+}
+
+// parseDuration yapılandırmadaki süre değerini çözer
+func parseDuration(raw interface{}) (time.Duration, error) {
+	switch v := raw.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(v)
+	case int:
+		return time.Duration(v), nil
+	case int64:
+		return time.Duration(v), nil
+	case float64:
+		return time.Duration(v), nil
+	}
+	return 0, fmt.Errorf("desteklenmeyen süre değeri: %v", raw)
+}
+
+// parseTime bir zaman değerini veya RFC3339 metnini çözer
+func parseTime(raw interface{}) (time.Time, error) {
+	switch v := raw.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		return time.Parse(time.RFC3339, v)
+	}
+	return time.Time{}, fmt.Errorf("desteklenmeyen zaman değeri: %v", raw)
+}
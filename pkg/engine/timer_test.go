@@ -0,0 +1,348 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type reminder struct {
+	RemindAt time.Time
+}
+
+func newTimerDefinition() *WorkflowDefinition {
+	definition := NewWorkflowDefinition("reminder", "Reminder Workflow", "Wait then remind")
+	definition.AddStep(NewStepDefinition("prepare", "Prepare", StepTypeTask).
+		WithNextSteps("wait"))
+	definition.AddStep(NewStepDefinition("wait", "Wait", StepTypeTimer).
+		WithConfig(map[string]interface{}{TimerConfigDuration: "72h"}).
+		WithNextSteps("remind"))
+	definition.AddStep(NewStepDefinition("remind", "Remind", StepTypeTask))
+	return definition
+}
+
+func registerTimerSteps(engine *WorkflowEngine, reminded chan<- string) {
+	engine.RegisterStep("prepare", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "prepared", nil
+	})
+	engine.RegisterStep("remind", func(ctx context.Context, data interface{}) (interface{}, error) {
+		reminded <- "reminded"
+		return "reminded", nil
+	})
+}
+
+func TestTimerStepWaitsAndResumes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	engine := NewWorkflowEngine(WithClock(clock))
+
+	reminded := make(chan string, 1)
+	registerTimerSteps(engine, reminded)
+
+	runtime := NewWorkflowRuntime(engine, newTimerDefinition())
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Zamanlayıcı adımında örnek beklemeye geçmeli
+	state := runtime.GetState()
+	if state.Status != StatusWaiting {
+		t.Fatalf("Expected waiting status, got %s", state.Status)
+	}
+	if state.CurrentStepID != "wait" {
+		t.Errorf("Expected current step wait, got %s", state.CurrentStepID)
+	}
+	if state.WakeAt == nil || !state.WakeAt.Equal(start.Add(72*time.Hour)) {
+		t.Errorf("Unexpected wake time: %v", state.WakeAt)
+	}
+
+	// Vakti gelmeden zamanlayıcı tetiklenmemeli
+	fired, err := engine.FireDueTimers(context.Background())
+	if err != nil || fired != 0 {
+		t.Fatalf("Expected no due timers, got %d (%v)", fired, err)
+	}
+
+	clock.Advance(72 * time.Hour)
+	fired, err = engine.FireDueTimers(context.Background())
+	if err != nil || fired != 1 {
+		t.Fatalf("Expected one due timer, got %d (%v)", fired, err)
+	}
+
+	select {
+	case <-reminded:
+	default:
+		t.Error("Remind step should have run after the timer fired")
+	}
+
+	state = runtime.GetState()
+	if state.Status != StatusCompleted {
+		t.Errorf("Expected completed status, got %s", state.Status)
+	}
+	if state.StepResults["wait"] != start.Add(72*time.Hour) {
+		t.Errorf("Timer step result should be the wake time, got %v", state.StepResults["wait"])
+	}
+
+	timers, _ := engine.Store().DueTimers(context.Background(), clock.Now(), 0)
+	if len(timers) != 0 {
+		t.Errorf("Fired timer should be deleted, found %d", len(timers))
+	}
+}
+
+func TestTimerStepUntilExpression(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	engine := NewWorkflowEngine(WithClock(clock))
+
+	remindAt := start.Add(90 * time.Minute)
+	engine.RegisterStep("schedule", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return &reminder{RemindAt: remindAt}, nil
+	})
+
+	definition := NewWorkflowDefinition("until", "Until Workflow", "")
+	definition.AddStep(NewStepDefinition("schedule", "Schedule", StepTypeTask).WithNextSteps("wait"))
+	definition.AddStep(NewStepDefinition("wait", "Wait", StepTypeTimer).
+		WithConfig(map[string]interface{}{TimerConfigUntil: "steps.schedule.RemindAt"}))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	state := runtime.GetState()
+	if state.WakeAt == nil || !state.WakeAt.Equal(remindAt) {
+		t.Fatalf("Expected wake time %v, got %v", remindAt, state.WakeAt)
+	}
+
+	clock.Set(remindAt)
+	if _, err := engine.FireDueTimers(context.Background()); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+	if runtime.GetState().Status != StatusCompleted {
+		t.Error("Workflow should complete once the until time is reached")
+	}
+}
+
+func TestTimerStepInvalidConfig(t *testing.T) {
+	engine := NewWorkflowEngine()
+	definition := NewWorkflowDefinition("invalid", "Invalid Timer", "")
+	definition.AddStep(NewStepDefinition("wait", "Wait", StepTypeTimer).
+		WithConfig(map[string]interface{}{TimerConfigDuration: "soon"}))
+
+	runtime := NewWorkflowRuntime(engine, definition)
+	if err := runtime.Start(context.Background()); err == nil {
+		t.Error("Start should fail with an invalid duration")
+	}
+	if runtime.GetState().Status != StatusFailed {
+		t.Error("Workflow should be failed")
+	}
+}
+
+func TestTimerSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	store := NewMemoryStore()
+
+	// İlk süreç tanımı kaydeder ve örneği beklemeye alır
+	first := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	registerTimerSteps(first, make(chan string, 1))
+	definition := newTimerDefinition()
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// İkinci süreç yalnızca depoyu ve adım kayıtlarını paylaşır
+	reminded := make(chan string, 1)
+	second := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	registerTimerSteps(second, reminded)
+
+	clock.Advance(72 * time.Hour)
+	fired, err := second.FireDueTimers(ctx)
+	if err != nil || fired != 1 {
+		t.Fatalf("Expected one due timer, got %d (%v)", fired, err)
+	}
+
+	select {
+	case <-reminded:
+	default:
+		t.Error("Remind step should run on the restarted engine")
+	}
+
+	record, err := store.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != StatusCompleted {
+		t.Errorf("Expected completed status in store, got %s", record.State.Status)
+	}
+}
+
+// unreadableStore broken örneğinin kaydını okuyamayan bir depodur
+type unreadableStore struct {
+	WorkflowStore
+}
+
+func (s *unreadableStore) GetInstance(ctx context.Context, id string) (*InstanceRecord, error) {
+	if id == "broken" {
+		return nil, errors.New("connection reset")
+	}
+	return s.WorkflowStore.GetInstance(ctx, id)
+}
+
+func TestFireDueTimersContinuesAfterLoadError(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	store := &unreadableStore{NewMemoryStore()}
+
+	engine := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	reminded := make(chan string, 1)
+	registerTimerSteps(engine, reminded)
+	runtime := NewWorkflowRuntime(engine, newTimerDefinition())
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Yüklenemeyen örneğin zamanlayıcısı sırada önce gelir
+	broken := Timer{ID: "broken/wait/sleep", InstanceID: "broken", StepID: "wait", Kind: TimerKindSleep, WakeAt: clock.Now()}
+	if err := store.SaveTimer(ctx, broken); err != nil {
+		t.Fatalf("SaveTimer failed: %v", err)
+	}
+
+	clock.Advance(72 * time.Hour)
+	fired, err := engine.FireDueTimers(ctx)
+	if err == nil || !strings.Contains(err.Error(), broken.ID) {
+		t.Errorf("Expected the load error for %s, got %v", broken.ID, err)
+	}
+	if fired != 1 {
+		t.Errorf("Expected the remaining timer to fire, got %d", fired)
+	}
+	select {
+	case <-reminded:
+	default:
+		t.Error("Remind step should run despite the broken timer")
+	}
+
+	// Hatalı zamanlayıcı sonraki turda yeniden denenmek üzere kalır
+	timers, _ := store.DueTimers(ctx, clock.Now(), 0)
+	if len(timers) != 1 || timers[0].ID != broken.ID {
+		t.Errorf("Expected only the broken timer to remain, got %v", timers)
+	}
+}
+
+func TestRecoverWaitingInstances(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	store := NewMemoryStore()
+
+	first := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	registerTimerSteps(first, make(chan string, 1))
+	definition := newTimerDefinition()
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	second := NewWorkflowEngine(WithStore(store), WithClock(clock))
+	recovered, err := second.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0].ID() != runtime.ID() {
+		t.Fatalf("Expected the waiting instance to be recovered, got %d", len(recovered))
+	}
+	if recovered[0].GetState().Status != StatusWaiting {
+		t.Error("Recovered instance should still be waiting")
+	}
+	if _, ok := second.GetRuntime(runtime.ID()); !ok {
+		t.Error("Recovered instance should be tracked by the engine")
+	}
+}
+
+func TestCancelWaitingInstance(t *testing.T) {
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock))
+	registerTimerSteps(engine, make(chan string, 1))
+
+	runtime := NewWorkflowRuntime(engine, newTimerDefinition())
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := runtime.Cancel(); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	clock.Advance(72 * time.Hour)
+	fired, err := engine.FireDueTimers(ctx)
+	if err != nil || fired != 0 {
+		t.Errorf("Canceled instance timer should be removed, fired %d (%v)", fired, err)
+	}
+	if runtime.GetState().Status != StatusCanceled {
+		t.Error("Workflow should stay canceled")
+	}
+}
+
+func TestRunTimers(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewWorkflowEngine(WithClock(clock), WithTimerPollInterval(time.Minute))
+
+	reminded := make(chan string, 1)
+	registerTimerSteps(engine, reminded)
+
+	runtime := NewWorkflowRuntime(engine, newTimerDefinition())
+	if err := runtime.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.RunTimers(ctx)
+	}()
+
+	// Servis yoklama aralığında saati ilerletmeye devam et
+	deadline := time.After(5 * time.Second)
+	for {
+		clock.Advance(time.Hour)
+		select {
+		case <-reminded:
+			cancel()
+			if err := <-done; err != context.Canceled {
+				t.Errorf("RunTimers should stop with context.Canceled, got %v", err)
+			}
+			return
+		case <-deadline:
+			cancel()
+			t.Fatal("Timer service did not resume the workflow")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ch := clock.After(time.Minute)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("After should not fire before the deadline")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("After should fire once the deadline is reached")
+	}
+}
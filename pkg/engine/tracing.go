@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName motorun OpenTelemetry izleyicisinin adıdır
+const tracerName = "github.com/parevo-lab/maestro"
+
+// Span öznitelik anahtarları
+const (
+	attrWorkflowID     = attribute.Key("maestro.workflow.id")
+	attrInstanceID     = attribute.Key("maestro.instance.id")
+	attrWorkflowStatus = attribute.Key("maestro.workflow.status")
+	attrResumed        = attribute.Key("maestro.workflow.resumed")
+	attrStepID         = attribute.Key("maestro.step.id")
+	attrStepType       = attribute.Key("maestro.step.type")
+	attrStepAttempt    = attribute.Key("maestro.step.attempt")
+	attrStepStatus     = attribute.Key("maestro.step.status")
+)
+
+// traceContextPropagator örneğin iz bağlamını W3C traceparent biçiminde saklar;
+// uygulamanın genel yayıcısından bağımsızdır
+var traceContextPropagator = propagation.TraceContext{}
+
+// WithTracerProvider örnek ve adım span'lerinin oluşturulacağı sağlayıcıyı
+// belirler; verilmezse otel.GetTracerProvider kullanılır
+func WithTracerProvider(provider trace.TracerProvider) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// defaultTracer genel sağlayıcının izleyicisini döndürür
+func defaultTracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+// startRootSpanLocked örneğin kök span'ini açar ve iz bağlamını duruma yazar. Span
+// örneğin ilk yürütmesi bitene kadar açık kalır; sonraki yürütmeler saklanan
+// bağlamla aynı ize eklenir. Çağıran kilidi tutmalıdır.
+func (r *WorkflowRuntime) startRootSpanLocked(ctx context.Context) {
+	ctx, span := r.engine.tracer.Start(ctx, "workflow "+r.definition.ID,
+		trace.WithAttributes(attrWorkflowID.String(r.definition.ID), attrInstanceID.String(r.id)))
+	carrier := propagation.MapCarrier{}
+	traceContextPropagator.Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		r.state.TraceContext = carrier
+	}
+	r.span = span
+}
+
+// startRunSpan yürütmenin span'ini açar. Örneğin bu süreçte açılmış kök span'i
+// varsa o kullanılır; yoksa saklanan iz bağlamının altında bir devam span'i
+// açılır ve yürütmeyi tetikleyen çağrının span'i bağlantı olarak eklenir.
+func (r *WorkflowRuntime) startRunSpan(ctx context.Context) (context.Context, trace.Span) {
+	r.mutex.Lock()
+	root := r.span
+	r.span = nil
+	carrier := propagation.MapCarrier(r.state.TraceContext)
+	r.mutex.Unlock()
+
+	if root != nil {
+		return trace.ContextWithSpan(ctx, root), root
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithAttributes(attrWorkflowID.String(r.definition.ID), attrInstanceID.String(r.id), attrResumed.Bool(true)),
+	}
+	parent := trace.SpanContextFromContext(traceContextPropagator.Extract(context.Background(), carrier))
+	if parent.IsValid() {
+		if caller := trace.SpanContextFromContext(ctx); caller.IsValid() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: caller}))
+		}
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+	return r.engine.tracer.Start(ctx, "workflow "+r.definition.ID, opts...)
+}
+
+// endRunSpan yürütmenin span'ini örneğin son durumuyla kapatır
+func (r *WorkflowRuntime) endRunSpan(span trace.Span, err error) {
+	r.mutex.RLock()
+	status := r.state.Status
+	if err == nil && status == StatusFailed {
+		err = r.state.Error
+	}
+	r.mutex.RUnlock()
+
+	span.SetAttributes(attrWorkflowStatus.String(string(status)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startStepSpan adım denemesinin span'ini açar; dönen bağlam adım fonksiyonuna iletilir
+func (r *WorkflowRuntime) startStepSpan(ctx context.Context, step *StepDefinition, attempt int) (context.Context, trace.Span) {
+	return r.engine.tracer.Start(ctx, "step "+step.ID, trace.WithAttributes(
+		attrWorkflowID.String(r.definition.ID),
+		attrInstanceID.String(r.id),
+		attrStepID.String(step.ID),
+		attrStepType.String(string(step.Type)),
+		attrStepAttempt.Int(attempt),
+	))
+}
+
+// endStepSpan deneme span'ini sonucuna göre kapatır
+func endStepSpan(span trace.Span, result interface{}, err error) {
+	switch {
+	case err != nil:
+		span.SetAttributes(attrStepStatus.String("failed"))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case result == Pending:
+		span.SetAttributes(attrStepStatus.String("pending"))
+	default:
+		span.SetAttributes(attrStepStatus.String("completed"))
+	}
+	span.End()
+}
@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTracedEngine span'leri bellekteki bir dışa aktarıcıya yazan bir motor oluşturur
+func newTracedEngine(opts ...EngineOption) (*WorkflowEngine, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return NewWorkflowEngine(append(opts, WithTracerProvider(provider))...), exporter
+}
+
+// spanAttr span'in özniteliğini döndürür
+func spanAttr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingCreatesWorkflowAndStepSpans(t *testing.T) {
+	ctx := context.Background()
+	engine, exporter := newTracedEngine()
+
+	var stepSpans []trace.SpanContext
+	attempts := 0
+	engine.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		stepSpans = append(stepSpans, trace.SpanContextFromContext(ctx))
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	definition := singleStepDefinition("order", NewStepDefinition("charge", "Charge", StepTypeTask).WithRetryPolicy(2, 0, 0, 1))
+	if err := engine.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := engine.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected root and two attempt spans, got %d", len(spans))
+	}
+	failed, succeeded, root := spans[0], spans[1], spans[2]
+
+	if root.Name != "workflow order" || root.Parent.IsValid() {
+		t.Errorf("Expected a root workflow span, got %q with parent %v", root.Name, root.Parent)
+	}
+	if spanAttr(root, attrInstanceID).AsString() != runtime.ID() || spanAttr(root, attrWorkflowStatus).AsString() != "completed" {
+		t.Errorf("Unexpected root attributes: %v", root.Attributes)
+	}
+
+	for i, span := range []tracetest.SpanStub{failed, succeeded} {
+		if span.Name != "step charge" || span.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Errorf("Attempt span should be a child of the root, got %q under %v", span.Name, span.Parent.SpanID())
+		}
+		if spanAttr(span, attrStepAttempt).AsInt64() != int64(i+1) || spanAttr(span, attrStepType).AsString() != string(StepTypeTask) {
+			t.Errorf("Unexpected attempt attributes: %v", span.Attributes)
+		}
+		// Adım fonksiyonu kendi denemesinin span'ini bağlamında görür
+		if stepSpans[i].SpanID() != span.SpanContext.SpanID() {
+			t.Errorf("Step context should carry the attempt span")
+		}
+	}
+	if failed.Status.Code != codes.Error || len(failed.Events) == 0 || spanAttr(failed, attrStepStatus).AsString() != "failed" {
+		t.Errorf("Failed attempt should record the error, got %+v", failed.Status)
+	}
+	if succeeded.Status.Code == codes.Error || spanAttr(succeeded, attrStepStatus).AsString() != "completed" {
+		t.Errorf("Successful attempt should not be an error, got %+v", succeeded.Status)
+	}
+
+	// İz bağlamı örnekle birlikte saklanır
+	if runtime.GetState().TraceContext["traceparent"] == "" {
+		t.Error("Expected the trace context in the instance state")
+	}
+}
+
+func TestTracingResumedWorkflowContinuesTrace(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	definition := newPaymentDefinition(map[string]interface{}{SignalConfigName: "payment_received"})
+
+	first, firstExporter := newTracedEngine(WithStore(store))
+	registerPaymentSteps(first, make(chan interface{}, 1))
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime, err := first.StartWorkflow(ctx, "payment", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	// Örnek sinyal beklerken kök span kapanır
+	var root tracetest.SpanStub
+	for _, span := range firstExporter.GetSpans() {
+		if span.Name == "workflow payment" {
+			root = span
+		}
+	}
+	if !root.SpanContext.IsValid() || spanAttr(root, attrWorkflowStatus).AsString() != "waiting" {
+		t.Fatalf("Expected an ended root span for the waiting instance, got %+v", root)
+	}
+
+	// Yeniden başlayan süreç sinyali başka bir isteğin span'i içinde alır
+	second, secondExporter := newTracedEngine(WithStore(store))
+	registerPaymentSteps(second, make(chan interface{}, 1))
+	requestCtx, request := second.tracer.Start(ctx, "POST /signals")
+	if err := second.Signal(requestCtx, runtime.ID(), "payment_received", nil); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	request.End()
+
+	var resumed, ship tracetest.SpanStub
+	for _, span := range secondExporter.GetSpans() {
+		switch span.Name {
+		case "workflow payment":
+			resumed = span
+		case "step ship":
+			ship = span
+		}
+	}
+	if resumed.SpanContext.TraceID() != root.SpanContext.TraceID() || resumed.Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Errorf("Resumed run should continue the instance trace under the root span")
+	}
+	if !spanAttr(resumed, attrResumed).AsBool() || spanAttr(resumed, attrWorkflowStatus).AsString() != "completed" {
+		t.Errorf("Unexpected resumed span attributes: %v", resumed.Attributes)
+	}
+	if len(resumed.Links) != 1 || resumed.Links[0].SpanContext.SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("Resumed span should link to the triggering request, got %v", resumed.Links)
+	}
+	if ship.Parent.SpanID() != resumed.SpanContext.SpanID() || ship.SpanContext.TraceID() != root.SpanContext.TraceID() {
+		t.Errorf("Steps after the resume should join the same trace")
+	}
+}
@@ -3,15 +3,62 @@ package engine
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WorkflowEngine iş akışı motorunun ana yapısı
 type WorkflowEngine struct {
-	steps     map[string]StepFunc
-	mutex     sync.RWMutex
-	observers []ObserverFunc
+	steps             map[string]StepFunc
+	mutex             sync.RWMutex
+	observers         []ObserverFunc
+	definitions       map[string]map[int]*WorkflowDefinition
+	runtimes          map[string]*WorkflowRuntime
+	firing            map[string]bool
+	queries           map[string]map[string]QueryFunc
+	store             WorkflowStore
+	clock             Clock
+	timerPollInterval time.Duration
+
+	// startMutex aynı idempotency anahtarıyla gelen eşzamanlı başlatmaları sıraya koyar
+	startMutex        sync.Mutex
+	idempotencyWindow time.Duration
+
+	// Kiralama açıksa örnekler leaseOwner adına kiralanarak yürütülür
+	leaseOwner        string
+	leaseDuration     time.Duration
+	claimPollInterval time.Duration
+
+	// workers eşzamanlılık sınırı yapılandırılmışsa adım fonksiyonlarını sınırlar
+	workers *workerPool
+
+	// rateLimits kayıtta verilen adım sınırlarını, buckets kaynak kovalarını tutar
+	rateLimits map[string]*RateLimit
+	buckets    map[string]*tokenBucket
+
+	// tasks uzak adımların görev kuyruklarıdır; remoteSteps uzak adımların
+	// kuyruklarını tutar
+	tasks       *taskQueues
+	remoteSteps map[string]string
+
+	// completionKey askıya alınan adımların tamamlama jetonlarını imzalar
+	completionKey []byte
+
+	// middlewares adım çağrılarını sarmalayan ara katmanlardır
+	middlewares []StepMiddleware
+
+	// pendingEvents gözlemcilere henüz iletilmemiş olay sayısıdır
+	pendingEvents atomic.Int64
+
+	// tracer örnek ve adım span'lerini oluşturur
+	tracer trace.Tracer
+
+	// logger tanılama kayıtlarının yazıldığı logger'dır; nil ise kayıt yazılmaz
+	logger *slog.Logger
 }
 
 // StepFunc bir iş akışı adımını temsil eden fonksiyon tipi
@@ -22,10 +69,15 @@ type ObserverFunc func(event Event)
 
 // Event iş akışındaki olayları temsil eder
 type Event struct {
-	Type      EventType
-	StepID    string
-	Data      interface{}
-	Timestamp time.Time
+	Type       EventType   `json:"type"`
+	InstanceID string      `json:"instance_id,omitempty"`
+	WorkflowID string      `json:"workflow_id,omitempty"`
+	StepID     string      `json:"step_id,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+
+	// Sequence olayın EventLog'daki sırasıdır; günlüğe yazılmamış olaylarda sıfırdır
+	Sequence int64 `json:"seq,omitempty"`
 }
 
 // EventType olay tiplerini temsil eder
@@ -35,21 +87,124 @@ const (
 	EventStepStarted  EventType = "step_started"
 	EventStepComplete EventType = "step_completed"
 	EventStepFailed   EventType = "step_failed"
+	EventStepRetried  EventType = "step_retried"
+
+	EventStepSuspended EventType = "step_suspended"
+
+	EventTimerScheduled EventType = "timer_scheduled"
+	EventTimerFired     EventType = "timer_fired"
+	EventTimerFailed    EventType = "timer_failed"
+
+	EventSignalReceived EventType = "signal_received"
+	EventSignalTimeout  EventType = "signal_timeout"
+
+	EventLoopIteration EventType = "loop_iteration"
+
+	EventClaimFailed EventType = "claim_failed"
+
+	EventStepThrottled EventType = "step_throttled"
+	EventStepHeartbeat EventType = "step_heartbeat"
 )
 
+// EngineOption motorun yapılandırma seçeneğini temsil eder
+type EngineOption func(*WorkflowEngine)
+
+// WithStore motorun örnekleri ve zamanlayıcıları saklayacağı depoyu belirler
+func WithStore(store WorkflowStore) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.store = store
+	}
+}
+
+// WithClock motorun zaman kaynağını belirler; testlerde ManualClock ile kullanılır
+func WithClock(clock Clock) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.clock = clock
+	}
+}
+
+// WithTimerPollInterval zamanlayıcı servisinin depoyu yoklama aralığını belirler
+func WithTimerPollInterval(interval time.Duration) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.timerPollInterval = interval
+	}
+}
+
+// WithIdempotencyWindow idempotency anahtarının aynı örneğe yönlendirildiği süreyi
+// belirler; daha eski örneklerin anahtarları yok sayılır. 0 süresiz demektir.
+func WithIdempotencyWindow(window time.Duration) EngineOption {
+	return func(e *WorkflowEngine) {
+		e.idempotencyWindow = window
+	}
+}
+
 // NewWorkflowEngine yeni bir iş akışı motoru oluşturur
-func NewWorkflowEngine() *WorkflowEngine {
-	return &WorkflowEngine{
-		steps:     make(map[string]StepFunc),
-		observers: make([]ObserverFunc, 0),
+func NewWorkflowEngine(opts ...EngineOption) *WorkflowEngine {
+	e := &WorkflowEngine{
+		steps:             make(map[string]StepFunc),
+		observers:         make([]ObserverFunc, 0),
+		definitions:       make(map[string]map[int]*WorkflowDefinition),
+		runtimes:          make(map[string]*WorkflowRuntime),
+		firing:            make(map[string]bool),
+		queries:           make(map[string]map[string]QueryFunc),
+		rateLimits:        make(map[string]*RateLimit),
+		buckets:           make(map[string]*tokenBucket),
+		tasks:             newTaskQueues(),
+		remoteSteps:       make(map[string]string),
+		store:             NewMemoryStore(),
+		clock:             realClock{},
+		timerPollInterval: DefaultTimerPollInterval,
+		idempotencyWindow: DefaultIdempotencyWindow,
+		claimPollInterval: DefaultClaimPollInterval,
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	e.tasks.clock = e.clock
+	if len(e.completionKey) == 0 {
+		e.completionKey = newCompletionKey()
+	}
+	if e.tracer == nil {
+		e.tracer = defaultTracer()
+	}
+	return e
+}
+
+// Store motorun kullandığı depoyu döndürür
+func (e *WorkflowEngine) Store() WorkflowStore {
+	return e.store
+}
+
+// Clock motorun zaman kaynağını döndürür
+func (e *WorkflowEngine) Clock() Clock {
+	return e.clock
 }
 
 // RegisterStep yeni bir adım kaydeder
-func (e *WorkflowEngine) RegisterStep(id string, step StepFunc) {
+func (e *WorkflowEngine) RegisterStep(id string, step StepFunc, opts ...StepOption) {
+	e.registerStep(id, step, "", opts)
+}
+
+// registerStep adımı kaydeder; queue boş değilse adım o kuyruğa bağlı uzak adımdır
+func (e *WorkflowEngine) registerStep(id string, step StepFunc, queue string, opts []StepOption) {
+	var options stepOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	e.steps[id] = step
+	if options.rateLimit != nil {
+		e.rateLimits[id] = options.rateLimit
+	} else {
+		delete(e.rateLimits, id)
+	}
+	if queue != "" {
+		e.remoteSteps[id] = queue
+	} else {
+		delete(e.remoteSteps, id)
+	}
 }
 
 // AddObserver yeni bir gözlemci ekler
@@ -59,49 +214,93 @@ func (e *WorkflowEngine) AddObserver(observer ObserverFunc) {
 	e.observers = append(e.observers, observer)
 }
 
-// notifyObservers tüm gözlemcilere olay bildirir
-func (e *WorkflowEngine) notifyObservers(event Event) {
-	for _, observer := range e.observers {
-		observer(event)
+// notifyObservers tüm gözlemcilere olayları sırayla bildirir
+func (e *WorkflowEngine) notifyObservers(events ...Event) {
+	e.pendingEvents.Add(int64(len(events)))
+	for _, event := range events {
+		for _, observer := range e.observers {
+			observer(event)
+		}
+		e.pendingEvents.Add(-1)
 	}
 }
 
+// PendingEvents bildirilmiş ama tüm gözlemcilere henüz iletilmemiş olay
+// sayısını döndürür. Gözlemciler olayı üreten goroutine'de sırayla çağrıldığından
+// yavaş bir gözlemci bu sayının ve adım sürelerinin artmasına yol açar.
+func (e *WorkflowEngine) PendingEvents() int {
+	return int(e.pendingEvents.Load())
+}
+
 // ExecuteStep belirli bir adımı çalıştırır
 func (e *WorkflowEngine) ExecuteStep(ctx context.Context, stepID string, data interface{}) (interface{}, error) {
 	e.mutex.RLock()
 	step, exists := e.steps[stepID]
+	limit := e.rateLimits[stepID]
+	var handler StepHandler
+	if exists {
+		handler = e.stepHandlerLocked(step)
+	}
 	e.mutex.RUnlock()
 
 	if !exists {
 		return nil, fmt.Errorf("adım bulunamadı: %s", stepID)
 	}
 
+	// Örnek içinde çalışan adımın olayları örneğin kimliğini taşır
+	info, _ := StepInfoFromContext(ctx)
+
+	// Hız sınırına takılan adım havuzda yer tutmadan bekler
+	if err := e.throttle(ctx, stepID, limit); err != nil {
+		return nil, err
+	}
+
+	// Havuz yapılandırılmışsa adım yer açılana kadar kuyrukta bekler
+	if e.workers != nil {
+		release, err := e.workers.acquire(ctx, stepID, info.Priority)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// Kalp atışı süresi adım fonksiyonu başlarken işlemeye başlar
+	if watch := heartbeatWatchFrom(ctx); watch != nil {
+		watch.start()
+	}
+
 	// Adım başlangıç olayını bildir
 	e.notifyObservers(Event{
-		Type:      EventStepStarted,
-		StepID:    stepID,
-		Data:      data,
-		Timestamp: time.Now(),
+		Type:       EventStepStarted,
+		InstanceID: info.InstanceID,
+		WorkflowID: info.WorkflowID,
+		StepID:     stepID,
+		Data:       data,
+		Timestamp:  time.Now(),
 	})
 
-	result, err := step(ctx, data)
+	result, err := handler(ctx, stepID, data)
 	if err != nil {
 		// Hata olayını bildir
 		e.notifyObservers(Event{
-			Type:      EventStepFailed,
-			StepID:    stepID,
-			Data:      err,
-			Timestamp: time.Now(),
+			Type:       EventStepFailed,
+			InstanceID: info.InstanceID,
+			WorkflowID: info.WorkflowID,
+			StepID:     stepID,
+			Data:       err,
+			Timestamp:  time.Now(),
 		})
 		return nil, err
 	}
 
 	// Başarılı tamamlanma olayını bildir
 	e.notifyObservers(Event{
-		Type:      EventStepComplete,
-		StepID:    stepID,
-		Data:      result,
-		Timestamp: time.Now(),
+		Type:       EventStepComplete,
+		InstanceID: info.InstanceID,
+		WorkflowID: info.WorkflowID,
+		StepID:     stepID,
+		Data:       result,
+		Timestamp:  time.Now(),
 	})
 
 	return result, nil
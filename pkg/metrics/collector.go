@@ -0,0 +1,239 @@
+// Package metrics motorun ölçümlerini Prometheus biçiminde sunar. Collector
+// adım çağrılarını bir ara katmanla, yeniden denemeleri ve zamanlayıcıları
+// gözlemciyle sayar; örnek, havuz ve gözlemci durumlarını her toplamada
+// motordan okur:
+//
+//	collector := metrics.NewCollector(wfEngine)
+//	mux.Handle("/metrics", collector.Handler())
+//
+// Collector bir prometheus.Collector'dır; mevcut bir kayıt defterine
+// prometheus.MustRegister(collector) ile de eklenebilir.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultScrapeTimeout örnek sayılarını depodan okumak için verilen varsayılan süredir
+const DefaultScrapeTimeout = 5 * time.Second
+
+// Collector motorun Prometheus ölçümlerini toplar
+type Collector struct {
+	engine        *engine.WorkflowEngine
+	registry      *prometheus.Registry
+	namespace     string
+	buckets       []float64
+	scrapeTimeout time.Duration
+
+	executions *prometheus.CounterVec
+	failures   *prometheus.CounterVec
+	retries    *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	timerLag   prometheus.Histogram
+
+	instances      *prometheus.Desc
+	observerQueue  *prometheus.Desc
+	poolRunning    *prometheus.Desc
+	poolQueued     *prometheus.Desc
+	poolLimit      *prometheus.Desc
+	poolSaturation *prometheus.Desc
+	poolWait       *prometheus.Desc
+	stepRunning    *prometheus.Desc
+	stepQueued     *prometheus.Desc
+}
+
+// Option Collector'ın yapılandırma seçeneğini temsil eder
+type Option func(*Collector)
+
+// WithNamespace ölçüm adlarının önekini belirler; varsayılan "maestro"dur
+func WithNamespace(namespace string) Option {
+	return func(c *Collector) {
+		c.namespace = namespace
+	}
+}
+
+// WithDurationBuckets adım süresi ve zamanlayıcı gecikmesi histogramlarının
+// saniye cinsinden sınırlarını belirler; varsayılan prometheus.DefBuckets'tır
+func WithDurationBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.buckets = buckets
+	}
+}
+
+// WithScrapeTimeout örnek sayılarını depodan okumak için verilecek süreyi belirler
+func WithScrapeTimeout(timeout time.Duration) Option {
+	return func(c *Collector) {
+		c.scrapeTimeout = timeout
+	}
+}
+
+// NewCollector motora bir adım ara katmanı ve bir gözlemci ekleyerek ölçüm
+// toplamaya başlar. Collector kendi kayıt defterine kayıtlıdır; Handler bu
+// defteri sunar.
+func NewCollector(e *engine.WorkflowEngine, opts ...Option) *Collector {
+	c := &Collector{
+		engine:        e,
+		registry:      prometheus.NewRegistry(),
+		namespace:     "maestro",
+		buckets:       prometheus.DefBuckets,
+		scrapeTimeout: DefaultScrapeTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.executions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "step_executions_total",
+		Help:      "Çalıştırılan adım fonksiyonu sayısı.",
+	}, []string{"step_id"})
+	c.failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "step_failures_total",
+		Help:      "Hatayla dönen adım fonksiyonu sayısı.",
+	}, []string{"step_id"})
+	c.retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "step_retries_total",
+		Help:      "Yeniden deneme politikasıyla tekrarlanan adım denemesi sayısı.",
+	}, []string{"step_id"})
+	c.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Adım fonksiyonlarının çalışma süresi; havuz ve hız sınırı beklemeleri dahil değildir.",
+		Buckets:   c.buckets,
+	}, []string{"step_id"})
+	c.timerLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "timer_lag_seconds",
+		Help:      "Zamanlayıcıların uyanma zamanından sonra tetiklenene kadar geçen süre.",
+		Buckets:   c.buckets,
+	})
+
+	c.instances = c.desc("workflow_instances", "Depodaki iş akışı örneklerinin iş akışı ve duruma göre sayısı.", "workflow_id", "status")
+	c.observerQueue = c.desc("observer_queue_depth", "Bildirilmiş ama tüm gözlemcilere henüz iletilmemiş olay sayısı.")
+	c.poolRunning = c.desc("worker_pool_running", "Havuzda çalışan adım fonksiyonu sayısı.")
+	c.poolQueued = c.desc("worker_pool_queued", "Havuzda yer bekleyen adım sayısı.")
+	c.poolLimit = c.desc("worker_pool_max_concurrency", "Havuzun genel eşzamanlılık sınırı; 0 sınırsız demektir.")
+	c.poolSaturation = c.desc("worker_pool_saturation", "Çalışan adımların genel sınıra oranı; sınır yoksa yayınlanmaz.")
+	c.poolWait = c.desc("worker_pool_wait_seconds_total", "Adımların havuzda yer beklerken geçirdiği toplam süre.")
+	c.stepRunning = c.desc("step_pool_running", "Adımın havuzda çalışan kopya sayısı.", "step_id")
+	c.stepQueued = c.desc("step_pool_queued", "Adımın havuzda yer bekleyen kopya sayısı.", "step_id")
+
+	c.registry.MustRegister(c)
+	e.AddStepMiddleware(c.middleware)
+	e.AddObserver(c.observe)
+	return c
+}
+
+// Handler kayıt defterini Prometheus metin biçiminde sunan bir http.Handler döndürür
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+}
+
+// Describe prometheus.Collector arayüzünü uygular
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.executions.Describe(ch)
+	c.failures.Describe(ch)
+	c.retries.Describe(ch)
+	c.duration.Describe(ch)
+	c.timerLag.Describe(ch)
+	for _, desc := range []*prometheus.Desc{
+		c.instances, c.observerQueue,
+		c.poolRunning, c.poolQueued, c.poolLimit, c.poolSaturation, c.poolWait,
+		c.stepRunning, c.stepQueued,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect prometheus.Collector arayüzünü uygular. Örnek sayıları her toplamada
+// depodan listelenir; depo okunamazsa yalnızca bu ölçüm hatalı işaretlenir.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.executions.Collect(ch)
+	c.failures.Collect(ch)
+	c.retries.Collect(ch)
+	c.duration.Collect(ch)
+	c.timerLag.Collect(ch)
+
+	c.collectInstances(ch)
+	ch <- prometheus.MustNewConstMetric(c.observerQueue, prometheus.GaugeValue, float64(c.engine.PendingEvents()))
+
+	stats := c.engine.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.poolRunning, prometheus.GaugeValue, float64(stats.Running))
+	ch <- prometheus.MustNewConstMetric(c.poolQueued, prometheus.GaugeValue, float64(stats.Queued))
+	ch <- prometheus.MustNewConstMetric(c.poolLimit, prometheus.GaugeValue, float64(stats.MaxConcurrency))
+	if stats.MaxConcurrency > 0 {
+		ch <- prometheus.MustNewConstMetric(c.poolSaturation, prometheus.GaugeValue, float64(stats.Running)/float64(stats.MaxConcurrency))
+	}
+	ch <- prometheus.MustNewConstMetric(c.poolWait, prometheus.CounterValue, stats.TotalWait.Seconds())
+	for stepID, step := range stats.Steps {
+		ch <- prometheus.MustNewConstMetric(c.stepRunning, prometheus.GaugeValue, float64(step.Running), stepID)
+		ch <- prometheus.MustNewConstMetric(c.stepQueued, prometheus.GaugeValue, float64(step.Queued), stepID)
+	}
+}
+
+// collectInstances depodaki örnekleri iş akışı ve duruma göre sayar
+func (c *Collector) collectInstances(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.scrapeTimeout)
+	defer cancel()
+	records, err := c.engine.Store().ListInstances(ctx, engine.InstanceFilter{})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.instances, err)
+		return
+	}
+
+	type key struct {
+		workflowID string
+		status     engine.WorkflowStatus
+	}
+	counts := make(map[key]int)
+	for _, record := range records {
+		counts[key{record.WorkflowID, record.State.Status}]++
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.instances, prometheus.GaugeValue, float64(count), k.workflowID, string(k.status))
+	}
+}
+
+// middleware adım fonksiyonlarının çalışma sayısını, hatalarını ve süresini ölçer
+func (c *Collector) middleware(next engine.StepHandler) engine.StepHandler {
+	return func(ctx context.Context, stepID string, data interface{}) (interface{}, error) {
+		clock := c.engine.Clock()
+		start := clock.Now()
+		result, err := next(ctx, stepID, data)
+		c.duration.WithLabelValues(stepID).Observe(clock.Now().Sub(start).Seconds())
+		c.executions.WithLabelValues(stepID).Inc()
+		if err != nil {
+			c.failures.WithLabelValues(stepID).Inc()
+		}
+		return result, err
+	}
+}
+
+// observe yeniden denemeleri ve zamanlayıcı gecikmelerini kaydeder
+func (c *Collector) observe(event engine.Event) {
+	switch event.Type {
+	case engine.EventStepRetried:
+		c.retries.WithLabelValues(event.StepID).Inc()
+	case engine.EventTimerFired:
+		if wakeAt, ok := event.Data.(time.Time); ok {
+			lag := event.Timestamp.Sub(wakeAt)
+			if lag < 0 {
+				lag = 0
+			}
+			c.timerLag.Observe(lag.Seconds())
+		}
+	}
+}
+
+// desc ad alanı önekli bir ölçüm tanımı oluşturur
+func (c *Collector) desc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(c.namespace, "", name), help, labels, nil)
+}
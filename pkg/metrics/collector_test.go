@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// scrape Handler'ın metin çıktısını döndürür
+func scrape(t *testing.T, c *Collector) string {
+	t.Helper()
+	server := httptest.NewServer(c.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	return string(body)
+}
+
+func TestCollectorExportsEngineMetrics(t *testing.T) {
+	ctx := context.Background()
+	clock := engine.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e := engine.NewWorkflowEngine(engine.WithClock(clock), engine.WithMaxConcurrency(4))
+	collector := NewCollector(e)
+
+	// İlk deneme başarısız olur; her deneme iki saniye sürer
+	attempts := 0
+	e.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		attempts++
+		clock.Advance(2 * time.Second)
+		if attempts == 1 {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	e.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+
+	definition := engine.NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", engine.StepTypeTask).
+		WithRetryPolicy(2, 0, 0, 1).
+		WithNextSteps("cool-off"))
+	definition.AddStep(engine.NewStepDefinition("cool-off", "Cool off", engine.StepTypeTimer).
+		WithConfig(map[string]interface{}{engine.TimerConfigDuration: "1m"}).
+		WithNextSteps("receipt"))
+	definition.AddStep(engine.NewStepDefinition("receipt", "Receipt", engine.StepTypeTask))
+	if err := e.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	runtime, err := e.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if !strings.Contains(scrape(t, collector), `maestro_workflow_instances{status="waiting",workflow_id="order"} 1`) {
+		t.Error("Expected the waiting instance to be counted")
+	}
+
+	// Zamanlayıcı uyanma zamanından 30 saniye sonra tetiklenir
+	clock.Advance(90 * time.Second)
+	if _, err := e.FireDueTimers(ctx); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+	if status := runtime.GetState().Status; status != engine.StatusCompleted {
+		t.Fatalf("Expected completed status, got %s", status)
+	}
+
+	output := scrape(t, collector)
+	for _, line := range []string{
+		`maestro_step_executions_total{step_id="charge"} 2`,
+		`maestro_step_executions_total{step_id="receipt"} 1`,
+		`maestro_step_failures_total{step_id="charge"} 1`,
+		`maestro_step_retries_total{step_id="charge"} 1`,
+		`maestro_step_duration_seconds_sum{step_id="charge"} 4`,
+		`maestro_step_duration_seconds_count{step_id="charge"} 2`,
+		`maestro_timer_lag_seconds_sum 30`,
+		`maestro_workflow_instances{status="completed",workflow_id="order"} 1`,
+		`maestro_observer_queue_depth 0`,
+		`maestro_worker_pool_max_concurrency 4`,
+		`maestro_worker_pool_saturation 0`,
+		`maestro_step_pool_running{step_id="charge"} 0`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected %q in output:\n%s", line, output)
+		}
+	}
+}
+
+func TestCollectorWithoutPoolOmitsSaturation(t *testing.T) {
+	e := engine.NewWorkflowEngine()
+	collector := NewCollector(e, WithNamespace("jobs"))
+
+	output := scrape(t, collector)
+	if !strings.Contains(output, "jobs_worker_pool_max_concurrency 0\n") {
+		t.Errorf("Expected namespaced pool limit, got:\n%s", output)
+	}
+	if strings.Contains(output, "jobs_worker_pool_saturation") {
+		t.Error("Saturation should not be reported without a concurrency limit")
+	}
+}
@@ -0,0 +1,180 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/remote/workerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer görev kuyruklarını workerpb.WorkerService olarak sunar. İşçiler
+// Poll akışında adım kimlikleriyle kaydolur; henüz kayıtlı olmayan adımlar
+// adım kimliğiyle aynı adlı kuyruğa bağlanır, RegisterRemoteStep ile kaydedilmiş
+// adımlar kendi kuyruklarında kalır. Yerel bir fonksiyona kayıtlı adımı
+// üstlenmek isteyen işçi FailedPrecondition alır.
+type GRPCServer struct {
+	workerpb.UnimplementedWorkerServiceServer
+	engine *engine.WorkflowEngine
+}
+
+// NewGRPCServer motorun görev kuyruklarını sunan bir GRPCServer oluşturur;
+// workerpb.RegisterWorkerServiceServer ile bir grpc.Server'a eklenir
+func NewGRPCServer(e *engine.WorkflowEngine) *GRPCServer {
+	return &GRPCServer{engine: e}
+}
+
+// Poll işçiyi kaydeder ve verdiği kredi kadar görevi akışa yazar
+func (s *GRPCServer) Poll(stream workerpb.WorkerService_PollServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if len(first.StepIds) == 0 {
+		return status.Error(codes.InvalidArgument, "step_ids zorunludur")
+	}
+	for _, stepID := range first.StepIds {
+		if err := s.engine.EnsureRemoteStep(stepID, stepID); err != nil {
+			return grpcError(err)
+		}
+	}
+
+	// Sonraki mesajlar yalnızca kredi taşır; akış kapanınca yoklama da biter
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	grants := make(chan int32, 1)
+	go func() {
+		defer cancel()
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case grants <- req.Credits:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	credits := first.Credits
+	for {
+		for credits <= 0 {
+			select {
+			case granted := <-grants:
+				credits += granted
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		task, err := s.engine.PollTaskForSteps(ctx, first.StepIds, first.WorkerId)
+		if err != nil {
+			return grpcError(err)
+		}
+		if task == nil {
+			return nil
+		}
+		msg, err := taskMessage(task)
+		if err != nil {
+			// Kodlanamayan girdi işçiye ulaşamaz; deneme hatayla sonlanır
+			s.engine.FailTask(task.ID, err.Error())
+			continue
+		}
+		// Gönderilemeyen görev kiralaması dolunca yeniden denenir
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+		credits--
+	}
+}
+
+// Heartbeat görevin kiralamasını uzatır ve varsa ilerleme ayrıntılarını iletir
+func (s *GRPCServer) Heartbeat(ctx context.Context, req *workerpb.HeartbeatRequest) (*workerpb.HeartbeatResponse, error) {
+	var (
+		expires time.Time
+		err     error
+	)
+	if len(req.Details) > 0 {
+		var details interface{}
+		if err := json.Unmarshal(req.Details, &details); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "geçersiz ayrıntılar: %v", err)
+		}
+		expires, err = s.engine.ReportTaskProgress(req.TaskId, details)
+	} else {
+		expires, err = s.engine.HeartbeatTask(req.TaskId)
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &workerpb.HeartbeatResponse{LeaseExpiresAt: timestamppb.New(expires)}, nil
+}
+
+// Complete görevi işçinin sonucuyla tamamlar
+func (s *GRPCServer) Complete(ctx context.Context, req *workerpb.CompleteRequest) (*workerpb.CompleteResponse, error) {
+	var result interface{}
+	if len(req.Result) > 0 {
+		if err := json.Unmarshal(req.Result, &result); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "geçersiz sonuç: %v", err)
+		}
+	}
+	if err := s.engine.CompleteTask(req.TaskId, result); err != nil {
+		return nil, grpcError(err)
+	}
+	return &workerpb.CompleteResponse{}, nil
+}
+
+// Fail görevi işçinin bildirdiği hatayla sonlandırır
+func (s *GRPCServer) Fail(ctx context.Context, req *workerpb.FailRequest) (*workerpb.FailResponse, error) {
+	if req.Error == "" {
+		return nil, status.Error(codes.InvalidArgument, "error zorunludur")
+	}
+	if err := s.engine.FailTask(req.TaskId, req.Error); err != nil {
+		return nil, grpcError(err)
+	}
+	return &workerpb.FailResponse{}, nil
+}
+
+// taskMessage görevi protokol mesajına çevirir
+func taskMessage(task *engine.Task) (*workerpb.Task, error) {
+	input, err := json.Marshal(task.Input)
+	if err != nil {
+		return nil, err
+	}
+	var details []byte
+	if task.HeartbeatDetails != nil {
+		if details, err = json.Marshal(task.HeartbeatDetails); err != nil {
+			return nil, err
+		}
+	}
+	return &workerpb.Task{
+		Id:               task.ID,
+		Queue:            task.Queue,
+		InstanceId:       task.InstanceID,
+		WorkflowId:       task.WorkflowID,
+		StepId:           task.StepID,
+		Attempt:          int32(task.Attempt),
+		IdempotencyToken: task.IdempotencyToken,
+		Input:            input,
+		LeaseExpiresAt:   timestamppb.New(task.LeaseExpiresAt),
+		HeartbeatDetails: details,
+	}, nil
+}
+
+// grpcError motor hatasını gRPC durum koduna çevirir
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, engine.ErrTaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, engine.ErrStepRegistered):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
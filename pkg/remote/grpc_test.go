@@ -0,0 +1,251 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/remote/workerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newBufconnClient motoru bellek içi bir gRPC sunucusunda sunar ve ona bağlı bir
+// istemci bağlantısı döndürür
+func newBufconnClient(t *testing.T, e *engine.WorkflowEngine) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	workerpb.RegisterWorkerServiceServer(server, NewGRPCServer(e))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// runWorker işçiyi arka planda çalıştırır; test bitince durdurur
+func runWorker(t *testing.T, worker *Worker) <-chan error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return done
+}
+
+// waitForRemoteStep adım uzak bir adım olarak kaydedilene kadar bekler
+func waitForRemoteStep(t *testing.T, e *engine.WorkflowEngine, stepID string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := e.RemoteQueue(stepID); ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Step %s was never registered by the worker", stepID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGoWorkerRunsRegisteredSteps(t *testing.T) {
+	ctx := context.Background()
+	e := engine.NewWorkflowEngine()
+	e.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+	definition := engine.NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", engine.StepTypeTask).WithNextSteps("receipt"))
+	definition.AddStep(engine.NewStepDefinition("receipt", "Receipt", engine.StepTypeTask))
+	if err := e.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	// İşçideki adım fonksiyonu RegisterStep'e verilecek fonksiyonla aynıdır
+	var seen engine.StepInfo
+	worker := NewWorker(newBufconnClient(t, e), "go-worker")
+	worker.Handle("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		seen, _ = engine.StepInfoFromContext(ctx)
+		input := data.(map[string]interface{})
+		return map[string]interface{}{"charged": input["amount"]}, nil
+	})
+	runWorker(t, worker)
+	waitForRemoteStep(t, e, "charge")
+
+	runtime, err := e.StartWorkflow(ctx, "order", map[string]interface{}{"amount": 42})
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	state := runtime.GetState()
+	if state.Status != engine.StatusCompleted {
+		t.Fatalf("Expected completed status, got %s (%v)", state.Status, state.Error)
+	}
+	if charge, ok := state.StepResults["charge"].(map[string]interface{}); !ok || charge["charged"] != float64(42) {
+		t.Errorf("Expected worker result, got %#v", state.StepResults["charge"])
+	}
+	if seen.InstanceID != runtime.ID() || seen.StepID != "charge" || seen.IdempotencyToken == "" {
+		t.Errorf("Worker step should receive the attempt info, got %+v", seen)
+	}
+}
+
+func TestGoWorkerFailureIsRetried(t *testing.T) {
+	ctx := context.Background()
+	e := engine.NewWorkflowEngine()
+	e.RegisterRemoteStep("charge", "payments")
+	definition := engine.NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", engine.StepTypeTask).WithRetryPolicy(3, 0, 0, 1))
+	if err := e.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	var attempts atomic.Int32
+	worker := NewWorker(newBufconnClient(t, e), "go-worker")
+	worker.Handle("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		if attempts.Add(1) < 3 {
+			return nil, errors.New("gateway unavailable")
+		}
+		return "charged", nil
+	})
+	runWorker(t, worker)
+
+	runtime, err := e.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != engine.StatusCompleted || state.StepResults["charge"] != "charged" {
+		t.Errorf("Expected completed after retries, got %s %v", state.Status, state.StepResults)
+	}
+	if queue, _ := e.RemoteQueue("charge"); queue != "payments" {
+		t.Errorf("Worker should keep the registered queue, got %q", queue)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestGoWorkerConcurrencyLimitsInFlightTasks(t *testing.T) {
+	e := engine.NewWorkflowEngine()
+	var running, peak atomic.Int32
+	worker := NewWorker(newBufconnClient(t, e), "go-worker", WithWorkerConcurrency(2))
+	worker.Handle("resize", func(ctx context.Context, data interface{}) (interface{}, error) {
+		current := running.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return data, nil
+	})
+	runWorker(t, worker)
+	waitForRemoteStep(t, e, "resize")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := e.ExecuteStep(context.Background(), "resize", float64(i))
+			if err != nil || result != float64(i) {
+				t.Errorf("ExecuteStep returned %v, %v", result, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// İşçi kredisi kadar görev alır; fazlası motorun kuyruğunda bekler
+	if peak.Load() != 2 {
+		t.Errorf("Expected at most 2 tasks in flight, got %d", peak.Load())
+	}
+}
+
+func TestGoWorkerResumesFromHeartbeatDetails(t *testing.T) {
+	ctx := context.Background()
+	e := engine.NewWorkflowEngine()
+	definition := engine.NewWorkflowDefinition("transcode", "Transcode", "")
+	definition.AddStep(engine.NewStepDefinition("transcode", "Transcode", engine.StepTypeTask).WithRetryPolicy(2, 0, 0, 1))
+	if err := e.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	// İlk deneme ilerleme bildirdikten sonra düşer; ikinci deneme kaldığı yerden sürer
+	var resumed interface{}
+	worker := NewWorker(newBufconnClient(t, e), "go-worker")
+	worker.Handle("transcode", func(ctx context.Context, data interface{}) (interface{}, error) {
+		info, _ := engine.StepInfoFromContext(ctx)
+		if info.Attempt == 1 {
+			engine.Heartbeat(ctx, map[string]interface{}{"segment": 7})
+			return nil, errors.New("encoder crashed")
+		}
+		resumed = info.HeartbeatDetails
+		return "transcoded", nil
+	})
+	runWorker(t, worker)
+	waitForRemoteStep(t, e, "transcode")
+
+	runtime, err := e.StartWorkflow(ctx, "transcode", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if state := runtime.GetState(); state.Status != engine.StatusCompleted {
+		t.Fatalf("Expected completed status, got %s (%v)", state.Status, state.Error)
+	}
+	if details, ok := resumed.(map[string]interface{}); !ok || details["segment"] != float64(7) {
+		t.Errorf("Second attempt should receive the last heartbeat details, got %#v", resumed)
+	}
+}
+
+func TestWorkerCannotClaimLocalStep(t *testing.T) {
+	e := engine.NewWorkflowEngine()
+	e.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	worker := NewWorker(newBufconnClient(t, e), "go-worker")
+	worker.Handle("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := worker.Run(ctx); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestGRPCUnknownTask(t *testing.T) {
+	client := workerpb.NewWorkerServiceClient(newBufconnClient(t, engine.NewWorkflowEngine()))
+	ctx := context.Background()
+
+	if _, err := client.Heartbeat(ctx, &workerpb.HeartbeatRequest{TaskId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for heartbeat, got %v", err)
+	}
+	if _, err := client.Complete(ctx, &workerpb.CompleteRequest{TaskId: "missing", Result: []byte(`1`)}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for complete, got %v", err)
+	}
+	if _, err := client.Fail(ctx, &workerpb.FailRequest{TaskId: "missing"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for fail without error, got %v", err)
+	}
+}
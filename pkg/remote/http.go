@@ -0,0 +1,250 @@
+// Package remote uzak adımların görev kuyruklarını motorun dışındaki işçilere
+// açar. Handler HTTP/JSON üzerinden uzun yoklama (long polling) ile çalışır:
+//
+//	POST /tasks/poll             {"queue": "...", "worker_id": "..."}  -> 200 görev, 204 görev yok
+//	POST /tasks/<id>/heartbeat   {"details": ...} (isteğe bağlı)        -> 200 {"lease_expires_at": ...}
+//	POST /tasks/<id>/complete    {"result": ...}                        -> 204
+//	POST /tasks/<id>/fail        {"error": "..."}                       -> 204
+//
+// Ayrıntı taşıyan kalp atışı adımın engine.Heartbeat çağrısı gibi işlenir ve
+// HeartbeatTimeout süresini yeniler; gövdesiz kalp atışı yalnızca kiralamayı
+// uzatır. Hatalar {"error": "..."} gövdesiyle döner; bilinmeyen, tamamlanmış veya
+// kiralaması dolmuş görevler 404 alır. Kiralama, zaman aşımı ve yeniden deneme
+// motor tarafından uygulanır.
+//
+// Aynı kuyruklar GRPCServer ile workerpb.WorkerService olarak da sunulur; Go
+// işçileri Worker ile adım fonksiyonlarını bu protokol üzerinden çalıştırır.
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// DefaultPollTimeout boş kuyrukta yoklamanın 204 ile dönmeden önce beklediği
+// varsayılan süredir
+const DefaultPollTimeout = 30 * time.Second
+
+// Handler görev kuyruklarının HTTP arayüzüdür
+type Handler struct {
+	engine      *engine.WorkflowEngine
+	pollTimeout time.Duration
+}
+
+// Option Handler'ın yapılandırma seçeneğini temsil eder
+type Option func(*Handler)
+
+// WithPollTimeout boş kuyrukta yoklamanın en fazla ne kadar bekleyeceğini belirler
+func WithPollTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.pollTimeout = timeout
+	}
+}
+
+// NewHandler motorun görev kuyruklarını sunan bir Handler oluşturur. Handler
+// bir önek altında sunulacaksa http.StripPrefix ile sarılmalıdır.
+func NewHandler(e *engine.WorkflowEngine, opts ...Option) *Handler {
+	h := &Handler{
+		engine:      e,
+		pollTimeout: DefaultPollTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// pollRequest yoklama isteğinin gövdesidir
+type pollRequest struct {
+	Queue    string `json:"queue"`
+	WorkerID string `json:"worker_id"`
+}
+
+// heartbeatRequest kalp atışı isteğinin gövdesidir; Details verilmişse adımın
+// ilerlemesi olarak iletilir
+type heartbeatRequest struct {
+	Details json.RawMessage `json:"details"`
+}
+
+// heartbeatResponse kalp atışı yanıtının gövdesidir
+type heartbeatResponse struct {
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+}
+
+// completeRequest tamamlama isteğinin gövdesidir
+type completeRequest struct {
+	Result interface{} `json:"result"`
+}
+
+// failRequest hata bildirme isteğinin gövdesidir
+type failRequest struct {
+	Error string `json:"error"`
+}
+
+// errorResponse hata yanıtlarının gövdesidir
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP isteği yoluna göre yönlendirir
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "tasks" && parts[1] == "poll":
+		if allowPost(w, r) {
+			h.poll(w, r)
+		}
+	case len(parts) == 3 && parts[0] == "tasks" && parts[1] != "":
+		taskID := parts[1]
+		switch parts[2] {
+		case "heartbeat":
+			if allowPost(w, r) {
+				h.heartbeat(w, r, taskID)
+			}
+		case "complete":
+			if allowPost(w, r) {
+				h.complete(w, r, taskID)
+			}
+		case "fail":
+			if allowPost(w, r) {
+				h.fail(w, r, taskID)
+			}
+		default:
+			writeError(w, http.StatusNotFound, "yol bulunamadı: "+r.URL.Path)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "yol bulunamadı: "+r.URL.Path)
+	}
+}
+
+// poll kuyruktan bir görev kiralar; süre içinde görev gelmezse 204 döner
+func (h *Handler) poll(w http.ResponseWriter, r *http.Request) {
+	var req pollRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Queue == "" {
+		writeError(w, http.StatusBadRequest, "queue zorunludur")
+		return
+	}
+
+	ctx := r.Context()
+	if h.pollTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.pollTimeout)
+		defer cancel()
+	}
+	task, err := h.engine.PollTask(ctx, req.Queue, req.WorkerID)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	if task == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// heartbeat görevin kiralamasını uzatır ve varsa ilerleme ayrıntılarını iletir
+func (h *Handler) heartbeat(w http.ResponseWriter, r *http.Request, taskID string) {
+	var req heartbeatRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	var (
+		expires time.Time
+		err     error
+	)
+	if len(req.Details) > 0 {
+		var details interface{}
+		if err := json.Unmarshal(req.Details, &details); err != nil {
+			writeError(w, http.StatusBadRequest, "geçersiz ayrıntılar: "+err.Error())
+			return
+		}
+		expires, err = h.engine.ReportTaskProgress(taskID, details)
+	} else {
+		expires, err = h.engine.HeartbeatTask(taskID)
+	}
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, heartbeatResponse{LeaseExpiresAt: expires})
+}
+
+// complete görevi işçinin sonucuyla tamamlar
+func (h *Handler) complete(w http.ResponseWriter, r *http.Request, taskID string) {
+	var req completeRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if err := h.engine.CompleteTask(taskID, req.Result); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fail görevi işçinin bildirdiği hatayla sonlandırır
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, taskID string) {
+	var req failRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Error == "" {
+		writeError(w, http.StatusBadRequest, "error zorunludur")
+		return
+	}
+	if err := h.engine.FailTask(taskID, req.Error); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowPost yalnızca POST isteklerine izin verir
+func allowPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodPost {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodPost)
+	writeError(w, http.StatusMethodNotAllowed, "yöntem desteklenmiyor: "+r.Method)
+	return false
+}
+
+// decode isteğin JSON gövdesini çözer; gövde boşsa v değiştirilmez
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "geçersiz istek gövdesi: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeEngineError motor hatasını HTTP durum koduna çevirir
+func writeEngineError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, engine.ErrTaskNotFound) {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
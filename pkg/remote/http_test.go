@@ -0,0 +1,173 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// newTestServer charge adımını payments kuyruğuna bağlayan bir motoru sunar
+func newTestServer(t *testing.T, opts ...Option) (*engine.WorkflowEngine, *httptest.Server) {
+	t.Helper()
+	e := engine.NewWorkflowEngine()
+	e.RegisterRemoteStep("charge", "payments")
+	e.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+
+	definition := engine.NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", engine.StepTypeTask).
+		WithRetryPolicy(2, 0, 0, 1).
+		WithNextSteps("receipt"))
+	definition.AddStep(engine.NewStepDefinition("receipt", "Receipt", engine.StepTypeTask))
+	if err := e.RegisterDefinition(context.Background(), definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(e, opts...))
+	t.Cleanup(server.Close)
+	return e, server
+}
+
+// post gövdeyi JSON olarak gönderir ve yanıtı out'a çözer
+func post(t *testing.T, url string, body, out interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestWorkerCompletesWorkflowOverHTTP(t *testing.T) {
+	e, server := newTestServer(t)
+
+	done := make(chan *engine.WorkflowRuntime, 1)
+	go func() {
+		runtime, err := e.StartWorkflow(context.Background(), "order", map[string]interface{}{"amount": 42})
+		if err != nil {
+			t.Errorf("StartWorkflow failed: %v", err)
+		}
+		done <- runtime
+	}()
+
+	poll := map[string]string{"queue": "payments", "worker_id": "py-worker"}
+
+	// İlk deneme işçi tarafından başarısız bildirilir ve yeniden denenir
+	var first engine.Task
+	if status := post(t, server.URL+"/tasks/poll", poll, &first); status != http.StatusOK {
+		t.Fatalf("Expected 200 from poll, got %d", status)
+	}
+	if first.StepID != "charge" || first.Worker != "py-worker" {
+		t.Errorf("Unexpected task: %+v", first)
+	}
+	if input, ok := first.Input.(map[string]interface{}); !ok || input["amount"] != float64(42) {
+		t.Errorf("Expected instance input in task, got %#v", first.Input)
+	}
+	if status := post(t, server.URL+"/tasks/"+first.ID+"/fail", map[string]string{"error": "card declined"}, nil); status != http.StatusNoContent {
+		t.Fatalf("Expected 204 from fail, got %d", status)
+	}
+
+	var second engine.Task
+	if status := post(t, server.URL+"/tasks/poll", poll, &second); status != http.StatusOK {
+		t.Fatalf("Expected 200 from poll, got %d", status)
+	}
+	if second.ID == first.ID || second.Attempt != first.Attempt+1 {
+		t.Errorf("Expected a retry task, got %+v after %+v", second, first)
+	}
+
+	var heartbeat heartbeatResponse
+	progress := map[string]interface{}{"details": map[string]int{"percent": 50}}
+	if status := post(t, server.URL+"/tasks/"+second.ID+"/heartbeat", progress, &heartbeat); status != http.StatusOK {
+		t.Fatalf("Expected 200 from heartbeat, got %d", status)
+	}
+	if heartbeat.LeaseExpiresAt.Before(second.LeaseExpiresAt) {
+		t.Errorf("Heartbeat should extend the lease, got %s before %s", heartbeat.LeaseExpiresAt, second.LeaseExpiresAt)
+	}
+
+	result := map[string]interface{}{"result": map[string]string{"charge_id": "ch_1"}}
+	if status := post(t, server.URL+"/tasks/"+second.ID+"/complete", result, nil); status != http.StatusNoContent {
+		t.Fatalf("Expected 204 from complete, got %d", status)
+	}
+
+	runtime := <-done
+	state := runtime.GetState()
+	if state.Status != engine.StatusCompleted {
+		t.Fatalf("Expected completed status, got %s (%v)", state.Status, state.Error)
+	}
+	if charge, ok := state.StepResults["charge"].(map[string]interface{}); !ok || charge["charge_id"] != "ch_1" {
+		t.Errorf("Expected worker result in step results, got %#v", state.StepResults["charge"])
+	}
+}
+
+func TestPollTimesOutWithNoContent(t *testing.T) {
+	_, server := newTestServer(t, WithPollTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	status := post(t, server.URL+"/tasks/poll", map[string]string{"queue": "payments"}, nil)
+	if status != http.StatusNoContent {
+		t.Errorf("Expected 204 for empty queue, got %d", status)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Poll should wait for work, returned after %s", elapsed)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	_, server := newTestServer(t, WithPollTimeout(time.Millisecond))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown task", http.MethodPost, "/tasks/missing/complete", `{"result": 1}`, http.StatusNotFound},
+		{"unknown heartbeat", http.MethodPost, "/tasks/missing/heartbeat", ``, http.StatusNotFound},
+		{"unknown action", http.MethodPost, "/tasks/missing/cancel", ``, http.StatusNotFound},
+		{"unknown path", http.MethodPost, "/workers", ``, http.StatusNotFound},
+		{"wrong method", http.MethodGet, "/tasks/poll", ``, http.StatusMethodNotAllowed},
+		{"bad json", http.MethodPost, "/tasks/poll", `{"queue":`, http.StatusBadRequest},
+		{"missing queue", http.MethodPost, "/tasks/poll", `{}`, http.StatusBadRequest},
+		{"missing error", http.MethodPost, "/tasks/missing/fail", `{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatalf("NewRequest failed: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, resp.StatusCode)
+			}
+
+			// Hata yanıtları her zaman JSON gövdesi taşır
+			var body errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+				t.Errorf("Expected JSON error body, got %v (%v)", body, err)
+			}
+		})
+	}
+}
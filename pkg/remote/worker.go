@@ -0,0 +1,244 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/remote/workerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// minHeartbeatInterval kalp atışlarının en sık gönderilme aralığıdır
+const minHeartbeatInterval = 100 * time.Millisecond
+
+// Worker adım fonksiyonlarını gRPC üzerinden uzak bir motor için çalıştıran Go
+// işçisidir. Handle ile kaydedilen fonksiyonlar RegisterStep'e verilenlerle
+// aynı imzayı taşır; adım yerel veya uzak çalışsa da değişmeden kullanılır.
+type Worker struct {
+	client      workerpb.WorkerServiceClient
+	id          string
+	concurrency int
+	steps       map[string]engine.StepFunc
+}
+
+// WorkerOption Worker'ın yapılandırma seçeneğini temsil eder
+type WorkerOption func(*Worker)
+
+// WithWorkerConcurrency işçinin aynı anda çalıştırabileceği görev sayısını
+// belirler; varsayılan 1'dir
+func WithWorkerConcurrency(n int) WorkerOption {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// NewWorker conn üzerinden motora bağlanan ve kendini id ile tanıtan bir işçi
+// oluşturur
+func NewWorker(conn grpc.ClientConnInterface, id string, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		client:      workerpb.NewWorkerServiceClient(conn),
+		id:          id,
+		concurrency: 1,
+		steps:       make(map[string]engine.StepFunc),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Handle stepID adımını işçinin çalıştıracağı fonksiyona bağlar; Run'dan önce
+// çağrılmalıdır
+func (w *Worker) Handle(stepID string, step engine.StepFunc) {
+	w.steps[stepID] = step
+}
+
+// Run işçiyi kaydettiği adımlarla motora bağlar ve ctx bitene kadar görev
+// çalıştırır. ctx bittiğinde çalışan görevlerin bağlamları iptal edilir ve
+// hepsi bitince nil döner; bağlantı hataları ve kayıt hataları döndürülür.
+func (w *Worker) Run(ctx context.Context) error {
+	if len(w.steps) == 0 {
+		return errors.New("işçiye kayıtlı adım yok")
+	}
+	if w.concurrency < 1 {
+		return fmt.Errorf("işçi eşzamanlılığı pozitif olmalı: %d", w.concurrency)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := w.client.Poll(ctx)
+	if err != nil {
+		return err
+	}
+
+	stepIDs := make([]string, 0, len(w.steps))
+	for id := range w.steps {
+		stepIDs = append(stepIDs, id)
+	}
+	if err := stream.Send(&workerpb.PollRequest{WorkerId: w.id, StepIds: stepIDs, Credits: int32(w.concurrency)}); err != nil {
+		return err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		sendMutex sync.Mutex
+	)
+	defer wg.Wait()
+	for {
+		task, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.execute(ctx, task)
+
+			// Biten görevin yeri yeni bir kredi olarak bildirilir
+			sendMutex.Lock()
+			defer sendMutex.Unlock()
+			stream.Send(&workerpb.PollRequest{Credits: 1})
+		}()
+	}
+}
+
+// execute görevi kalp atışı göndererek çalıştırır ve sonucunu bildirir
+func (w *Worker) execute(ctx context.Context, task *workerpb.Task) {
+	// Sonuç, işçi kapanırken iptal edilen adımlar için de bildirilir; motor
+	// kiralamanın dolmasını beklemeden yeniden deneme politikasını uygular
+	report := context.WithoutCancel(ctx)
+	step, ok := w.steps[task.StepId]
+	if !ok {
+		w.client.Fail(report, &workerpb.FailRequest{TaskId: task.Id, Error: "işçide adım bulunamadı: " + task.StepId})
+		return
+	}
+
+	var input, previous interface{}
+	if len(task.Input) > 0 {
+		if err := json.Unmarshal(task.Input, &input); err != nil {
+			w.client.Fail(report, &workerpb.FailRequest{TaskId: task.Id, Error: "geçersiz girdi: " + err.Error()})
+			return
+		}
+	}
+	if len(task.HeartbeatDetails) > 0 {
+		if err := json.Unmarshal(task.HeartbeatDetails, &previous); err != nil {
+			w.client.Fail(report, &workerpb.FailRequest{TaskId: task.Id, Error: "geçersiz kalp atışı ayrıntıları: " + err.Error()})
+			return
+		}
+	}
+
+	// Adımın engine.Heartbeat çağrıları kalp atışı döngüsüyle motora iletilir
+	progress := &taskProgress{beats: make(chan struct{}, 1)}
+	stepCtx, cancel := context.WithCancel(engine.ContextWithStepInfo(ctx, engine.StepInfo{
+		InstanceID:       task.InstanceId,
+		WorkflowID:       task.WorkflowId,
+		StepID:           task.StepId,
+		Attempt:          int(task.Attempt),
+		IdempotencyToken: task.IdempotencyToken,
+		HeartbeatDetails: previous,
+	}))
+	defer cancel()
+	stepCtx = engine.ContextWithHeartbeat(stepCtx, progress.record)
+	heartbeatDone := make(chan struct{})
+	heartbeatStopped := make(chan struct{})
+	go func() {
+		defer close(heartbeatStopped)
+		w.heartbeat(stepCtx, cancel, task, progress, heartbeatDone)
+	}()
+
+	result, err := step(stepCtx, input)
+
+	// Son ilerleme sonuçtan önce iletilir; sonraki deneme bu ayrıntıları alır
+	close(heartbeatDone)
+	<-heartbeatStopped
+	if details := progress.take(); details != nil {
+		w.client.Heartbeat(report, &workerpb.HeartbeatRequest{TaskId: task.Id, Details: details})
+	}
+	if err != nil {
+		w.client.Fail(report, &workerpb.FailRequest{TaskId: task.Id, Error: err.Error()})
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		w.client.Fail(report, &workerpb.FailRequest{TaskId: task.Id, Error: "sonuç kodlanamadı: " + err.Error()})
+		return
+	}
+	w.client.Complete(report, &workerpb.CompleteRequest{TaskId: task.Id, Result: data})
+}
+
+// taskProgress adımın henüz motora iletilmemiş son kalp atışını tutar
+type taskProgress struct {
+	mutex   sync.Mutex
+	details []byte
+	pending bool
+	beats   chan struct{}
+}
+
+// record adımın Heartbeat çağrısını kaydeder ve kalp atışı döngüsünü uyandırır
+func (p *taskProgress) record(details interface{}) {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return
+	}
+	p.mutex.Lock()
+	p.details = data
+	p.pending = true
+	p.mutex.Unlock()
+	select {
+	case p.beats <- struct{}{}:
+	default:
+	}
+}
+
+// take iletilmeyi bekleyen kalp atışını alır; yoksa nil döner
+func (p *taskProgress) take() []byte {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.pending {
+		return nil
+	}
+	p.pending = false
+	return p.details
+}
+
+// heartbeat kiralamanın üçte biri geçtikçe kiralamayı uzatır; adım Heartbeat
+// çağırdığında ayrıntıları beklemeden iletir. Motor görevi artık tanımıyorsa
+// (kiralama dolmuş veya adım iptal edilmişse) adımın bağlamı iptal edilir.
+func (w *Worker) heartbeat(ctx context.Context, cancel context.CancelFunc, task *workerpb.Task, progress *taskProgress, done <-chan struct{}) {
+	expires := task.LeaseExpiresAt.AsTime()
+	for {
+		interval := time.Until(expires) / 3
+		if interval < minHeartbeatInterval {
+			interval = minHeartbeatInterval
+		}
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-progress.beats:
+		case <-time.After(interval):
+		}
+
+		resp, err := w.client.Heartbeat(ctx, &workerpb.HeartbeatRequest{TaskId: task.Id, Details: progress.take()})
+		if status.Code(err) == codes.NotFound {
+			cancel()
+			return
+		}
+		if err == nil {
+			expires = resp.LeaseExpiresAt.AsTime()
+		}
+	}
+}
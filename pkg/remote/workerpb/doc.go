@@ -0,0 +1,5 @@
+// Package workerpb uzak adım işçileri protokolünün (worker.proto) üretilmiş
+// gRPC kodunu içerir.
+package workerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative worker.proto
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// routeDefinitions /definitions altındaki istekleri yönlendirir
+func (h *Handler) routeDefinitions(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 || (len(parts) == 1 && parts[0] == ""):
+		if !allow(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			h.listDefinitions(w, r)
+		} else {
+			h.createDefinition(w, r)
+		}
+	case len(parts) == 1:
+		if !allow(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			h.getDefinition(w, r, parts[0])
+		case http.MethodPut:
+			h.updateDefinition(w, r, parts[0])
+		default:
+			h.deleteDefinition(w, r, parts[0])
+		}
+	case len(parts) == 2 && parts[1] == "versions":
+		if allow(w, r, http.MethodGet) {
+			h.definitionVersions(w, r, parts[0])
+		}
+	default:
+		notFound(w, r)
+	}
+}
+
+// listDefinitions her tanımın en güncel sürümünü ID sırasıyla döndürür
+func (h *Handler) listDefinitions(w http.ResponseWriter, r *http.Request) {
+	all, err := h.engine.Store().ListDefinitions(r.Context())
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	// Depo tanımları ID ve sürüme göre sıralı döndürür; her ID'nin son sürümü alınır
+	latest := make([]*engine.WorkflowDefinition, 0, len(all))
+	for _, definition := range all {
+		if n := len(latest); n > 0 && latest[n-1].ID == definition.ID {
+			latest[n-1] = definition
+			continue
+		}
+		latest = append(latest, definition)
+	}
+	writeJSON(w, http.StatusOK, latest)
+}
+
+// definitionVersions tanımın tüm sürümlerini artan sırada döndürür
+func (h *Handler) definitionVersions(w http.ResponseWriter, r *http.Request, id string) {
+	all, err := h.engine.Store().ListDefinitions(r.Context())
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	versions := make([]*engine.WorkflowDefinition, 0)
+	for _, definition := range all {
+		if definition.ID == id {
+			versions = append(versions, definition)
+		}
+	}
+	if len(versions) == 0 {
+		writeError(w, http.StatusNotFound, "tanım bulunamadı: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+// getDefinition tanımın istenen veya en güncel sürümünü döndürür
+func (h *Handler) getDefinition(w http.ResponseWriter, r *http.Request, id string) {
+	version := 0
+	if raw := r.URL.Query().Get("version"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			writeError(w, http.StatusBadRequest, "geçersiz sürüm: "+raw)
+			return
+		}
+		version = v
+	}
+	definition, err := h.engine.Store().GetDefinition(r.Context(), id, version)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, definition)
+}
+
+// createDefinition yeni bir tanımı 1. sürüm olarak kaydeder; aynı ID ile
+// kayıtlı tanım varsa 409 döner
+func (h *Handler) createDefinition(w http.ResponseWriter, r *http.Request) {
+	var definition engine.WorkflowDefinition
+	if !decode(w, r, &definition) {
+		return
+	}
+	if definition.ID == "" {
+		writeError(w, http.StatusBadRequest, "id zorunludur")
+		return
+	}
+
+	h.definitionMutex.Lock()
+	defer h.definitionMutex.Unlock()
+
+	_, err := h.engine.Store().GetDefinition(r.Context(), definition.ID, 0)
+	if err == nil {
+		writeError(w, http.StatusConflict, "tanım zaten kayıtlı: "+definition.ID)
+		return
+	}
+	if !errors.Is(err, engine.ErrNotFound) {
+		writeEngineError(w, err)
+		return
+	}
+
+	now := h.engine.Clock().Now()
+	definition.Version = 1
+	definition.CreatedAt = now
+	definition.UpdatedAt = now
+	if !h.register(w, r, &definition) {
+		return
+	}
+	writeJSON(w, http.StatusCreated, &definition)
+}
+
+// updateDefinition tanımı bir sonraki sürüm olarak kaydeder. Kayıtlı sürümler
+// değiştirilmez; çalışan örnekler başladıkları sürümle devam eder.
+func (h *Handler) updateDefinition(w http.ResponseWriter, r *http.Request, id string) {
+	var definition engine.WorkflowDefinition
+	if !decode(w, r, &definition) {
+		return
+	}
+	if definition.ID != "" && definition.ID != id {
+		writeError(w, http.StatusBadRequest, "gövdedeki id yolla uyuşmuyor: "+definition.ID)
+		return
+	}
+
+	h.definitionMutex.Lock()
+	defer h.definitionMutex.Unlock()
+
+	latest, err := h.engine.Store().GetDefinition(r.Context(), id, 0)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+
+	definition.ID = id
+	definition.Version = latest.Version + 1
+	definition.CreatedAt = latest.CreatedAt
+	definition.UpdatedAt = h.engine.Clock().Now()
+	if !h.register(w, r, &definition) {
+		return
+	}
+	writeJSON(w, http.StatusOK, &definition)
+}
+
+// register tanımı doğrulayıp motora kaydeder
+func (h *Handler) register(w http.ResponseWriter, r *http.Request, definition *engine.WorkflowDefinition) bool {
+	if err := definition.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "geçersiz tanım: "+err.Error())
+		return false
+	}
+	if err := h.engine.RegisterDefinition(r.Context(), definition); err != nil {
+		writeEngineError(w, err)
+		return false
+	}
+	return true
+}
+
+// deleteDefinition tanımın tüm sürümlerini siler
+func (h *Handler) deleteDefinition(w http.ResponseWriter, r *http.Request, id string) {
+	h.definitionMutex.Lock()
+	defer h.definitionMutex.Unlock()
+
+	if err := h.engine.DeleteDefinition(r.Context(), id); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
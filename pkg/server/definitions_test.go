@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+func TestCreateAndUpdateDefinition(t *testing.T) {
+	_, server := newTestServer(t)
+
+	definition := engine.NewWorkflowDefinition("refund", "Refund", "")
+	definition.AddStep(engine.NewStepDefinition("ship", "Ship", engine.StepTypeTask))
+
+	var created engine.WorkflowDefinition
+	if status := do(t, http.MethodPost, server.URL+"/definitions", definition, &created); status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+	if created.Version != 1 || created.CreatedAt.IsZero() {
+		t.Errorf("Expected version 1 with creation time, got %+v", created)
+	}
+
+	// Aynı ID ile ikinci oluşturma reddedilir
+	if status := do(t, http.MethodPost, server.URL+"/definitions", definition, nil); status != http.StatusConflict {
+		t.Errorf("Expected 409 for duplicate definition, got %d", status)
+	}
+
+	definition.Name = "Refund v2"
+	var updated engine.WorkflowDefinition
+	if status := do(t, http.MethodPut, server.URL+"/definitions/refund", definition, &updated); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if updated.Version != 2 || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected version 2 keeping creation time, got %+v", updated)
+	}
+
+	var versions []engine.WorkflowDefinition
+	if status := do(t, http.MethodGet, server.URL+"/definitions/refund/versions", nil, &versions); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(versions) != 2 || versions[0].Name != "Refund" || versions[1].Name != "Refund v2" {
+		t.Errorf("Unexpected versions: %+v", versions)
+	}
+
+	var first engine.WorkflowDefinition
+	do(t, http.MethodGet, server.URL+"/definitions/refund?version=1", nil, &first)
+	if first.Version != 1 || first.Name != "Refund" {
+		t.Errorf("Expected version 1, got %+v", first)
+	}
+
+	// Liste her tanımın yalnızca son sürümünü içerir
+	var list []engine.WorkflowDefinition
+	do(t, http.MethodGet, server.URL+"/definitions", nil, &list)
+	if len(list) != 2 || list[0].ID != "order" || list[1].ID != "refund" || list[1].Version != 2 {
+		t.Errorf("Unexpected definition list: %+v", list)
+	}
+}
+
+func TestCreateInvalidDefinition(t *testing.T) {
+	_, server := newTestServer(t)
+
+	definition := engine.NewWorkflowDefinition("broken", "Broken", "")
+	definition.AddStep(engine.NewStepDefinition("a", "A", engine.StepTypeTask).WithNextSteps("missing"))
+	if status := do(t, http.MethodPost, server.URL+"/definitions", definition, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid definition, got %d", status)
+	}
+	if status := do(t, http.MethodPut, server.URL+"/definitions/missing", orderDefinition(), nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for mismatched id, got %d", status)
+	}
+	if status := do(t, http.MethodGet, server.URL+"/definitions/order?version=x", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid version, got %d", status)
+	}
+}
+
+func TestDeleteDefinition(t *testing.T) {
+	e, server := newTestServer(t)
+
+	runtime, err := e.StartWorkflow(context.Background(), "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+
+	// Bekleyen örneği olan tanım silinemez
+	if status := do(t, http.MethodDelete, server.URL+"/definitions/order", nil, nil); status != http.StatusConflict {
+		t.Errorf("Expected 409 while instance is waiting, got %d", status)
+	}
+
+	if err := e.Cancel(context.Background(), runtime.ID()); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if status := do(t, http.MethodDelete, server.URL+"/definitions/order", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", status)
+	}
+	if status := do(t, http.MethodGet, server.URL+"/definitions/order", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", status)
+	}
+	if status := do(t, http.MethodDelete, server.URL+"/definitions/order", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for second delete, got %d", status)
+	}
+}
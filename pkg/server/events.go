@@ -0,0 +1,353 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// DefaultKeepAlive olay gelmeyen bağlantılara yorum satırı gönderme aralığıdır;
+// aradaki vekil sunucuların boşta kalan bağlantıyı kapatmasını önler
+const DefaultKeepAlive = 15 * time.Second
+
+// DefaultSubscriberBuffer bir istemciye yazılmayı bekleyebilecek en fazla olay sayısıdır
+const DefaultSubscriberBuffer = 256
+
+// replayPageSize yeniden bağlanan istemciye günlükten tek seferde okunan olay sayısıdır
+const replayPageSize = 500
+
+// EventStream motorun gözlemci olaylarını Server-Sent Events olarak yayınlar:
+//
+//	GET /?workflow_id=order&instance_id=...&type=step_failed,step_retried
+//
+// Filtreler virgülle ayrılabilir veya tekrarlanabilir; verilmeyen filtre tüm
+// olaylara uyar. Her olay "event: <tip>" satırı ve JSON Event gövdesiyle yazılır.
+//
+// Depo engine.EventLog arayüzünü uyguluyorsa olaylar yayınlanmadan önce günlüğe
+// yazılır ve "id:" satırı olayın günlükteki sırasını taşır. Yeniden bağlanan
+// istemcinin Last-Event-ID başlığı (veya last_event_id sorgu parametresi)
+// verildiğinde o sıradan sonraki olaylar önce günlükten gönderilir, ardından
+// canlı akışa geçilir; bağlantı koptuğu sırada üretilen olaylar kaçırılmaz.
+// Aynı depoyu paylaşan diğer düğümlerin olayları yalnızca günlükten gelir.
+//
+// Olaylar tek bir goroutine'de sırayla günlüğe yazılıp yayınlanır. Olayları
+// yeterince hızlı okumayan istemcinin bağlantısı kapatılır; istemci
+// Last-Event-ID ile yeniden bağlanarak kaldığı yerden devam eder.
+type EventStream struct {
+	log       engine.EventLog
+	logger    *slog.Logger
+	keepAlive time.Duration
+	buffer    int
+
+	queue     chan engine.Event
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mutex       sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+// subscriber tek bir istemci bağlantısıdır; events kanalı bağlantı kapatılacaksa kapanır
+type subscriber struct {
+	events chan engine.Event
+}
+
+// StreamOption olay akışının yapılandırma seçeneğini temsil eder
+type StreamOption func(*EventStream)
+
+// WithKeepAlive boşta kalan bağlantılara yorum satırı gönderme aralığını belirler;
+// sıfır veya negatif değer kapatır
+func WithKeepAlive(interval time.Duration) StreamOption {
+	return func(s *EventStream) {
+		s.keepAlive = interval
+	}
+}
+
+// WithSubscriberBuffer bir istemci için bekletilebilecek en fazla olay sayısını belirler
+func WithSubscriberBuffer(size int) StreamOption {
+	return func(s *EventStream) {
+		if size > 0 {
+			s.buffer = size
+		}
+	}
+}
+
+// NewEventStream motora bir gözlemci ekleyerek olay akışını başlatır. Gözlemci
+// motordan kaldırılamadığından akış uygulama boyunca bir kez oluşturulmalı ve
+// kapanışta Close çağrılmalıdır.
+func NewEventStream(e *engine.WorkflowEngine, opts ...StreamOption) *EventStream {
+	s := &EventStream{
+		logger:      e.Logger(),
+		keepAlive:   DefaultKeepAlive,
+		buffer:      DefaultSubscriberBuffer,
+		queue:       make(chan engine.Event, DefaultSubscriberBuffer),
+		done:        make(chan struct{}),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	if log, ok := e.Store().(engine.EventLog); ok {
+		s.log = log
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	e.AddObserver(s.observe)
+	go s.dispatch()
+	return s
+}
+
+// Close akışı durdurur ve açık bağlantıları kapatır; sonraki olaylar yayınlanmaz
+func (s *EventStream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.closed = true
+		for sub := range s.subscribers {
+			close(sub.events)
+			delete(s.subscribers, sub)
+		}
+	})
+	return nil
+}
+
+// observe olayı yayın kuyruğuna ekler. Kuyruk doluysa olayı üreten goroutine
+// yer açılana kadar bekler; olaylar günlüğe üretildikleri sırayla yazılır.
+func (s *EventStream) observe(event engine.Event) {
+	// Hatalar JSON'da boş nesneye dönüşmesin diye metin olarak taşınır
+	if err, ok := event.Data.(error); ok {
+		event.Data = err.Error()
+	}
+	select {
+	case s.queue <- event:
+	case <-s.done:
+	}
+}
+
+// dispatch kuyruktaki olayları günlüğe yazar ve abonelere yayınlar
+func (s *EventStream) dispatch() {
+	for {
+		select {
+		case event := <-s.queue:
+			if s.log != nil {
+				seq, err := s.log.AppendEvent(context.Background(), event)
+				if err != nil {
+					// Olay yine yayınlanır ancak sırası olmadığından yeniden bağlanan istemciye gönderilemez
+					s.logger.Error("event log append failed", "event_type", string(event.Type), "error", err)
+				}
+				event.Sequence = seq
+			}
+			s.broadcast(event)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// broadcast olayı tüm abonelere iletir; tamponu dolu abonenin bağlantısı kapatılır
+func (s *EventStream) broadcast(event engine.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			close(sub.events)
+			delete(s.subscribers, sub)
+		}
+	}
+}
+
+// subscribe yeni bir abone kaydeder; akış kapatılmışsa nil döner
+func (s *EventStream) subscribe() *subscriber {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	sub := &subscriber{events: make(chan engine.Event, s.buffer)}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe aboneyi kaldırır; abone zaten kaldırılmışsa bir şey yapmaz
+func (s *EventStream) unsubscribe(sub *subscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		close(sub.events)
+		delete(s.subscribers, sub)
+	}
+}
+
+// eventFilter isteğin olay filtresidir; boş küme tüm değerlere uyar
+type eventFilter struct {
+	workflows map[string]bool
+	instances map[string]bool
+	types     map[string]bool
+}
+
+// match olayın filtreye uyup uymadığını döndürür
+func (f eventFilter) match(event engine.Event) bool {
+	return matchSet(f.workflows, event.WorkflowID) &&
+		matchSet(f.instances, event.InstanceID) &&
+		matchSet(f.types, string(event.Type))
+}
+
+func matchSet(set map[string]bool, value string) bool {
+	return len(set) == 0 || set[value]
+}
+
+// toSet değerleri kümeye çevirir
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// ServeHTTP olayları istemci bağlantıyı kapatana kadar akıtır
+func (s *EventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "yanıt akıtmayı desteklemiyor")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := eventFilter{
+		workflows: toSet(queryList(query, "workflow_id")),
+		instances: toSet(queryList(query, "instance_id")),
+		types:     toSet(queryList(query, "type")),
+	}
+
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = query.Get("last_event_id")
+	}
+	var last int64
+	if raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			writeError(w, http.StatusBadRequest, "geçersiz Last-Event-ID: "+raw)
+			return
+		}
+		last = value
+	}
+
+	// Abonelik günlük okunmadan önce açılır; aradaki olaylar ya günlükte ya da
+	// abonenin tamponunda bulunur, ikisinde birden bulunanlar sırayla elenir
+	sub := s.subscribe()
+	if sub == nil {
+		writeError(w, http.StatusServiceUnavailable, "olay akışı kapatıldı")
+		return
+	}
+	defer s.unsubscribe(sub)
+
+	var backlog []engine.Event
+	replay := raw != "" && s.log != nil
+	if replay {
+		events, err := s.log.LoadEvents(r.Context(), last, replayPageSize)
+		if err != nil {
+			writeEngineError(w, err)
+			return
+		}
+		backlog = events
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// send olayı filtreye uyuyorsa yazar; günlükten gönderilmiş olaylar atlanır
+	send := func(event engine.Event) error {
+		if event.Sequence != 0 {
+			if event.Sequence <= last {
+				return nil
+			}
+			last = event.Sequence
+		}
+		if !filter.match(event) {
+			return nil
+		}
+		return writeEvent(w, event)
+	}
+
+	for replay {
+		for _, event := range backlog {
+			if err := send(event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+		if len(backlog) < replayPageSize {
+			break
+		}
+		events, err := s.log.LoadEvents(r.Context(), last, replayPageSize)
+		if err != nil {
+			return
+		}
+		backlog = events
+	}
+
+	var keepAlive <-chan time.Time
+	if s.keepAlive > 0 {
+		ticker := time.NewTicker(s.keepAlive)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent olayı SSE biçiminde yazar; JSON'a çevrilemeyen veri metin olarak gönderilir
+func writeEvent(w io.Writer, event engine.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		event.Data = fmt.Sprint(event.Data)
+		if data, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	if event.Sequence != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.Sequence); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
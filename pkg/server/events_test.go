@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// sseEvent akıştan okunan tek bir olaydır
+type sseEvent struct {
+	id    string
+	name  string
+	event engine.Event
+}
+
+// newEventServer order tanımlı motoru ve olay akışını sunar
+func newEventServer(t *testing.T) (*engine.WorkflowEngine, *httptest.Server) {
+	t.Helper()
+	e, _ := newTestServer(t)
+	stream := NewEventStream(e)
+	t.Cleanup(func() { stream.Close() })
+	server := httptest.NewServer(stream)
+	t.Cleanup(server.Close)
+	return e, server
+}
+
+// connect akışa bağlanır; lastID boş değilse Last-Event-ID olarak gönderilir
+func connect(t *testing.T, url, lastID string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// next akıştaki bir sonraki olayı okur; yorum satırları atlanır
+func next(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var result sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Reading stream failed: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if result.name != "" {
+				return result
+			}
+		case strings.HasPrefix(line, "id: "):
+			result.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			result.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &result.event); err != nil {
+				t.Fatalf("Decoding event failed: %v", err)
+			}
+		}
+	}
+}
+
+// waitForEvents olay günlüğünde en az n olay olana kadar bekler
+func waitForEvents(t *testing.T, e *engine.WorkflowEngine, n int) []engine.Event {
+	t.Helper()
+	log := e.Store().(engine.EventLog)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		events, err := log.LoadEvents(context.Background(), 0, 0)
+		if err != nil {
+			t.Fatalf("LoadEvents failed: %v", err)
+		}
+		if len(events) >= n {
+			return events
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d logged events, got %d", n, len(events))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventStreamFiltersEvents(t *testing.T) {
+	e, server := newEventServer(t)
+	ctx := context.Background()
+
+	other := engine.NewWorkflowDefinition("refund", "Refund", "")
+	other.AddStep(engine.NewStepDefinition("prepare", "Prepare", engine.StepTypeTask))
+	if err := e.RegisterDefinition(ctx, other); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	reader := connect(t, server.URL+"?workflow_id=order&type=step_completed,signal_received", "")
+
+	// Diğer tanımın olayları filtreye takılır
+	if _, err := e.StartWorkflow(ctx, "refund", nil); err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	runtime, err := e.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	if err := e.Signal(ctx, runtime.ID(), "approval", map[string]interface{}{"approved": true}); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+
+	expected := []struct {
+		eventType engine.EventType
+		stepID    string
+	}{
+		{engine.EventStepComplete, "prepare"},
+		{engine.EventSignalReceived, "approval"},
+		{engine.EventStepComplete, "ship"},
+	}
+	for _, want := range expected {
+		got := next(t, reader)
+		if got.name != string(want.eventType) || got.event.Type != want.eventType ||
+			got.event.StepID != want.stepID || got.event.WorkflowID != "order" ||
+			got.event.InstanceID != runtime.ID() {
+			t.Fatalf("Expected %s for %q, got %+v", want.eventType, want.stepID, got)
+		}
+		if got.id == "" || got.id != strconv.FormatInt(got.event.Sequence, 10) {
+			t.Errorf("Expected id to carry the log sequence, got id %q seq %d", got.id, got.event.Sequence)
+		}
+	}
+}
+
+func TestEventStreamResumesFromLastEventID(t *testing.T) {
+	e, server := newEventServer(t)
+	ctx := context.Background()
+
+	runtime, err := e.StartWorkflow(ctx, "order", nil)
+	if err != nil {
+		t.Fatalf("StartWorkflow failed: %v", err)
+	}
+	logged := waitForEvents(t, e, 2)
+
+	// İstemci ilk olayı almış ve bağlantısı kopmuş kabul edilir; ikinci olay
+	// bağlantı yokken üretilmiştir
+	reader := connect(t, server.URL+"?instance_id="+runtime.ID(), strconv.FormatInt(logged[0].Sequence, 10))
+	replayed := next(t, reader)
+	if replayed.event.Sequence != logged[1].Sequence || replayed.event.Type != logged[1].Type {
+		t.Fatalf("Expected event %d from the log, got %+v", logged[1].Sequence, replayed)
+	}
+
+	// Günlükten sonra canlı olaylar tekrarsız devam eder
+	if err := e.Signal(ctx, runtime.ID(), "approval", nil); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	live := next(t, reader)
+	if live.event.Type != engine.EventSignalReceived || live.event.Sequence != logged[1].Sequence+1 {
+		t.Errorf("Expected signal_received right after the replay, got %+v", live)
+	}
+}
+
+func TestEventStreamRejectsInvalidLastEventID(t *testing.T) {
+	_, server := newEventServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestEventStreamCarriesErrorsAsText(t *testing.T) {
+	e, server := newEventServer(t)
+	e.RegisterStep("prepare", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, errors.New("card declined")
+	})
+
+	reader := connect(t, server.URL+"?type=step_failed", "")
+	e.StartWorkflow(context.Background(), "order", nil)
+
+	got := next(t, reader)
+	if got.event.Data != "card declined" {
+		t.Errorf("Expected error text as data, got %v", got.event.Data)
+	}
+}
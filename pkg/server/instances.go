@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// startRequest örnek başlatma isteğinin gövdesidir
+type startRequest struct {
+	WorkflowID     string                 `json:"workflow_id"`
+	Input          map[string]interface{} `json:"input"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+}
+
+// approvalRequest onay isteğinin gövdesidir
+type approvalRequest struct {
+	Approved *bool  `json:"approved"`
+	By       string `json:"by"`
+	Comment  string `json:"comment"`
+}
+
+// instanceList örnek listesi yanıtının gövdesidir. NextOffset sonraki sayfanın
+// başlangıcıdır; son sayfada boştur.
+type instanceList struct {
+	Instances  []*engine.InstanceRecord `json:"instances"`
+	Total      int                      `json:"total"`
+	NextOffset *int                     `json:"next_offset,omitempty"`
+}
+
+// routeInstances /instances altındaki istekleri yönlendirir
+func (h *Handler) routeInstances(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0:
+		if !allow(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			h.listInstances(w, r)
+		} else {
+			h.startInstance(w, r)
+		}
+	case len(parts) == 1:
+		if allow(w, r, http.MethodGet) {
+			h.getInstance(w, r, parts[0])
+		}
+	case len(parts) == 2 && parts[1] == "history":
+		if allow(w, r, http.MethodGet) {
+			h.history(w, r, parts[0])
+		}
+	case len(parts) == 2 && (parts[1] == "cancel" || parts[1] == "pause" || parts[1] == "resume" || parts[1] == "retry"):
+		if allow(w, r, http.MethodPost) {
+			h.control(w, r, parts[0], parts[1])
+		}
+	case len(parts) == 3 && parts[1] == "signals" && parts[2] != "":
+		if allow(w, r, http.MethodPost) {
+			h.signal(w, r, parts[0], parts[2])
+		}
+	case len(parts) == 3 && parts[1] == "approvals" && parts[2] != "":
+		if allow(w, r, http.MethodPost) {
+			h.approve(w, r, parts[0], parts[2])
+		}
+	default:
+		notFound(w, r)
+	}
+}
+
+// listInstances filtreye uyan örnekleri oluşturulma sırasıyla sayfalar.
+// workflow_id, idempotency_key ve status (virgülle ayrılmış veya tekrarlanan)
+// ile filtrelenir; limit ve offset sayfayı belirler.
+func (h *Handler) listInstances(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := engine.InstanceFilter{
+		WorkflowID:     query.Get("workflow_id"),
+		IdempotencyKey: query.Get("idempotency_key"),
+	}
+	for _, status := range queryList(query, "status") {
+		filter.Statuses = append(filter.Statuses, engine.WorkflowStatus(status))
+	}
+
+	limit, ok := queryInt(w, query.Get("limit"), "limit", DefaultPageSize)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > MaxPageSize {
+		writeError(w, http.StatusBadRequest, "limit 1 ile "+strconv.Itoa(MaxPageSize)+" arasında olmalıdır")
+		return
+	}
+	offset, ok := queryInt(w, query.Get("offset"), "offset", 0)
+	if !ok {
+		return
+	}
+
+	records, err := h.engine.Store().ListInstances(r.Context(), filter)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+
+	page := instanceList{Instances: make([]*engine.InstanceRecord, 0), Total: len(records)}
+	if offset < len(records) {
+		end := offset + limit
+		if end < len(records) {
+			page.NextOffset = &end
+		} else {
+			end = len(records)
+		}
+		page.Instances = records[offset:end]
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// queryList virgülle ayrılmış veya tekrarlanan sorgu parametresinin boş
+// olmayan değerlerini döndürür
+func queryList(query url.Values, name string) []string {
+	var values []string
+	for _, value := range query[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// queryInt negatif olmayan bir sorgu parametresini okur; boşsa def döner
+func queryInt(w http.ResponseWriter, raw, name string, def int) (int, bool) {
+	if raw == "" {
+		return def, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		writeError(w, http.StatusBadRequest, "geçersiz "+name+": "+raw)
+		return 0, false
+	}
+	return value, true
+}
+
+// startInstance tanımın en güncel sürümüyle bir örnek başlatır
+func (h *Handler) startInstance(w http.ResponseWriter, r *http.Request) {
+	var req startRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.WorkflowID == "" {
+		writeError(w, http.StatusBadRequest, "workflow_id zorunludur")
+		return
+	}
+
+	var opts []engine.StartOption
+	if req.IdempotencyKey != "" {
+		opts = append(opts, engine.WithIdempotencyKey(req.IdempotencyKey))
+	}
+	runtime, err := h.engine.StartWorkflow(detach(r), req.WorkflowID, req.Input, opts...)
+	if runtime == nil {
+		writeEngineError(w, err)
+		return
+	}
+	h.respond(w, r, http.StatusCreated, runtime.ID(), err)
+}
+
+// getInstance örneğin kaydını döndürür
+func (h *Handler) getInstance(w http.ResponseWriter, r *http.Request, id string) {
+	record, err := h.engine.Store().GetInstance(r.Context(), id)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+// history örneğin geçmişini sıralı döndürür
+func (h *Handler) history(w http.ResponseWriter, r *http.Request, id string) {
+	// Geçmişi olmayan örnek ile olmayan örnek ayırt edilir
+	if _, err := h.engine.Store().GetInstance(r.Context(), id); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	events, err := h.engine.History(r.Context(), id)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	if events == nil {
+		events = make([]engine.HistoryEvent, 0)
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// control örneği iptal eder, duraklatır, duraklatmasını kaldırır veya başarısız
+// örneği yeniden dener
+func (h *Handler) control(w http.ResponseWriter, r *http.Request, id, action string) {
+	ctx := detach(r)
+	var err error
+	switch action {
+	case "cancel":
+		err = h.engine.Cancel(ctx, id)
+	case "pause":
+		err = h.engine.Pause(ctx, id)
+	case "retry":
+		err = h.engine.Retry(ctx, id)
+	default:
+		err = h.engine.Unpause(ctx, id)
+	}
+	h.respond(w, r, http.StatusOK, id, err)
+}
+
+// signal isteğin gövdesini sinyalin verisi olarak teslim eder; boş gövde nil veridir
+func (h *Handler) signal(w http.ResponseWriter, r *http.Request, id, name string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "istek gövdesi okunamadı: "+err.Error())
+		return
+	}
+	var payload interface{}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, "geçersiz istek gövdesi: "+err.Error())
+			return
+		}
+	}
+	h.respond(w, r, http.StatusOK, id, h.engine.Signal(detach(r), id, name, payload))
+}
+
+// approve onay kararını sinyal olarak teslim eder. Sinyalin verisi
+// {"approved": ..., "by": ..., "comment": ...} biçimindedir; bekleyen sinyal
+// adımının sonucu olur ve koşullarda steps.<adım>.approved ile okunur.
+func (h *Handler) approve(w http.ResponseWriter, r *http.Request, id, name string) {
+	var req approvalRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Approved == nil {
+		writeError(w, http.StatusBadRequest, "approved zorunludur")
+		return
+	}
+	payload := map[string]interface{}{
+		"approved": *req.Approved,
+		"by":       req.By,
+		"comment":  req.Comment,
+	}
+	h.respond(w, r, http.StatusOK, id, h.engine.Signal(detach(r), id, name, payload))
+}
+
+// respond işlemden sonra örneğin güncel kaydını yazar. İşlem örneği ilerletirken
+// bir adım örneği başarısız kıldıysa işlem başarılı sayılır ve başarısız örneğin
+// kaydı döner.
+func (h *Handler) respond(w http.ResponseWriter, r *http.Request, status int, id string, err error) {
+	if err != nil && engineStatus(err) != http.StatusInternalServerError {
+		writeEngineError(w, err)
+		return
+	}
+	record, rerr := h.engine.Store().GetInstance(r.Context(), id)
+	if rerr != nil {
+		writeEngineError(w, errors.Join(err, rerr))
+		return
+	}
+	if err != nil && record.State.Status != engine.StatusFailed {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, status, record)
+}
+
+// detach istemci bağlantıyı kapatsa da örneğin yürütülmeye devam etmesi için
+// isteğin iptalinden bağımsız, değerlerini (iz bağlamı gibi) koruyan bir bağlam döndürür
+func detach(r *http.Request) context.Context {
+	return context.WithoutCancel(r.Context())
+}
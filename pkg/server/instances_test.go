@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// start order tanımıyla bir örnek başlatır; örnek onay bekler
+func start(t *testing.T, url string, body startRequest) *engine.InstanceRecord {
+	t.Helper()
+	var record engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/instances", body, &record); status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+	return &record
+}
+
+func TestStartAndApproveInstance(t *testing.T) {
+	_, server := newTestServer(t)
+
+	record := start(t, server.URL, startRequest{WorkflowID: "order", Input: map[string]interface{}{"amount": 42}})
+	if record.State.Status != engine.StatusWaiting || record.State.CurrentStepID != "approval" {
+		t.Fatalf("Expected instance waiting on approval, got %+v", record.State)
+	}
+
+	url := server.URL + "/instances/" + record.ID
+	if status := do(t, http.MethodPost, url+"/approvals/approval", map[string]interface{}{"by": "ayse"}, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 without approved, got %d", status)
+	}
+
+	var approved engine.InstanceRecord
+	body := map[string]interface{}{"approved": true, "by": "ayse", "comment": "ok"}
+	if status := do(t, http.MethodPost, url+"/approvals/approval", body, &approved); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if approved.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed instance, got %s", approved.State.Status)
+	}
+	// Onay kararı sinyal adımının sonucudur
+	result, _ := approved.State.StepResults["approval"].(map[string]interface{})
+	if result["approved"] != true || result["by"] != "ayse" || result["comment"] != "ok" {
+		t.Errorf("Unexpected approval result: %v", approved.State.StepResults["approval"])
+	}
+
+	var events []engine.HistoryEvent
+	if status := do(t, http.MethodGet, url+"/history", nil, &events); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(events) == 0 || events[0].Type != engine.HistoryWorkflowStarted ||
+		events[len(events)-1].Type != engine.HistoryWorkflowCompleted {
+		t.Errorf("Unexpected history: %+v", events)
+	}
+}
+
+func TestStartInstanceIsIdempotent(t *testing.T) {
+	_, server := newTestServer(t)
+
+	first := start(t, server.URL, startRequest{WorkflowID: "order", IdempotencyKey: "order-1"})
+	second := start(t, server.URL, startRequest{WorkflowID: "order", IdempotencyKey: "order-1"})
+	if first.ID != second.ID {
+		t.Errorf("Expected same instance for same key, got %s and %s", first.ID, second.ID)
+	}
+
+	if status := do(t, http.MethodPost, server.URL+"/instances", startRequest{}, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 without workflow_id, got %d", status)
+	}
+	if status := do(t, http.MethodPost, server.URL+"/instances", startRequest{WorkflowID: "missing"}, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown workflow, got %d", status)
+	}
+}
+
+func TestListInstancesFilterAndPagination(t *testing.T) {
+	_, server := newTestServer(t)
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		ids = append(ids, start(t, server.URL, startRequest{WorkflowID: "order"}).ID)
+	}
+	do(t, http.MethodPost, server.URL+"/instances/"+ids[0]+"/cancel", nil, nil)
+
+	var page instanceList
+	if status := do(t, http.MethodGet, server.URL+"/instances?limit=2", nil, &page); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if page.Total != 3 || len(page.Instances) != 2 || page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Fatalf("Unexpected first page: total=%d len=%d next=%v", page.Total, len(page.Instances), page.NextOffset)
+	}
+	if page.Instances[0].ID != ids[0] {
+		t.Errorf("Expected creation order, got %s first", page.Instances[0].ID)
+	}
+
+	var last instanceList
+	do(t, http.MethodGet, server.URL+"/instances?limit=2&offset="+strconv.Itoa(*page.NextOffset), nil, &last)
+	if len(last.Instances) != 1 || last.NextOffset != nil || last.Instances[0].ID != ids[2] {
+		t.Errorf("Unexpected last page: %+v", last)
+	}
+
+	var waiting instanceList
+	do(t, http.MethodGet, server.URL+"/instances?workflow_id=order&status=waiting,running", nil, &waiting)
+	if waiting.Total != 2 {
+		t.Errorf("Expected 2 waiting instances, got %d", waiting.Total)
+	}
+
+	var canceled instanceList
+	do(t, http.MethodGet, server.URL+"/instances?status=canceled&status=failed", nil, &canceled)
+	if canceled.Total != 1 || canceled.Instances[0].ID != ids[0] {
+		t.Errorf("Expected the canceled instance, got %+v", canceled)
+	}
+
+	for _, query := range []string{"limit=0", "limit=501", "offset=-1", "limit=x"} {
+		if status := do(t, http.MethodGet, server.URL+"/instances?"+query, nil, nil); status != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", query, status)
+		}
+	}
+}
+
+func TestPauseAndResumeInstance(t *testing.T) {
+	_, server := newTestServer(t)
+
+	record := start(t, server.URL, startRequest{WorkflowID: "order"})
+	url := server.URL + "/instances/" + record.ID
+
+	var paused engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/pause", nil, &paused); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !paused.State.Paused || paused.State.Status != engine.StatusWaiting {
+		t.Errorf("Expected waiting instance marked paused, got %+v", paused.State)
+	}
+
+	// Sinyal beklemeyi bitirir ama örnek duraklatıldığı için sonraki adıma geçmez
+	var signaled engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/signals/approval", map[string]interface{}{"approved": true}, &signaled); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if signaled.State.Status != engine.StatusPaused {
+		t.Errorf("Expected paused instance after signal, got %s", signaled.State.Status)
+	}
+
+	var resumed engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/resume", nil, &resumed); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if resumed.State.Status != engine.StatusCompleted || resumed.State.StepResults["ship"] != "shipped" {
+		t.Errorf("Expected completed instance after resume, got %+v", resumed.State)
+	}
+}
+
+func TestInstanceActionErrors(t *testing.T) {
+	_, server := newTestServer(t)
+
+	if status := do(t, http.MethodGet, server.URL+"/instances/missing", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown instance, got %d", status)
+	}
+	if status := do(t, http.MethodGet, server.URL+"/instances/missing/history", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown instance history, got %d", status)
+	}
+	if status := do(t, http.MethodPost, server.URL+"/instances/missing/cancel", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for canceling unknown instance, got %d", status)
+	}
+
+	record := start(t, server.URL, startRequest{WorkflowID: "order"})
+	url := server.URL + "/instances/" + record.ID
+
+	// Duraklatılmamış örneğin devam ettirilmesi hata değildir
+	var unchanged engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/resume", nil, &unchanged); status != http.StatusOK {
+		t.Errorf("Expected 200 for resuming unpaused instance, got %d", status)
+	}
+	if unchanged.State.Status != engine.StatusWaiting {
+		t.Errorf("Expected instance still waiting, got %s", unchanged.State.Status)
+	}
+
+	var canceled engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/cancel", nil, &canceled); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if canceled.State.Status != engine.StatusCanceled {
+		t.Errorf("Expected canceled instance, got %s", canceled.State.Status)
+	}
+	for _, action := range []string{"/cancel", "/pause", "/signals/approval"} {
+		if status := do(t, http.MethodPost, url+action, nil, nil); status != http.StatusConflict {
+			t.Errorf("Expected 409 for %s on canceled instance, got %d", action, status)
+		}
+	}
+}
+
+func TestRetryFailedInstance(t *testing.T) {
+	e, server := newTestServer(t)
+	failures := 1
+	e.RegisterStep("ship", func(ctx context.Context, data interface{}) (interface{}, error) {
+		if failures > 0 {
+			failures--
+			return nil, errors.New("carrier unavailable")
+		}
+		return "shipped", nil
+	})
+
+	record := start(t, server.URL, startRequest{WorkflowID: "order"})
+	url := server.URL + "/instances/" + record.ID
+
+	// Adım hatası isteği başarısız kılmaz; başarısız örneğin kaydı döner
+	var failed engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/signals/approval", nil, &failed); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if failed.State.Status != engine.StatusFailed || failed.State.CurrentStepID != "ship" {
+		t.Fatalf("Expected instance failed at ship, got %+v", failed.State)
+	}
+
+	var retried engine.InstanceRecord
+	if status := do(t, http.MethodPost, url+"/retry", nil, &retried); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if retried.State.Status != engine.StatusCompleted || retried.State.StepResults["ship"] != "shipped" {
+		t.Errorf("Expected completed instance after retry, got %+v", retried.State)
+	}
+
+	if status := do(t, http.MethodPost, url+"/retry", nil, nil); status != http.StatusConflict {
+		t.Errorf("Expected 409 for retrying a completed instance, got %d", status)
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPIDocument Handler'ın OpenAPI 3 belgesidir. Sunucu adresi belgeye göre
+// görelidir; Handler hangi önek altına eklenirse eklensin yollar doğru çözülür.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// OpenAPI Handler'ın OpenAPI 3 belgesini JSON olarak döndürür
+func OpenAPI() []byte {
+	return append([]byte(nil), openAPIDocument...)
+}
+
+// openAPI belgeyi sunar
+func (h *Handler) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocument)
+}
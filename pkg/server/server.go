@@ -0,0 +1,158 @@
+// Package server motoru HTTP/JSON üzerinden sunar. Handler iş akışı tanımlarını
+// yönetir, örnek başlatır ve çalışan örnekleri denetler:
+//
+//	GET    /definitions                      her tanımın en güncel sürümü
+//	POST   /definitions                      yeni tanım (sürüm 1)         -> 201
+//	GET    /definitions/<id>[?version=N]     tanım
+//	PUT    /definitions/<id>                 tanımın yeni sürümü
+//	DELETE /definitions/<id>                 tanımın tüm sürümleri        -> 204
+//	GET    /definitions/<id>/versions        tanımın tüm sürümleri
+//	GET    /instances                        örnek listesi (filtre ve sayfalama)
+//	POST   /instances                        örnek başlatma               -> 201
+//	GET    /instances/<id>                   örneğin kaydı ve durumu
+//	GET    /instances/<id>/history           örneğin geçmişi
+//	POST   /instances/<id>/cancel            iptal
+//	POST   /instances/<id>/pause             duraklatma
+//	POST   /instances/<id>/resume            duraklatmayı kaldırma
+//	POST   /instances/<id>/retry             başarısız örneği yeniden deneme
+//	POST   /instances/<id>/signals/<name>    sinyal; gövde sinyalin verisidir
+//	POST   /instances/<id>/approvals/<name>  onay sinyali
+//	GET    /openapi.json                     OpenAPI 3 belgesi
+//
+// Örneği ilerleten çağrılar (başlatma, sinyal, onay, devam, yeniden deneme) örnek bir sonraki
+// bekleme noktasına veya sona ulaşınca örneğin güncel kaydıyla döner. Örnek
+// istemci bağlantıyı kapatsa da yürütülmeye devam eder. Hatalar {"error": "..."}
+// gövdesiyle döner; bulunamayan kayıtlar 404, örneğin durumuna uymayan işlemler
+// 409 alır.
+//
+// Handler mevcut bir http.ServeMux'a Mount ile bir önek altında eklenebilir:
+//
+//	api := server.NewHandler(wfEngine)
+//	api.Mount(mux, "/api")
+//
+// Canlı olaylar Server-Sent Events olarak EventStream ile ayrıca sunulur:
+//
+//	mux.Handle("/api/events", server.NewEventStream(wfEngine))
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// DefaultPageSize örnek listesinde limit verilmediğinde dönen kayıt sayısıdır
+const DefaultPageSize = 50
+
+// MaxPageSize örnek listesinde bir sayfada dönebilecek en fazla kayıt sayısıdır
+const MaxPageSize = 500
+
+// Handler motorun HTTP arayüzüdür
+type Handler struct {
+	engine *engine.WorkflowEngine
+
+	// definitionMutex tanım sürümlerinin aynı anda iki isteğe verilmesini önler
+	definitionMutex sync.Mutex
+}
+
+// NewHandler motoru sunan bir Handler oluşturur. Handler bir önek altında
+// sunulacaksa Mount kullanılmalı ya da http.StripPrefix ile sarılmalıdır.
+func NewHandler(e *engine.WorkflowEngine) *Handler {
+	return &Handler{engine: e}
+}
+
+// Mount Handler'ı mux'a prefix altında ekler; "/api" öneki için istekler
+// /api/definitions gibi yollardan gelir. Boş önek Handler'ı köke ekler.
+func (h *Handler) Mount(mux *http.ServeMux, prefix string) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		mux.Handle("/", h)
+		return
+	}
+	mux.Handle(prefix+"/", http.StripPrefix(prefix, h))
+}
+
+// errorResponse hata yanıtlarının gövdesidir
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP isteği yoluna göre yönlendirir
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "openapi.json":
+		if allow(w, r, http.MethodGet) {
+			h.openAPI(w, r)
+		}
+	case parts[0] == "definitions":
+		h.routeDefinitions(w, r, parts[1:])
+	case parts[0] == "instances":
+		h.routeInstances(w, r, parts[1:])
+	default:
+		notFound(w, r)
+	}
+}
+
+// allow isteğin yöntemi methods arasında değilse 405 yazar
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "yöntem desteklenmiyor: "+r.Method)
+	return false
+}
+
+// notFound bilinmeyen yollar için 404 yazar
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, "yol bulunamadı: "+r.URL.Path)
+}
+
+// decode isteğin JSON gövdesini çözer; gövde boşsa v değiştirilmez
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "geçersiz istek gövdesi: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// engineStatus motor hatasının HTTP durum kodunu döndürür
+func engineStatus(err error) int {
+	switch {
+	case errors.Is(err, engine.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, engine.ErrInstanceNotActive),
+		errors.Is(err, engine.ErrInstanceFinished),
+		errors.Is(err, engine.ErrInstanceNotFailed),
+		errors.Is(err, engine.ErrDefinitionInUse),
+		errors.Is(err, engine.ErrHistoryConflict),
+		errors.Is(err, engine.ErrLeaseLost):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeEngineError motor hatasını HTTP durum koduna çevirir
+func writeEngineError(w http.ResponseWriter, err error) {
+	writeError(w, engineStatus(err), err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
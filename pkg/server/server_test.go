@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// newTestServer approval sinyalini bekleyen order tanımını kayıtlı bir motoru sunar
+func newTestServer(t *testing.T) (*engine.WorkflowEngine, *httptest.Server) {
+	t.Helper()
+	e := engine.NewWorkflowEngine()
+	e.RegisterStep("prepare", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "prepared", nil
+	})
+	e.RegisterStep("ship", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "shipped", nil
+	})
+	if err := e.RegisterDefinition(context.Background(), orderDefinition()); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(e))
+	t.Cleanup(server.Close)
+	return e, server
+}
+
+// orderDefinition hazırlık, onay bekleme ve gönderim adımlarından oluşur
+func orderDefinition() *engine.WorkflowDefinition {
+	definition := engine.NewWorkflowDefinition("order", "Order", "")
+	definition.AddStep(engine.NewStepDefinition("prepare", "Prepare", engine.StepTypeTask).
+		WithNextSteps("approval"))
+	definition.AddStep(engine.NewStepDefinition("approval", "Approval", engine.StepTypeSignal).
+		WithConfig(map[string]interface{}{engine.SignalConfigName: "approval"}).
+		WithNextSteps("ship"))
+	definition.AddStep(engine.NewStepDefinition("ship", "Ship", engine.StepTypeTask))
+	return definition
+}
+
+// do isteği gönderir ve başarılı yanıtın gövdesini out'a çözer
+func do(t *testing.T, method, url string, body, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestMountUnderPrefix(t *testing.T) {
+	e, _ := newTestServer(t)
+	mux := http.NewServeMux()
+	NewHandler(e).Mount(mux, "/api/")
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var definition engine.WorkflowDefinition
+	if status := do(t, http.MethodGet, server.URL+"/api/definitions/order", nil, &definition); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if definition.ID != "order" {
+		t.Errorf("Expected order definition, got %q", definition.ID)
+	}
+
+	// Önek dışındaki yollar Handler'a ulaşmaz
+	if status := do(t, http.MethodGet, server.URL+"/definitions/order", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 outside prefix, got %d", status)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	_, server := newTestServer(t)
+
+	var document struct {
+		OpenAPI string                 `json:"openapi"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+	if status := do(t, http.MethodGet, server.URL+"/openapi.json", nil, &document); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if document.OpenAPI == "" {
+		t.Error("Expected openapi version")
+	}
+	for _, path := range []string{"/definitions", "/instances/{id}/approvals/{name}"} {
+		if _, ok := document.Paths[path]; !ok {
+			t.Errorf("Expected path %s in document", path)
+		}
+	}
+	if !json.Valid(OpenAPI()) {
+		t.Error("Expected OpenAPI to return valid JSON")
+	}
+}
+
+func TestUnsupportedMethodAndPath(t *testing.T) {
+	_, server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/instances", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); allow != "GET, POST" {
+		t.Errorf("Expected Allow header GET, POST, got %q", allow)
+	}
+
+	if status := do(t, http.MethodGet, server.URL+"/unknown", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", status)
+	}
+}
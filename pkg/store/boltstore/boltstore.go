@@ -0,0 +1,609 @@
+// Package boltstore iş akışı durumunu tek bir gömülü bbolt dosyasında saklayan
+// engine.WorkflowStore uygulamasını içerir. Ayrı bir veritabanı sunucusu
+// gerektirmediğinden tek düğümlü kurulumlar için uygundur.
+//
+// Kova düzeni:
+//
+//	definitions/<id>/<sürüm>              tanım (sürüm 8 bayt big-endian)
+//	instances/<id>                        örnek kaydı
+//	instances_by_status/<durum>\x00<id>   durum indeksi
+//	instances_by_workflow/<tanım>\x00<id> tanım indeksi
+//	history/<id>/<sıra>                   geçmiş olayı (sıra 8 bayt big-endian)
+//	timers/<id>                           zamanlayıcı
+//	timers_by_wake/<zaman><id>            uyanma zamanı indeksi (zaman 8 bayt)
+//	events/<sıra>                         engine.EventLog olayı (sıra 8 bayt big-endian)
+//
+// Bir örneğin kaydı ve indeksleri aynı işlemde güncellenir; yarım kalan bir
+// yazma indeksleri kayıttan ayırmaz. Depo engine.TransitionStore arayüzünü
+// uyguladığından geçmiş olayları ve örnek kaydı da birlikte yazılır.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	definitionsBucket         = []byte("definitions")
+	instancesBucket           = []byte("instances")
+	instancesByStatusBucket   = []byte("instances_by_status")
+	instancesByWorkflowBucket = []byte("instances_by_workflow")
+	historyBucket             = []byte("history")
+	timersBucket              = []byte("timers")
+	timersByWakeBucket        = []byte("timers_by_wake")
+	eventsBucket              = []byte("events")
+)
+
+// separator indeks anahtarlarında önek ile kimliği ayırır
+const separator = 0
+
+// compactTxSize sıkıştırma sırasında tek işlemde kopyalanan en fazla bayt sayısıdır
+const compactTxSize = 64 << 20
+
+// Store bbolt tabanlı bir WorkflowStore uygulamasıdır
+type Store struct {
+	path    string
+	options *bolt.Options
+
+	// mutex yalnızca Compact sırasında veritabanının değiştirilmesini korur;
+	// işlemler arası eşzamanlılık bbolt tarafından yönetilir
+	mutex sync.RWMutex
+	db    *bolt.DB
+}
+
+// Option deponun yapılandırma seçeneğini temsil eder
+type Option func(*Store)
+
+// WithNoSync her işlemden sonra yapılan fsync'i kapatır; yazma hızı artar ancak
+// çökmede son işlemler kaybolabilir
+func WithNoSync() Option {
+	return func(s *Store) {
+		s.options.NoSync = true
+	}
+}
+
+// WithLockTimeout dosya kilidi için beklenecek en uzun süreyi belirler; dosyayı
+// başka bir süreç açmışsa Open bu süreden sonra hata döndürür
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		s.options.Timeout = timeout
+	}
+}
+
+// Open verilen yoldaki veritabanı dosyasını açar; dosya yoksa oluşturulur
+func Open(path string, opts ...Option) (*Store, error) {
+	s := &Store{
+		path:    path,
+		options: &bolt.Options{Timeout: time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	s.db = db
+	return s, nil
+}
+
+// open veritabanını açar ve kovaları oluşturur
+func (s *Store) open() (*bolt.DB, error) {
+	db, err := bolt.Open(s.path, 0o600, s.options)
+	if err != nil {
+		return nil, fmt.Errorf("veritabanı açılamadı: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			definitionsBucket, instancesBucket, instancesByStatusBucket, instancesByWorkflowBucket,
+			historyBucket, timersBucket, timersByWakeBucket, eventsBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("kovalar oluşturulamadı: %w", err)
+	}
+	return db, nil
+}
+
+// Path veritabanı dosyasının yolunu döndürür
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Close veritabanını kapatır
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.db.Close()
+}
+
+// view veritabanında salt okunur bir işlem çalıştırır
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.db.View(fn)
+}
+
+// update veritabanında yazma işlemi çalıştırır; fn hata döndürürse hiçbir değişiklik yazılmaz
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.db.Update(fn)
+}
+
+// Compact veritabanını yeni bir dosyaya kopyalayarak silinen ve üzerine yazılan
+// kayıtların bıraktığı boş sayfaları geri kazanır. bbolt dosyası kendiliğinden
+// küçülmediğinden zamanlayıcı ve örnek trafiği yoğun kurulumlarda düzenli olarak
+// çağrılmalıdır. Sıkıştırma sırasında diğer işlemler bekler.
+func (s *Store) Compact(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tmpPath := s.path + ".compact"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	dst, err := bolt.Open(tmpPath, 0o600, &bolt.Options{Timeout: s.options.Timeout})
+	if err != nil {
+		return fmt.Errorf("sıkıştırma dosyası açılamadı: %w", err)
+	}
+	if err := bolt.Compact(dst, s.db, compactTxSize); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("veritabanı sıkıştırılamadı: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// Eski dosya kapatılıp sıkıştırılmış kopya yerine taşınır. Kapatmadan sonraki
+	// her hatada özgün dosya yeniden açılır; depo kapalı bir veritabanıyla kalmaz.
+	if err := s.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return s.reopen(fmt.Errorf("veritabanı kapatılamadı: %w", err))
+	}
+	if err := renameFile(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return s.reopen(fmt.Errorf("sıkıştırılmış dosya taşınamadı: %w", err))
+	}
+	return s.reopen(nil)
+}
+
+// renameFile sıkıştırılmış dosyayı yerine taşır; testler hata yolunu denemek için değiştirir
+var renameFile = os.Rename
+
+// reopen veritabanı dosyasını yeniden açar ve cause hatasını döndürür. Dosya
+// açılamazsa iki hata birlikte döner.
+func (s *Store) reopen(cause error) error {
+	db, err := s.open()
+	if err != nil {
+		return errors.Join(cause, err)
+	}
+	s.db = db
+	return cause
+}
+
+// SaveDefinition tanımı ID ve sürümüyle kaydeder
+func (s *Store) SaveDefinition(ctx context.Context, definition *engine.WorkflowDefinition) error {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		versions, err := tx.Bucket(definitionsBucket).CreateBucketIfNotExists([]byte(definition.ID))
+		if err != nil {
+			return err
+		}
+		return versions.Put(uint64Key(uint64(definition.Version)), data)
+	})
+}
+
+// DeleteDefinition tanımın tüm sürümlerini siler
+func (s *Store) DeleteDefinition(ctx context.Context, id string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		definitions := tx.Bucket(definitionsBucket)
+		if definitions.Bucket([]byte(id)) == nil {
+			return engine.ErrNotFound
+		}
+		return definitions.DeleteBucket([]byte(id))
+	})
+}
+
+// GetDefinition tanımı döndürür; version 0 ise en güncel sürüm döner
+func (s *Store) GetDefinition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error) {
+	var definition engine.WorkflowDefinition
+	err := s.view(func(tx *bolt.Tx) error {
+		versions := tx.Bucket(definitionsBucket).Bucket([]byte(id))
+		if versions == nil {
+			return engine.ErrNotFound
+		}
+
+		var data []byte
+		if version == 0 {
+			_, data = versions.Cursor().Last()
+		} else {
+			data = versions.Get(uint64Key(uint64(version)))
+		}
+		if data == nil {
+			return engine.ErrNotFound
+		}
+		return json.Unmarshal(data, &definition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// ListDefinitions tüm tanımları ID ve sürüme göre sıralı döndürür
+func (s *Store) ListDefinitions(ctx context.Context) ([]*engine.WorkflowDefinition, error) {
+	result := make([]*engine.WorkflowDefinition, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		// Kova anahtarları bayt sırasıyla, sürümler big-endian olduğundan sayısal sırayla gezilir
+		return tx.Bucket(definitionsBucket).ForEachBucket(func(id []byte) error {
+			return tx.Bucket(definitionsBucket).Bucket(id).ForEach(func(_, data []byte) error {
+				var definition engine.WorkflowDefinition
+				if err := json.Unmarshal(data, &definition); err != nil {
+					return err
+				}
+				result = append(result, &definition)
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveInstance örnek kaydını saklar ve durum ile tanım indekslerini aynı işlemde günceller
+func (s *Store) SaveInstance(ctx context.Context, record *engine.InstanceRecord) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return putInstance(tx, record)
+	})
+}
+
+// SaveTransition geçmiş olaylarını ekler ve örnek kaydını aynı işlemde saklar
+func (s *Store) SaveTransition(ctx context.Context, record *engine.InstanceRecord, events []engine.HistoryEvent) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := appendHistory(tx, record.ID, events); err != nil {
+			return err
+		}
+		return putInstance(tx, record)
+	})
+}
+
+// putInstance kaydı yazar; önceki kaydın indeks girdilerini kaldırıp yenilerini ekler
+func putInstance(tx *bolt.Tx, record *engine.InstanceRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	instances := tx.Bucket(instancesBucket)
+	byStatus := tx.Bucket(instancesByStatusBucket)
+	byWorkflow := tx.Bucket(instancesByWorkflowBucket)
+
+	if previous := instances.Get([]byte(record.ID)); previous != nil {
+		var old engine.InstanceRecord
+		if err := json.Unmarshal(previous, &old); err != nil {
+			return err
+		}
+		if err := byStatus.Delete(indexKey(string(old.State.Status), old.ID)); err != nil {
+			return err
+		}
+		if err := byWorkflow.Delete(indexKey(old.WorkflowID, old.ID)); err != nil {
+			return err
+		}
+	}
+
+	if err := instances.Put([]byte(record.ID), data); err != nil {
+		return err
+	}
+	if err := byStatus.Put(indexKey(string(record.State.Status), record.ID), []byte{}); err != nil {
+		return err
+	}
+	return byWorkflow.Put(indexKey(record.WorkflowID, record.ID), []byte{})
+}
+
+// GetInstance örnek kaydını döndürür
+func (s *Store) GetInstance(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	var record engine.InstanceRecord
+	err := s.view(func(tx *bolt.Tx) error {
+		data := tx.Bucket(instancesBucket).Get([]byte(id))
+		if data == nil {
+			return engine.ErrNotFound
+		}
+		return json.Unmarshal(data, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListInstances filtreye uyan örnekleri oluşturulma zamanına göre sıralı
+// döndürür. Durum veya tanım filtresi varsa yalnızca ilgili indeks gezilir.
+func (s *Store) ListInstances(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error) {
+	result := make([]*engine.InstanceRecord, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		instances := tx.Bucket(instancesBucket)
+
+		var ids []string
+		switch {
+		case len(filter.Statuses) > 0:
+			byStatus := tx.Bucket(instancesByStatusBucket)
+			seen := make(map[engine.WorkflowStatus]bool, len(filter.Statuses))
+			for _, status := range filter.Statuses {
+				if seen[status] {
+					continue
+				}
+				seen[status] = true
+				ids = append(ids, scanIndex(byStatus, string(status))...)
+			}
+		case filter.WorkflowID != "":
+			ids = scanIndex(tx.Bucket(instancesByWorkflowBucket), filter.WorkflowID)
+		default:
+			err := instances.ForEach(func(id, _ []byte) error {
+				ids = append(ids, string(id))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, id := range ids {
+			data := instances.Get([]byte(id))
+			if data == nil {
+				continue
+			}
+			var record engine.InstanceRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			if filter.Match(&record) {
+				result = append(result, &record)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// AppendHistory olayları örneğin geçmişinin sonuna tek bir işlemde ekler
+func (s *Store) AppendHistory(ctx context.Context, instanceID string, events []engine.HistoryEvent) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return appendHistory(tx, instanceID, events)
+	})
+}
+
+// appendHistory olayları son sıranın devamıysa geçmiş kovasına yazar
+func appendHistory(tx *bolt.Tx, instanceID string, events []engine.HistoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	history, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(instanceID))
+	if err != nil {
+		return err
+	}
+
+	var last int64
+	if key, _ := history.Cursor().Last(); key != nil {
+		last = int64(binary.BigEndian.Uint64(key))
+	}
+	if err := engine.CheckHistoryAppend(last, events); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err := history.Put(uint64Key(uint64(event.Sequence)), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadHistory örneğin geçmişini sıralı döndürür
+func (s *Store) LoadHistory(ctx context.Context, instanceID string) ([]engine.HistoryEvent, error) {
+	result := make([]engine.HistoryEvent, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		history := tx.Bucket(historyBucket).Bucket([]byte(instanceID))
+		if history == nil {
+			return nil
+		}
+		return history.ForEach(func(_, data []byte) error {
+			var event engine.HistoryEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				return err
+			}
+			result = append(result, event)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AppendEvent olayı olay günlüğüne son sıranın bir fazlasıyla yazar
+func (s *Store) AppendEvent(ctx context.Context, event engine.Event) (int64, error) {
+	err := s.update(func(tx *bolt.Tx) error {
+		events := tx.Bucket(eventsBucket)
+		event.Sequence = 1
+		if key, _ := events.Cursor().Last(); key != nil {
+			event.Sequence = int64(binary.BigEndian.Uint64(key)) + 1
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return events.Put(uint64Key(uint64(event.Sequence)), data)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return event.Sequence, nil
+}
+
+// LoadEvents sırası after'dan büyük olayları sıralı döndürür
+func (s *Store) LoadEvents(ctx context.Context, after int64, limit int) ([]engine.Event, error) {
+	if after < 0 {
+		after = 0
+	}
+	result := make([]engine.Event, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(eventsBucket).Cursor()
+		for key, data := cursor.Seek(uint64Key(uint64(after) + 1)); key != nil; key, data = cursor.Next() {
+			var event engine.Event
+			if err := json.Unmarshal(data, &event); err != nil {
+				return err
+			}
+			result = append(result, event)
+			if limit > 0 && len(result) == limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveTimer zamanlayıcıyı kaydeder; uyanma zamanı değişmişse eski indeks girdisi kaldırılır
+func (s *Store) SaveTimer(ctx context.Context, timer engine.Timer) error {
+	data, err := json.Marshal(timer)
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		if err := deleteTimer(tx, timer.ID); err != nil {
+			return err
+		}
+		if err := tx.Bucket(timersBucket).Put([]byte(timer.ID), data); err != nil {
+			return err
+		}
+		return tx.Bucket(timersByWakeBucket).Put(wakeKey(timer.WakeAt, timer.ID), []byte{})
+	})
+}
+
+// DeleteTimer zamanlayıcıyı siler
+func (s *Store) DeleteTimer(ctx context.Context, id string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return deleteTimer(tx, id)
+	})
+}
+
+// deleteTimer zamanlayıcıyı ve indeks girdisini siler; olmayan zamanlayıcı hata değildir
+func deleteTimer(tx *bolt.Tx, id string) error {
+	timers := tx.Bucket(timersBucket)
+	data := timers.Get([]byte(id))
+	if data == nil {
+		return nil
+	}
+	var timer engine.Timer
+	if err := json.Unmarshal(data, &timer); err != nil {
+		return err
+	}
+	if err := tx.Bucket(timersByWakeBucket).Delete(wakeKey(timer.WakeAt, timer.ID)); err != nil {
+		return err
+	}
+	return timers.Delete([]byte(id))
+}
+
+// DueTimers vadesi gelmiş zamanlayıcıları uyanma zamanı indeksini baştan
+// gezerek sıralı döndürür
+func (s *Store) DueTimers(ctx context.Context, now time.Time, limit int) ([]engine.Timer, error) {
+	result := make([]engine.Timer, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		timers := tx.Bucket(timersBucket)
+		cursor := tx.Bucket(timersByWakeBucket).Cursor()
+		for key, _ := cursor.First(); key != nil; key, _ = cursor.Next() {
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+			var timer engine.Timer
+			if err := json.Unmarshal(timers.Get(key[8:]), &timer); err != nil {
+				return err
+			}
+			if timer.WakeAt.After(now) {
+				break
+			}
+			result = append(result, timer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// uint64Key sayıyı bayt sırası sayısal sırayla aynı olan anahtara çevirir
+func uint64Key(n uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, n)
+	return key
+}
+
+// wakeKey uyanma zamanı ve kimlikten sıralı indeks anahtarı üretir. İşaret biti
+// çevrildiğinden 1970 öncesi zamanlar da doğru sıralanır.
+func wakeKey(wakeAt time.Time, id string) []byte {
+	return append(uint64Key(uint64(wakeAt.UnixNano())^(1<<63)), id...)
+}
+
+// indexKey önek ve kimlikten indeks anahtarı üretir
+func indexKey(prefix, id string) []byte {
+	key := make([]byte, 0, len(prefix)+1+len(id))
+	key = append(key, prefix...)
+	key = append(key, separator)
+	return append(key, id...)
+}
+
+// scanIndex önekle başlayan indeks girdilerinin kimliklerini döndürür
+func scanIndex(bucket *bolt.Bucket, prefix string) []string {
+	seek := indexKey(prefix, "")
+	ids := make([]string, 0)
+	cursor := bucket.Cursor()
+	for key, _ := cursor.Seek(seek); key != nil && bytes.HasPrefix(key, seek); key, _ = cursor.Next() {
+		ids = append(ids, string(key[len(seek):]))
+	}
+	return ids
+}
@@ -0,0 +1,186 @@
+package boltstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/storetest"
+)
+
+// openStore geçici dizinde bir depo açar ve test sonunda kapatır
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) engine.WorkflowStore {
+		return openStore(t, filepath.Join(t.TempDir(), "maestro.db"))
+	})
+}
+
+func TestTimerSurvivesProcessRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "maestro.db")
+	clock := engine.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	definition := engine.NewWorkflowDefinition("reminder", "Reminder", "")
+	definition.AddStep(engine.NewStepDefinition("wait", "Wait", engine.StepTypeTimer).
+		WithConfig(map[string]interface{}{engine.TimerConfigDuration: "72h"}).
+		WithNextSteps("remind"))
+	definition.AddStep(engine.NewStepDefinition("remind", "Remind", engine.StepTypeTask))
+
+	// İlk süreç dosyayı kapatarak sona erer
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first := engine.NewWorkflowEngine(engine.WithStore(store), engine.WithClock(clock))
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := engine.NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := openStore(t, path)
+	second := engine.NewWorkflowEngine(engine.WithStore(reopened), engine.WithClock(clock))
+	reminded := false
+	second.RegisterStep("remind", func(ctx context.Context, data interface{}) (interface{}, error) {
+		reminded = true
+		return nil, nil
+	})
+
+	recovered, err := second.Recover(ctx)
+	if err != nil || len(recovered) != 1 {
+		t.Fatalf("Expected one recovered instance, got %d (%v)", len(recovered), err)
+	}
+
+	clock.Advance(72 * time.Hour)
+	if _, err := second.FireDueTimers(ctx); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+	if !reminded {
+		t.Error("Remind step should run after restart")
+	}
+
+	record, err := reopened.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed instance, got %s", record.State.Status)
+	}
+	if timers, _ := reopened.DueTimers(ctx, clock.Now(), 0); len(timers) != 0 {
+		t.Errorf("Fired timer should be deleted, got %d", len(timers))
+	}
+}
+
+func TestCompactReclaimsSpace(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "maestro.db")
+	store := openStore(t, path)
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	payload := make([]byte, 4096)
+	for i := 0; i < 500; i++ {
+		record := &engine.InstanceRecord{
+			ID:         fmt.Sprintf("instance-%d", i),
+			WorkflowID: "share",
+			State:      engine.WorkflowState{Status: engine.StatusCompleted, Context: map[string]interface{}{"payload": payload}},
+			CreatedAt:  now,
+		}
+		if err := store.SaveInstance(ctx, record); err != nil {
+			t.Fatalf("SaveInstance failed: %v", err)
+		}
+		timer := engine.Timer{ID: record.ID, InstanceID: record.ID, StepID: "wait", WakeAt: now}
+		if err := store.SaveTimer(ctx, timer); err != nil {
+			t.Fatalf("SaveTimer failed: %v", err)
+		}
+		if err := store.DeleteTimer(ctx, timer.ID); err != nil {
+			t.Fatalf("DeleteTimer failed: %v", err)
+		}
+	}
+
+	// Kayıtlar küçültülünce eski sayfalar boş kalır
+	for i := 0; i < 500; i++ {
+		record := &engine.InstanceRecord{ID: fmt.Sprintf("instance-%d", i), WorkflowID: "share", State: engine.WorkflowState{Status: engine.StatusCompleted}, CreatedAt: now}
+		if err := store.SaveInstance(ctx, record); err != nil {
+			t.Fatalf("SaveInstance failed: %v", err)
+		}
+	}
+
+	before, _ := os.Stat(path)
+	if err := store.Compact(ctx); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Errorf("Compact should shrink the file, %d -> %d bytes", before.Size(), after.Size())
+	}
+
+	// Sıkıştırılmış dosya aynı kayıtları ve indeksleri içermeli
+	records, err := store.ListInstances(ctx, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusCompleted}})
+	if err != nil || len(records) != 500 {
+		t.Errorf("Expected 500 instances after compaction, got %d (%v)", len(records), err)
+	}
+	if err := store.SaveInstance(ctx, &engine.InstanceRecord{ID: "new", WorkflowID: "share", CreatedAt: now}); err != nil {
+		t.Errorf("Store should stay writable after compaction: %v", err)
+	}
+}
+
+func TestOpenFailsWhenLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maestro.db")
+	openStore(t, path)
+
+	if _, err := Open(path, WithLockTimeout(50*time.Millisecond)); err == nil {
+		t.Error("Second Open should fail while the file is locked")
+	}
+}
+
+func TestCompactReopensOnFailure(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "maestro.db")
+	store := openStore(t, path)
+
+	record := &engine.InstanceRecord{ID: "instance", WorkflowID: "share", State: engine.WorkflowState{Status: engine.StatusRunning}}
+	if err := store.SaveInstance(ctx, record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+
+	// Taşıma, özgün dosya kapatıldıktan sonra başarısız olur
+	errRename := errors.New("device busy")
+	renameFile = func(from, to string) error { return errRename }
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	if err := store.Compact(ctx); !errors.Is(err, errRename) {
+		t.Fatalf("Expected the rename error, got %v", err)
+	}
+	if _, err := os.Stat(path + ".compact"); !os.IsNotExist(err) {
+		t.Errorf("Compaction file should be removed, got %v", err)
+	}
+
+	// Depo özgün dosyayla çalışmaya devam etmeli
+	if _, err := store.GetInstance(ctx, "instance"); err != nil {
+		t.Errorf("GetInstance after failed compaction failed: %v", err)
+	}
+	record.State.Status = engine.StatusCompleted
+	if err := store.SaveInstance(ctx, record); err != nil {
+		t.Errorf("SaveInstance after failed compaction failed: %v", err)
+	}
+}
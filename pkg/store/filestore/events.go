@@ -0,0 +1,121 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+const eventsFile = "events.jsonl"
+
+// AppendEvent olayı olay günlüğü dosyasının sonuna ekler. Günlük geçmiş
+// eklemeleriyle aynı fsync politikasına tabidir.
+func (s *Store) AppendEvent(ctx context.Context, event engine.Event) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := filepath.Join(s.dir, eventsFile)
+	if !s.eventsLoaded {
+		events, size, err := readEvents(path)
+		if err != nil {
+			return 0, err
+		}
+		// Çökme sırasında yarım kalmış son satır yeni olaydan önce atılır
+		if err := truncateTail(path, size); err != nil {
+			return 0, err
+		}
+		s.lastEvent = 0
+		if n := len(events); n > 0 {
+			s.lastEvent = events[n-1].Sequence
+		}
+		s.eventsLoaded = true
+	}
+
+	event.Sequence = s.lastEvent + 1
+	line, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		s.eventsLoaded = false
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		s.eventsLoaded = false
+		return 0, err
+	}
+	s.lastEvent = event.Sequence
+
+	if !s.syncPolicy.durable() {
+		return event.Sequence, nil
+	}
+	s.unsynced[path] = true
+	s.appends++
+	if s.syncDue() {
+		return event.Sequence, s.syncLocked()
+	}
+	return event.Sequence, nil
+}
+
+// LoadEvents sırası after'dan büyük olayları dosyadan sıralı okur
+func (s *Store) LoadEvents(ctx context.Context, after int64, limit int) ([]engine.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	events, _, err := readEvents(filepath.Join(s.dir, eventsFile))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]engine.Event, 0)
+	for _, event := range events {
+		if event.Sequence <= after {
+			continue
+		}
+		result = append(result, event)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// readEvents olay günlüğü dosyasını readHistory kurallarıyla okur; yarım
+// kalmış son satır yok sayılır, dosya yoksa boş liste döner
+func readEvents(path string) ([]engine.Event, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []engine.Event{}, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	events := make([]engine.Event, 0)
+	var size int64
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		var event engine.Event
+		if err := json.Unmarshal(data[:i], &event); err != nil {
+			return nil, 0, fmt.Errorf("olay günlüğü okunamadı (satır %d): %w", len(events)+1, err)
+		}
+		events = append(events, event)
+		size += int64(i + 1)
+		data = data[i+1:]
+	}
+	return events, size, nil
+}
@@ -0,0 +1,390 @@
+// Package filestore iş akışı durumunu yerel bir dizinde JSON dosyaları olarak
+// saklayan engine.WorkflowStore uygulamasını içerir.
+//
+// Dizin düzeni:
+//
+//	definitions/<id>/<sürüm>.json
+//	instances/<id>.json
+//	timers/<id>.json
+//	history/<id>.jsonl   (satır başına bir geçmiş olayı)
+//	events.jsonl         (engine.EventLog; satır başına bir gözlemci olayı)
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+const (
+	definitionsDir = "definitions"
+	instancesDir   = "instances"
+	timersDir      = "timers"
+	historyDir     = "history"
+)
+
+// Store dosya sistemi tabanlı bir WorkflowStore uygulamasıdır
+type Store struct {
+	dir   string
+	mutex sync.RWMutex
+
+	// sequences her örneğin dosyadaki son geçmiş sırasını önbellekte tutar
+	sequences map[string]int64
+
+	// lastEvent olay günlüğünün son sırasıdır; eventsLoaded false ise dosyadan okunur
+	lastEvent    int64
+	eventsLoaded bool
+
+	// Geçmiş eklemeleri syncPolicy'ye göre toplu olarak fsync edilir
+	syncPolicy SyncPolicy
+	unsynced   map[string]bool
+	appends    int
+	lastSync   time.Time
+}
+
+// Option deponun yapılandırma seçeneğini temsil eder
+type Option func(*Store)
+
+// WithSyncPolicy geçmiş eklemelerinin ve kayıtların fsync politikasını belirler;
+// varsayılan SyncAlways'dir
+func WithSyncPolicy(policy SyncPolicy) Option {
+	return func(s *Store) {
+		s.syncPolicy = policy
+	}
+}
+
+// New verilen dizini kullanan bir depo oluşturur; dizin yoksa oluşturulur
+func New(dir string, opts ...Option) (*Store, error) {
+	for _, sub := range []string{definitionsDir, instancesDir, timersDir, historyDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("depo dizini oluşturulamadı: %w", err)
+		}
+	}
+
+	s := &Store{
+		dir:        dir,
+		sequences:  make(map[string]int64),
+		syncPolicy: SyncAlways,
+		unsynced:   make(map[string]bool),
+		lastSync:   time.Now(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Dir deponun kök dizinini döndürür
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// SaveDefinition tanımı kaydeder
+func (s *Store) SaveDefinition(ctx context.Context, definition *engine.WorkflowDefinition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dir := filepath.Join(s.dir, definitionsDir, escape(definition.ID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, strconv.Itoa(definition.Version)+".json"), definition, s.syncPolicy.durable())
+}
+
+// DeleteDefinition tanımın tüm sürümlerini siler
+func (s *Store) DeleteDefinition(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dir := filepath.Join(s.dir, definitionsDir, escape(id))
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return engine.ErrNotFound
+		}
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// GetDefinition tanımı döndürür; version 0 ise en güncel sürüm döner
+func (s *Store) GetDefinition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	dir := filepath.Join(s.dir, definitionsDir, escape(id))
+	if version == 0 {
+		versions, err := definitionVersions(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, engine.ErrNotFound
+		}
+		version = versions[len(versions)-1]
+	}
+
+	var definition engine.WorkflowDefinition
+	if err := readJSON(filepath.Join(dir, strconv.Itoa(version)+".json"), &definition); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// ListDefinitions tüm tanımları ID ve sürüme göre sıralı döndürür
+func (s *Store) ListDefinitions(ctx context.Context) ([]*engine.WorkflowDefinition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, definitionsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*engine.WorkflowDefinition, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(s.dir, definitionsDir, entry.Name())
+		versions, err := definitionVersions(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			var definition engine.WorkflowDefinition
+			if err := readJSON(filepath.Join(dir, strconv.Itoa(version)+".json"), &definition); err != nil {
+				return nil, err
+			}
+			result = append(result, &definition)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ID != result[j].ID {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// SaveInstance örnek kaydını saklar
+func (s *Store) SaveInstance(ctx context.Context, record *engine.InstanceRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return writeJSON(s.instancePath(record.ID), record, s.syncPolicy.durable())
+}
+
+// GetInstance örnek kaydını döndürür
+func (s *Store) GetInstance(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var record engine.InstanceRecord
+	if err := readJSON(s.instancePath(id), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListInstances filtreye uyan örnekleri oluşturulma zamanına göre sıralı döndürür
+func (s *Store) ListInstances(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	paths, err := jsonFiles(filepath.Join(s.dir, instancesDir))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*engine.InstanceRecord, 0)
+	for _, path := range paths {
+		var record engine.InstanceRecord
+		if err := readJSON(path, &record); err != nil {
+			return nil, err
+		}
+		if filter.Match(&record) {
+			result = append(result, &record)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// SaveTimer zamanlayıcıyı kaydeder
+func (s *Store) SaveTimer(ctx context.Context, timer engine.Timer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return writeJSON(s.timerPath(timer.ID), timer, s.syncPolicy.durable())
+}
+
+// DeleteTimer zamanlayıcıyı siler
+func (s *Store) DeleteTimer(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.Remove(s.timerPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// DueTimers vadesi gelmiş zamanlayıcıları uyanma zamanına göre sıralı döndürür
+func (s *Store) DueTimers(ctx context.Context, now time.Time, limit int) ([]engine.Timer, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	paths, err := jsonFiles(filepath.Join(s.dir, timersDir))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]engine.Timer, 0)
+	for _, path := range paths {
+		var timer engine.Timer
+		if err := readJSON(path, &timer); err != nil {
+			return nil, err
+		}
+		if !timer.WakeAt.After(now) {
+			result = append(result, timer)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].WakeAt.Equal(result[j].WakeAt) {
+			return result[i].WakeAt.Before(result[j].WakeAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *Store) instancePath(id string) string {
+	return filepath.Join(s.dir, instancesDir, escape(id)+".json")
+}
+
+func (s *Store) timerPath(id string) string {
+	return filepath.Join(s.dir, timersDir, escape(id)+".json")
+}
+
+// escape kimliği dosya adında güvenle kullanılabilir hale getirir
+func escape(id string) string {
+	return url.PathEscape(id)
+}
+
+// writeJSON değeri geçici bir dosyaya yazıp yerine taşıyarak atomik olarak
+// kaydeder; sync doğruysa dosya ve dizin fsync edilir
+func writeJSON(path string, value interface{}, sync bool) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if sync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	if sync {
+		return syncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+// syncDir dizin girdilerini fsync ederek yeniden adlandırmayı kalıcı hale getirir
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// readJSON dosyayı okur; dosya yoksa engine.ErrNotFound döner
+func readJSON(path string, value interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return engine.ErrNotFound
+		}
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// jsonFiles dizindeki JSON dosyalarının yollarını döndürür
+func jsonFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	return paths, nil
+}
+
+// definitionVersions dizindeki tanım sürümlerini artan sırada döndürür
+func definitionVersions(dir string) ([]int, error) {
+	paths, err := jsonFiles(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(paths))
+	for _, path := range paths {
+		version, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
@@ -0,0 +1,337 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/storetest"
+)
+
+func TestDefinitionVersions(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	definition := engine.NewWorkflowDefinition("share", "Share", "")
+	definition.AddStep(engine.NewStepDefinition("fetch", "Fetch", engine.StepTypeTask))
+	if err := store.SaveDefinition(ctx, definition); err != nil {
+		t.Fatalf("SaveDefinition failed: %v", err)
+	}
+	definition.Version = 2
+	if err := store.SaveDefinition(ctx, definition); err != nil {
+		t.Fatalf("SaveDefinition failed: %v", err)
+	}
+
+	latest, err := store.GetDefinition(ctx, "share", 0)
+	if err != nil {
+		t.Fatalf("GetDefinition failed: %v", err)
+	}
+	if latest.Version != 2 || len(latest.Steps) != 1 {
+		t.Errorf("Expected latest version 2 with one step, got %d", latest.Version)
+	}
+
+	all, err := store.ListDefinitions(ctx)
+	if err != nil || len(all) != 2 {
+		t.Errorf("Expected two definition versions, got %d (%v)", len(all), err)
+	}
+
+	if _, err := store.GetDefinition(ctx, "missing", 0); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestInstancesAndTimers(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := &engine.InstanceRecord{
+		ID:         "instance-1",
+		WorkflowID: "share",
+		Version:    1,
+		State: engine.WorkflowState{
+			Status:      engine.StatusFailed,
+			Context:     map[string]interface{}{"user": "jane"},
+			StepResults: map[string]interface{}{},
+			Error:       errors.New("boom"),
+		},
+		CreatedAt: now,
+	}
+	if err := store.SaveInstance(ctx, record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+
+	loaded, err := store.GetInstance(ctx, "instance-1")
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if loaded.State.Error == nil || loaded.State.Error.Error() != "boom" {
+		t.Errorf("Error should round-trip, got %v", loaded.State.Error)
+	}
+	if loaded.State.Context["user"] != "jane" {
+		t.Error("Context should round-trip")
+	}
+
+	failed, err := store.ListInstances(ctx, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusFailed}})
+	if err != nil || len(failed) != 1 {
+		t.Errorf("Expected one failed instance, got %d (%v)", len(failed), err)
+	}
+	running, _ := store.ListInstances(ctx, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusRunning}})
+	if len(running) != 0 {
+		t.Errorf("Expected no running instances, got %d", len(running))
+	}
+
+	for i, wake := range []time.Duration{2 * time.Hour, time.Hour, 3 * time.Hour} {
+		timer := engine.Timer{
+			ID:         "instance-1/step/" + string(rune('a'+i)),
+			InstanceID: "instance-1",
+			StepID:     "step",
+			Kind:       engine.TimerKindSleep,
+			WakeAt:     now.Add(wake),
+		}
+		if err := store.SaveTimer(ctx, timer); err != nil {
+			t.Fatalf("SaveTimer failed: %v", err)
+		}
+	}
+
+	due, err := store.DueTimers(ctx, now.Add(2*time.Hour), 0)
+	if err != nil {
+		t.Fatalf("DueTimers failed: %v", err)
+	}
+	if len(due) != 2 || due[0].ID != "instance-1/step/b" {
+		t.Fatalf("Expected two due timers ordered by wake time, got %+v", due)
+	}
+
+	if err := store.DeleteTimer(ctx, due[0].ID); err != nil {
+		t.Fatalf("DeleteTimer failed: %v", err)
+	}
+	if err := store.DeleteTimer(ctx, due[0].ID); err != nil {
+		t.Errorf("Deleting a missing timer should not fail: %v", err)
+	}
+	due, _ = store.DueTimers(ctx, now.Add(2*time.Hour), 0)
+	if len(due) != 1 {
+		t.Errorf("Expected one due timer after delete, got %d", len(due))
+	}
+}
+
+func TestTimerSurvivesProcessRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	clock := engine.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	definition := engine.NewWorkflowDefinition("reminder", "Reminder", "")
+	definition.AddStep(engine.NewStepDefinition("wait", "Wait", engine.StepTypeTimer).
+		WithConfig(map[string]interface{}{engine.TimerConfigDuration: "72h"}).
+		WithNextSteps("remind"))
+	definition.AddStep(engine.NewStepDefinition("remind", "Remind", engine.StepTypeTask))
+
+	// İlk süreç
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	first := engine.NewWorkflowEngine(engine.WithStore(store), engine.WithClock(clock))
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := engine.NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Yeniden başlatılmış süreç aynı dizini açar
+	reopened, err := New(dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	second := engine.NewWorkflowEngine(engine.WithStore(reopened), engine.WithClock(clock))
+	reminded := false
+	second.RegisterStep("remind", func(ctx context.Context, data interface{}) (interface{}, error) {
+		reminded = true
+		return nil, nil
+	})
+
+	recovered, err := second.Recover(ctx)
+	if err != nil || len(recovered) != 1 {
+		t.Fatalf("Expected one recovered instance, got %d (%v)", len(recovered), err)
+	}
+
+	clock.Advance(72 * time.Hour)
+	if _, err := second.FireDueTimers(ctx); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+	if !reminded {
+		t.Error("Remind step should run after restart")
+	}
+
+	record, err := reopened.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed instance, got %s", record.State.Status)
+	}
+}
+
+func TestHistoryAppendAndReload(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []engine.HistoryEvent{
+		{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now, Context: map[string]interface{}{"user": "ada"}},
+		{Sequence: 2, Type: engine.HistoryStepScheduled, StepID: "fetch", Timestamp: now},
+	}
+	if err := store.AppendHistory(ctx, "instance/1", events); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	// Yeniden açılan depo son sırayı dosyadan öğrenmeli
+	reopened, err := New(dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	stale := []engine.HistoryEvent{{Sequence: 2, Type: engine.HistoryWorkflowCanceled, Timestamp: now}}
+	if err := reopened.AppendHistory(ctx, "instance/1", stale); !errors.Is(err, engine.ErrHistoryConflict) {
+		t.Errorf("Expected ErrHistoryConflict, got %v", err)
+	}
+	next := []engine.HistoryEvent{{Sequence: 3, Type: engine.HistoryStepCompleted, StepID: "fetch", Result: "ok", Timestamp: now}}
+	if err := reopened.AppendHistory(ctx, "instance/1", next); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	history, err := reopened.LoadHistory(ctx, "instance/1")
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if len(history) != 3 || history[2].Result != "ok" || history[0].Context["user"] != "ada" {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+
+	state, err := engine.FoldHistory(history)
+	if err != nil {
+		t.Fatalf("FoldHistory failed: %v", err)
+	}
+	if state.StepResults["fetch"] != "ok" || state.Status != engine.StatusRunning {
+		t.Errorf("Unexpected folded state: %s %v", state.Status, state.StepResults)
+	}
+
+	empty, err := reopened.LoadHistory(ctx, "missing")
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Missing history should be empty, got %d (%v)", len(empty), err)
+	}
+}
+
+func TestHistoryIgnoresTornTail(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []engine.HistoryEvent{
+		{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now},
+		{Sequence: 2, Type: engine.HistoryStepScheduled, StepID: "fetch", Timestamp: now},
+	}
+	if err := store.AppendHistory(ctx, "instance", events); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	// Çökme sırasında yarım kalmış bir satırı taklit et
+	file, err := os.OpenFile(store.historyPath("instance"), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := file.WriteString(`{"seq":3,"type":"step_sta`); err != nil {
+		t.Fatalf("WriteString failed: %v", err)
+	}
+	file.Close()
+
+	reopened, err := New(dir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	history, err := reopened.LoadHistory(ctx, "instance")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("Torn tail should be ignored, got %d events (%v)", len(history), err)
+	}
+
+	// Yeni olaylar yarım satırın yerine yazılmalı
+	next := []engine.HistoryEvent{{Sequence: 3, Type: engine.HistoryStepStarted, StepID: "fetch", Attempt: 1, Timestamp: now}}
+	if err := reopened.AppendHistory(ctx, "instance", next); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+	history, err = reopened.LoadHistory(ctx, "instance")
+	if err != nil || len(history) != 3 || history[2].Attempt != 1 {
+		t.Errorf("Expected three events after append, got %d (%v)", len(history), err)
+	}
+}
+
+func TestSyncPolicyBatchesAppends(t *testing.T) {
+	ctx := context.Background()
+	store, err := New(t.TempDir(), WithSyncPolicy(SyncPolicy{Every: 3}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 2; i++ {
+		event := engine.HistoryEvent{Sequence: i, Type: engine.HistorySignalReceived, Timestamp: now, Signal: &engine.SignalRecord{Name: "ping"}}
+		if err := store.AppendHistory(ctx, "instance", []engine.HistoryEvent{event}); err != nil {
+			t.Fatalf("AppendHistory failed: %v", err)
+		}
+	}
+	if store.appends != 2 || !store.unsynced[store.historyPath("instance")] {
+		t.Fatalf("Appends below the batch size should stay unsynced, got %d", store.appends)
+	}
+
+	// Üçüncü ekleme bekleyen tüm eklemeleri fsync etmeli
+	event := engine.HistoryEvent{Sequence: 3, Type: engine.HistorySignalReceived, Timestamp: now, Signal: &engine.SignalRecord{Name: "ping"}}
+	if err := store.AppendHistory(ctx, "instance", []engine.HistoryEvent{event}); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+	if store.appends != 0 || len(store.unsynced) != 0 {
+		t.Errorf("Batch should be synced, got %d pending appends", store.appends)
+	}
+
+	// SyncNever hiçbir eklemeyi takip etmez
+	never, err := New(t.TempDir(), WithSyncPolicy(SyncNever))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	first := engine.HistoryEvent{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now}
+	if err := never.AppendHistory(ctx, "instance", []engine.HistoryEvent{first}); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+	if never.appends != 0 || len(never.unsynced) != 0 {
+		t.Errorf("SyncNever should not track appends, got %d", never.appends)
+	}
+	if err := never.Sync(); err != nil {
+		t.Errorf("Sync failed: %v", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) engine.WorkflowStore {
+		store, err := New(t.TempDir())
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		return store
+	})
+}
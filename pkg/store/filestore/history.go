@@ -0,0 +1,197 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// SyncPolicy geçmiş eklemelerinin ne zaman fsync ile diske yazılacağını belirler.
+// Every ve Interval birlikte kullanılabilir; hangisi önce dolarsa o ana kadar
+// eklenmiş tüm geçmiş dosyaları fsync edilir. Toplu fsync yazma hızını artırır
+// ancak çökmede son fsync'ten sonraki olaylar kaybolabilir ve kurtarılan örnek
+// bu adımları yeniden çalıştırır.
+type SyncPolicy struct {
+	// Every bu sayıda eklemeden sonra fsync yapılır; 1 her eklemede demektir
+	Every int
+	// Interval son fsync'ten bu kadar süre geçtikten sonraki ilk eklemede fsync yapılır
+	Interval time.Duration
+}
+
+var (
+	// SyncAlways her geçmiş eklemesini ve kaydı çağrı dönmeden önce fsync eder
+	SyncAlways = SyncPolicy{Every: 1}
+	// SyncNever hiç fsync yapmaz; dayanıklılık işletim sisteminin önbelleğine bırakılır
+	SyncNever = SyncPolicy{}
+)
+
+// durable politikanın fsync yapıp yapmadığını döndürür
+func (p SyncPolicy) durable() bool {
+	return p.Every > 0 || p.Interval > 0
+}
+
+// AppendHistory olayları örneğin geçmiş dosyasının sonuna ekler
+func (s *Store) AppendHistory(ctx context.Context, instanceID string, events []engine.HistoryEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := s.historyPath(instanceID)
+	last, ok := s.sequences[instanceID]
+	if !ok {
+		history, size, err := readHistory(path)
+		if err != nil {
+			return err
+		}
+		// Çökme sırasında yarım kalmış son satır yeni olaylardan önce atılır
+		if err := truncateTail(path, size); err != nil {
+			return err
+		}
+		if n := len(history); n > 0 {
+			last = history[n-1].Sequence
+		}
+	}
+	if err := engine.CheckHistoryAppend(last, events); err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		delete(s.sequences, instanceID)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		delete(s.sequences, instanceID)
+		return err
+	}
+	s.sequences[instanceID] = events[len(events)-1].Sequence
+
+	if !s.syncPolicy.durable() {
+		return nil
+	}
+	s.unsynced[path] = true
+	s.appends++
+	if s.syncDue() {
+		return s.syncLocked()
+	}
+	return nil
+}
+
+// LoadHistory örneğin geçmişini dosyadan sıralı okur
+func (s *Store) LoadHistory(ctx context.Context, instanceID string) ([]engine.HistoryEvent, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	history, _, err := readHistory(s.historyPath(instanceID))
+	return history, err
+}
+
+// Sync henüz fsync edilmemiş tüm geçmiş eklemelerini diske yazar; toplu
+// politikalarla çalışırken kapanıştan önce çağrılmalıdır
+func (s *Store) Sync() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.syncLocked()
+}
+
+// syncDue politikaya göre fsync zamanının gelip gelmediğini döndürür
+func (s *Store) syncDue() bool {
+	policy := s.syncPolicy
+	if policy.Every > 0 && s.appends >= policy.Every {
+		return true
+	}
+	return policy.Interval > 0 && time.Since(s.lastSync) >= policy.Interval
+}
+
+// syncLocked bekleyen geçmiş dosyalarını fsync eder; çağıran kilidi tutmalıdır
+func (s *Store) syncLocked() error {
+	for path := range s.unsynced {
+		if err := syncFile(path); err != nil {
+			return fmt.Errorf("geçmiş diske yazılamadı: %w", err)
+		}
+		delete(s.unsynced, path)
+	}
+	if len(s.unsynced) == 0 {
+		s.appends = 0
+		s.lastSync = time.Now()
+	}
+	return syncDir(filepath.Join(s.dir, historyDir))
+}
+
+func (s *Store) historyPath(id string) string {
+	return filepath.Join(s.dir, historyDir, escape(id)+".jsonl")
+}
+
+// readHistory satır başına bir olay içeren geçmiş dosyasını okur ve geçerli
+// içeriğin bayt uzunluğunu döndürür. Satır sonu olmayan son satır çökme
+// sırasında yarım kalmış bir yazmadır ve yok sayılır. Dosya yoksa boş liste döner.
+func readHistory(path string) ([]engine.HistoryEvent, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []engine.HistoryEvent{}, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	history := make([]engine.HistoryEvent, 0)
+	var size int64
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		var event engine.HistoryEvent
+		if err := json.Unmarshal(data[:i], &event); err != nil {
+			return nil, 0, fmt.Errorf("geçmiş okunamadı (%s, satır %d): %w", filepath.Base(path), len(history)+1, err)
+		}
+		history = append(history, event)
+		size += int64(i + 1)
+		data = data[i+1:]
+	}
+	return history, size, nil
+}
+
+// truncateTail dosyayı geçerli uzunluğundan sonrası atılacak şekilde kısaltır
+func truncateTail(path string, size int64) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() == size {
+		return nil
+	}
+	return os.Truncate(path, size)
+}
+
+// syncFile dosyanın içeriğini fsync eder
+func syncFile(path string) error {
+	file, err := os.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
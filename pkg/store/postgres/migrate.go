@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations paketle gelen göç dosyalarıdır; şemayı harici araçlarla kurmak
+// isteyenler için dışa açılır. Dosyalar <sürüm>_<ad>.sql biçimindedir.
+var Migrations fs.FS = mustSub(migrationFiles, "migrations")
+
+// migrationLockID eşzamanlı göçleri sıraya koyan danışma kilidinin anahtarıdır
+const migrationLockID = 0x6d61657374726f // "maestro"
+
+// migration tek bir şema göçünü temsil eder
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate henüz uygulanmamış göçleri sırayla ve tek bir işlemde uygular.
+// Uygulanan sürümler maestro_schema_migrations tablosunda tutulur; aynı anda
+// başlayan düğümlerden yalnızca biri göçleri uygular, diğerleri bekler.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(migrationLockID)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS maestro_schema_migrations (
+			version    INTEGER     PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+		return err
+	}
+
+	var current int
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM maestro_schema_migrations`).Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+			return fmt.Errorf("göç uygulanamadı (%d_%s): %w", m.version, m.name, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO maestro_schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// loadMigrations göç dosyalarını okur ve sürüm sırasıyla döndürür; sürümler
+// 1'den başlayarak kesintisiz olmalıdır
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(Migrations, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, rest, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("geçersiz göç dosyası adı: %s", entry.Name())
+		}
+		data, err := fs.ReadFile(Migrations, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: rest, sql: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("göç sürümleri kesintisiz değil: %d beklenirken %d", i+1, m.version)
+		}
+	}
+	return migrations, nil
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
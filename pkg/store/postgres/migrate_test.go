@@ -0,0 +1,13 @@
+package postgres
+
+import "testing"
+
+func TestMigrationsAreContiguous(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations failed: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].name != "init" {
+		t.Errorf("Expected the init migration first, got %+v", migrations)
+	}
+}
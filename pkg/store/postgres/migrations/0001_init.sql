@@ -0,0 +1,40 @@
+-- İş akışı tanımları, örnekler, geçmiş ve zamanlayıcılar
+CREATE TABLE maestro_definitions (
+    id      TEXT    NOT NULL,
+    version INTEGER NOT NULL,
+    data    JSONB   NOT NULL,
+    PRIMARY KEY (id, version)
+);
+
+CREATE TABLE maestro_instances (
+    id               TEXT        PRIMARY KEY,
+    workflow_id      TEXT        NOT NULL,
+    status           TEXT        NOT NULL,
+    idempotency_key  TEXT,
+    data             JSONB       NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lease_owner      TEXT,
+    lease_expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX maestro_instances_status_idx ON maestro_instances (status, created_at, id);
+CREATE INDEX maestro_instances_workflow_idx ON maestro_instances (workflow_id, created_at, id);
+CREATE INDEX maestro_instances_idempotency_idx ON maestro_instances (workflow_id, idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+
+CREATE TABLE maestro_history (
+    instance_id TEXT   NOT NULL,
+    seq         BIGINT NOT NULL,
+    data        JSONB  NOT NULL,
+    PRIMARY KEY (instance_id, seq)
+);
+
+CREATE TABLE maestro_timers (
+    id          TEXT        PRIMARY KEY,
+    instance_id TEXT        NOT NULL,
+    wake_at     TIMESTAMPTZ NOT NULL,
+    data        JSONB       NOT NULL
+);
+
+CREATE INDEX maestro_timers_wake_idx ON maestro_timers (wake_at, id);
@@ -0,0 +1,5 @@
+-- engine.EventLog için tüm örneklerin gözlemci olayları
+CREATE TABLE maestro_events (
+    seq  BIGINT PRIMARY KEY,
+    data JSONB  NOT NULL
+);
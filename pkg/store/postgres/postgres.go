@@ -0,0 +1,471 @@
+// Package postgres iş akışı durumunu PostgreSQL'de saklayan engine.WorkflowStore
+// uygulamasını içerir. Birden çok maestro süreci aynı veritabanını paylaşabilir:
+// depo engine.LeaseStore arayüzünü uygular ve çalışır örnekler
+// SELECT ... FOR UPDATE SKIP LOCKED ile kiralanarak düğümler arasında bölüşülür.
+// Kiralaması dolan örnek, sahibi olan düğüm ölmüş kabul edilerek başka bir düğüm
+// tarafından devralınır. Depo engine.EventLog arayüzünü de uygular; tüm
+// düğümlerin olayları tek bir günlükte sıralanır.
+//
+// Depo yalnızca database/sql kullanır; sürücü (örneğin github.com/lib/pq veya
+// github.com/jackc/pgx/v5/stdlib) uygulama tarafından seçilir. Şema, paketle
+// birlikte gelen göçlerle Migrate çağrılarak kurulur.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// Store PostgreSQL tabanlı bir WorkflowStore uygulamasıdır
+type Store struct {
+	db *sql.DB
+}
+
+// New verilen bağlantı havuzunu kullanan bir depo oluşturur. Şemanın Migrate
+// ile kurulmuş olması gerekir.
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// DB deponun kullandığı bağlantı havuzunu döndürür
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// SaveDefinition tanımı ID ve sürümüyle kaydeder; aynı sürüm üzerine yazılır
+func (s *Store) SaveDefinition(ctx context.Context, definition *engine.WorkflowDefinition) error {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	// JSONB değerleri metin olarak gönderilir; bazı sürücüler []byte'ı bytea
+	// olarak kodlar
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO maestro_definitions (id, version, data) VALUES ($1, $2, $3)
+		ON CONFLICT (id, version) DO UPDATE SET data = EXCLUDED.data`,
+		definition.ID, definition.Version, string(data))
+	return err
+}
+
+// DeleteDefinition tanımın tüm sürümlerini siler
+func (s *Store) DeleteDefinition(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM maestro_definitions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return engine.ErrNotFound
+	}
+	return nil
+}
+
+// GetDefinition tanımı döndürür; version 0 ise en güncel sürüm döner
+func (s *Store) GetDefinition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error) {
+	var row *sql.Row
+	if version == 0 {
+		row = s.db.QueryRowContext(ctx, `
+			SELECT data FROM maestro_definitions WHERE id = $1 ORDER BY version DESC LIMIT 1`, id)
+	} else {
+		row = s.db.QueryRowContext(ctx, `
+			SELECT data FROM maestro_definitions WHERE id = $1 AND version = $2`, id, version)
+	}
+
+	var definition engine.WorkflowDefinition
+	if err := scanJSON(row, &definition); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// ListDefinitions tüm tanımları ID ve sürüme göre sıralı döndürür
+func (s *Store) ListDefinitions(ctx context.Context) ([]*engine.WorkflowDefinition, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM maestro_definitions ORDER BY id, version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*engine.WorkflowDefinition, 0)
+	for rows.Next() {
+		var definition engine.WorkflowDefinition
+		if err := scanJSON(rows, &definition); err != nil {
+			return nil, err
+		}
+		result = append(result, &definition)
+	}
+	return result, rows.Err()
+}
+
+// SaveInstance örnek kaydını saklar; kiralama sütunlarına dokunmaz
+func (s *Store) SaveInstance(ctx context.Context, record *engine.InstanceRecord) error {
+	return saveInstance(ctx, s.db, record)
+}
+
+// SaveTransition geçmiş olaylarını ekler ve örnek kaydını aynı işlemde saklar
+func (s *Store) SaveTransition(ctx context.Context, record *engine.InstanceRecord, events []engine.HistoryEvent) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := appendHistory(ctx, tx, record.ID, events); err != nil {
+			return err
+		}
+		return saveInstance(ctx, tx, record)
+	})
+}
+
+// execer *sql.DB ve *sql.Tx'in ortak yazma arayüzüdür
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func saveInstance(ctx context.Context, db execer, record *engine.InstanceRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO maestro_instances (id, workflow_id, status, idempotency_key, data, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, now())
+		ON CONFLICT (id) DO UPDATE SET
+			workflow_id = EXCLUDED.workflow_id,
+			status = EXCLUDED.status,
+			idempotency_key = EXCLUDED.idempotency_key,
+			data = EXCLUDED.data,
+			updated_at = now()`,
+		record.ID, record.WorkflowID, string(record.State.Status), record.IdempotencyKey, string(data), record.CreatedAt)
+	return err
+}
+
+// GetInstance örnek kaydını döndürür
+func (s *Store) GetInstance(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT data FROM maestro_instances WHERE id = $1`, id)
+
+	var record engine.InstanceRecord
+	if err := scanJSON(row, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListInstances filtreye uyan örnekleri oluşturulma zamanına göre sıralı döndürür
+func (s *Store) ListInstances(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.WorkflowID != "" {
+		conditions = append(conditions, "workflow_id = "+arg(filter.WorkflowID))
+	}
+	if filter.IdempotencyKey != "" {
+		conditions = append(conditions, "idempotency_key = "+arg(filter.IdempotencyKey))
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			placeholders[i] = arg(string(status))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query := `SELECT data FROM maestro_instances`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*engine.InstanceRecord, 0)
+	for rows.Next() {
+		var record engine.InstanceRecord
+		if err := scanJSON(rows, &record); err != nil {
+			return nil, err
+		}
+		result = append(result, &record)
+	}
+	return result, rows.Err()
+}
+
+// AppendHistory olayları örneğin geçmişinin sonuna tek bir işlemde ekler
+func (s *Store) AppendHistory(ctx context.Context, instanceID string, events []engine.HistoryEvent) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return appendHistory(ctx, tx, instanceID, events)
+	})
+}
+
+// appendHistory olayları son sıranın devamıysa ekler. Aynı sırayı eşzamanlı
+// ekleyen iki işlemden ikincisi birincinin bitmesini bekler ve hiçbir satır
+// ekleyemediği için ErrHistoryConflict alır.
+func appendHistory(ctx context.Context, tx *sql.Tx, instanceID string, events []engine.HistoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var last int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(seq), 0) FROM maestro_history WHERE instance_id = $1`, instanceID).Scan(&last)
+	if err != nil {
+		return err
+	}
+	if err := engine.CheckHistoryAppend(last, events); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO maestro_history (instance_id, seq, data) VALUES ($1, $2, $3)
+			ON CONFLICT (instance_id, seq) DO NOTHING`,
+			instanceID, event.Sequence, string(data))
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: %d. olay başka bir yazıcı tarafından eklendi", engine.ErrHistoryConflict, event.Sequence)
+		}
+	}
+	return nil
+}
+
+// LoadHistory örneğin geçmişini sıralı döndürür
+func (s *Store) LoadHistory(ctx context.Context, instanceID string) ([]engine.HistoryEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT data FROM maestro_history WHERE instance_id = $1 ORDER BY seq`, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]engine.HistoryEvent, 0)
+	for rows.Next() {
+		var event engine.HistoryEvent
+		if err := scanJSON(rows, &event); err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, rows.Err()
+}
+
+// eventLockID olay günlüğüne eklemeleri sıraya koyan danışma kilidinin anahtarıdır
+const eventLockID = migrationLockID + 1
+
+// AppendEvent olayı olay günlüğüne ekler. Eklemeler danışma kilidiyle sıraya
+// konduğundan sıralar boşluksuzdur ve işlenme sırasıyla görünür; bir okuyucu
+// daha küçük sıralı bir olay işlenmeden büyüğünü görmez.
+func (s *Store) AppendEvent(ctx context.Context, event engine.Event) (int64, error) {
+	event.Sequence = 0
+	data, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	var seq int64
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(eventLockID)); err != nil {
+			return err
+		}
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO maestro_events (seq, data)
+			SELECT COALESCE(MAX(seq), 0) + 1, $1 FROM maestro_events
+			RETURNING seq`, string(data)).Scan(&seq)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// LoadEvents sırası after'dan büyük olayları sıralı döndürür
+func (s *Store) LoadEvents(ctx context.Context, after int64, limit int) ([]engine.Event, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT seq, data FROM maestro_events WHERE seq > $1 ORDER BY seq LIMIT $2`,
+		after, nullableLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]engine.Event, 0)
+	for rows.Next() {
+		var seq int64
+		var data []byte
+		if err := rows.Scan(&seq, &data); err != nil {
+			return nil, err
+		}
+		var event engine.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, err
+		}
+		event.Sequence = seq
+		result = append(result, event)
+	}
+	return result, rows.Err()
+}
+
+// SaveTimer zamanlayıcıyı kaydeder; aynı ID ile yeniden kayıt üzerine yazar
+func (s *Store) SaveTimer(ctx context.Context, timer engine.Timer) error {
+	data, err := json.Marshal(timer)
+	if err != nil {
+		return err
+	}
+	// Sütun mikrosaniye hassasiyetindedir; yukarı yuvarlanan bir uyanma zamanı
+	// tam vaktinde vadesi gelmemiş görünmesin diye kesilerek yazılır
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO maestro_timers (id, instance_id, wake_at, data) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			instance_id = EXCLUDED.instance_id,
+			wake_at = EXCLUDED.wake_at,
+			data = EXCLUDED.data`,
+		timer.ID, timer.InstanceID, timer.WakeAt.Truncate(time.Microsecond), string(data))
+	return err
+}
+
+// DeleteTimer zamanlayıcıyı siler; olmayan zamanlayıcı hata değildir
+func (s *Store) DeleteTimer(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM maestro_timers WHERE id = $1`, id)
+	return err
+}
+
+// DueTimers vadesi gelmiş zamanlayıcıları uyanma zamanına göre sıralı döndürür
+func (s *Store) DueTimers(ctx context.Context, now time.Time, limit int) ([]engine.Timer, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT data FROM maestro_timers WHERE wake_at <= $1 ORDER BY wake_at, id LIMIT $2::bigint`,
+		now, nullableLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]engine.Timer, 0)
+	for rows.Next() {
+		var timer engine.Timer
+		if err := scanJSON(rows, &timer); err != nil {
+			return nil, err
+		}
+		result = append(result, timer)
+	}
+	return result, rows.Err()
+}
+
+// ClaimInstances kiralaması boşta olan çalışır örnekleri kiralar. Eşzamanlı
+// sahiplenen düğümler SKIP LOCKED sayesinde birbirini beklemeden farklı
+// örnekler alır.
+func (s *Store) ClaimInstances(ctx context.Context, owner string, lease time.Duration, limit int) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE maestro_instances
+		SET lease_owner = $1, lease_expires_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM maestro_instances
+			WHERE status = $3
+			  AND (lease_expires_at <= now()
+			       OR (lease_expires_at IS NULL AND updated_at <= now() - make_interval(secs => $2)))
+			ORDER BY created_at, id
+			LIMIT $4::bigint
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		owner, lease.Seconds(), string(engine.StatusRunning), nullableLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AcquireLease örneğin kiralamasını alır veya owner zaten sahibiyse uzatır
+func (s *Store) AcquireLease(ctx context.Context, owner, instanceID string, lease time.Duration) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE maestro_instances
+		SET lease_owner = $1, lease_expires_at = now() + make_interval(secs => $3)
+		WHERE id = $2 AND (lease_owner IS NULL OR lease_owner = $1 OR lease_expires_at <= now())`,
+		owner, instanceID, lease.Seconds())
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return engine.ErrLeaseLost
+	}
+	return nil
+}
+
+// ReleaseLease owner'ın kiralamasını bırakır
+func (s *Store) ReleaseLease(ctx context.Context, owner, instanceID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE maestro_instances SET lease_owner = NULL, lease_expires_at = now()
+		WHERE id = $2 AND lease_owner = $1`,
+		owner, instanceID)
+	return err
+}
+
+// inTx fn'i bir işlem içinde çalıştırır; fn hata döndürürse işlem geri alınır
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanner *sql.Row ve *sql.Rows'un ortak okuma arayüzüdür
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJSON tek JSON sütununu değere çözer; satır yoksa engine.ErrNotFound döner
+func scanJSON(row scanner, value interface{}) error {
+	var data []byte
+	if err := row.Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return engine.ErrNotFound
+		}
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// nullableLimit 0 limiti SQL'de sınırsız anlamına gelen NULL'a çevirir
+func nullableLimit(limit int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(limit), Valid: limit > 0}
+}
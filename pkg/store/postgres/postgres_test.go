@@ -0,0 +1,311 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/storetest"
+)
+
+// dsnEnv entegrasyon testlerinin bağlanacağı veritabanını belirten ortam değişkenidir
+const dsnEnv = "MAESTRO_POSTGRES_DSN"
+
+// openStore her test için ayrı bir şema oluşturur, göçleri uygular ve test
+// sonunda şemayı siler. Testler postgres etiketiyle derlendiğinde veritabanı
+// zorunludur; tanımlı değilse test atlanmaz, başarısız olur.
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Fatalf("%s must point to a PostgreSQL database", dsnEnv)
+	}
+	ctx := context.Background()
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatalf("rand.Read failed: %v", err)
+	}
+	schema := "maestro_test_" + hex.EncodeToString(suffix)
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("CREATE SCHEMA failed: %v", err)
+	}
+	t.Cleanup(func() { admin.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE") })
+
+	db, err := sql.Open("postgres", withSearchPath(t, dsn, schema))
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	return New(db)
+}
+
+// withSearchPath bağlantı dizesine şemayı seçen search_path parametresini ekler
+func withSearchPath(t *testing.T, dsn, schema string) string {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + schema
+	}
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", dsnEnv, err)
+	}
+	query := parsed.Query()
+	query.Set("search_path", schema)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// ageInstances örneklerin son kayıt zamanını geriye alır; böylece kiralaması hiç
+// alınmamış örnekler beklemeden sahiplenilebilir
+func ageInstances(t *testing.T, store *Store) {
+	t.Helper()
+	_, err := store.DB().Exec(`UPDATE maestro_instances SET updated_at = now() - interval '1 hour'`)
+	if err != nil {
+		t.Fatalf("Aging instances failed: %v", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) engine.WorkflowStore {
+		return openStore(t)
+	})
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+
+	// Aynı anda başlayan düğümler göçleri bir kez uygulamalı
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Migrate(ctx, store.DB())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Repeated Migrate failed: %v", err)
+		}
+	}
+
+	var applied int
+	if err := store.DB().QueryRow(`SELECT count(*) FROM maestro_schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("Counting migrations failed: %v", err)
+	}
+	migrations, _ := loadMigrations()
+	if applied != len(migrations) {
+		t.Errorf("Expected %d applied migrations, got %d", len(migrations), applied)
+	}
+}
+
+func TestConcurrentClaimsDoNotOverlap(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+
+	const instances = 40
+	for i := 0; i < instances; i++ {
+		record := &engine.InstanceRecord{
+			ID:         fmt.Sprintf("instance-%02d", i),
+			WorkflowID: "share",
+			Version:    1,
+			State:      engine.WorkflowState{Status: engine.StatusRunning},
+			CreatedAt:  time.Now(),
+		}
+		if err := store.SaveInstance(ctx, record); err != nil {
+			t.Fatalf("SaveInstance failed: %v", err)
+		}
+	}
+	ageInstances(t, store)
+
+	// Dört düğüm SKIP LOCKED sayesinde aynı örneği iki kez almadan bölüşür
+	var (
+		mutex   sync.Mutex
+		claimed = make(map[string]string)
+		wg      sync.WaitGroup
+	)
+	for _, owner := range []string{"node-a", "node-b", "node-c", "node-d"} {
+		wg.Add(1)
+		go func(owner string) {
+			defer wg.Done()
+			for {
+				ids, err := store.ClaimInstances(ctx, owner, time.Minute, 3)
+				if err != nil {
+					t.Errorf("ClaimInstances failed: %v", err)
+					return
+				}
+				if len(ids) == 0 {
+					return
+				}
+				mutex.Lock()
+				for _, id := range ids {
+					if previous, ok := claimed[id]; ok {
+						t.Errorf("%s claimed by both %s and %s", id, previous, owner)
+					}
+					claimed[id] = owner
+				}
+				mutex.Unlock()
+			}
+		}(owner)
+	}
+	wg.Wait()
+
+	if len(claimed) != instances {
+		t.Errorf("Expected %d claimed instances, got %d", instances, len(claimed))
+	}
+}
+
+func TestConcurrentAppendConflicts(t *testing.T) {
+	store := openStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// Aynı sırayı yazmaya çalışan iki yazıcıdan yalnızca biri başarılı olur
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+		conflicts atomic.Int32
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events := []engine.HistoryEvent{{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now}}
+			err := store.AppendHistory(ctx, "instance", events)
+			switch {
+			case err == nil:
+				succeeded.Add(1)
+			case errors.Is(err, engine.ErrHistoryConflict):
+				conflicts.Add(1)
+			default:
+				t.Errorf("Unexpected AppendHistory error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded.Load() != 1 || conflicts.Load() != 7 {
+		t.Errorf("Expected one winner and seven conflicts, got %d and %d", succeeded.Load(), conflicts.Load())
+	}
+}
+
+// deadNodeStore ölen bir düğümün bağlantısını taklit eder: dead işaretlendikten
+// sonra hiçbir yazma ve kiralama yenilemesi veritabanına ulaşmaz
+type deadNodeStore struct {
+	*Store
+	dead atomic.Bool
+}
+
+var errDead = errors.New("node is dead")
+
+func (s *deadNodeStore) AppendHistory(ctx context.Context, instanceID string, events []engine.HistoryEvent) error {
+	if s.dead.Load() {
+		return errDead
+	}
+	return s.Store.AppendHistory(ctx, instanceID, events)
+}
+
+func (s *deadNodeStore) SaveInstance(ctx context.Context, record *engine.InstanceRecord) error {
+	if s.dead.Load() {
+		return errDead
+	}
+	return s.Store.SaveInstance(ctx, record)
+}
+
+func (s *deadNodeStore) SaveTransition(ctx context.Context, record *engine.InstanceRecord, events []engine.HistoryEvent) error {
+	if s.dead.Load() {
+		return errDead
+	}
+	return s.Store.SaveTransition(ctx, record, events)
+}
+
+func (s *deadNodeStore) AcquireLease(ctx context.Context, owner, instanceID string, lease time.Duration) error {
+	if s.dead.Load() {
+		return errDead
+	}
+	return s.Store.AcquireLease(ctx, owner, instanceID, lease)
+}
+
+func (s *deadNodeStore) ReleaseLease(ctx context.Context, owner, instanceID string) error {
+	if s.dead.Load() {
+		return errDead
+	}
+	return s.Store.ReleaseLease(ctx, owner, instanceID)
+}
+
+func TestDeadNodeInstanceIsReclaimed(t *testing.T) {
+	shared := openStore(t)
+	ctx := context.Background()
+	lease := 300 * time.Millisecond
+
+	definition := engine.NewWorkflowDefinition("charge", "Charge", "")
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", engine.StepTypeTask).WithNextSteps("receipt"))
+	definition.AddStep(engine.NewStepDefinition("receipt", "Receipt", engine.StepTypeTask))
+
+	// İlk düğüm charge adımı sırasında ölür; kiralaması yenilenemez
+	dying := &deadNodeStore{Store: shared}
+	first := engine.NewWorkflowEngine(engine.WithStore(dying), engine.WithLeases("node-a", lease))
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	first.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		dying.dead.Store(true)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	runtime, err := first.StartWorkflow(ctx, "charge", nil)
+	if !errors.Is(err, engine.ErrLeaseLost) {
+		t.Fatalf("Dead node should lose its lease, got %v", err)
+	}
+
+	second := engine.NewWorkflowEngine(engine.WithStore(shared), engine.WithLeases("node-b", lease))
+	second.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "charged", nil
+	})
+	second.RegisterStep("receipt", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "sent", nil
+	})
+	// Bırakılamayan kiralamanın süresinin dolması beklenir
+	time.Sleep(lease)
+	claimed, err := second.ClaimRunnable(ctx, 0)
+	if err != nil || claimed != 1 {
+		t.Fatalf("Expected one reclaimed instance, got %d (%v)", claimed, err)
+	}
+
+	record, err := shared.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Reclaimed instance should complete, got %s", record.State.Status)
+	}
+}
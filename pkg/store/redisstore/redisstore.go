@@ -0,0 +1,499 @@
+// Package redisstore iş akışı durumunu Redis'te saklayan engine.WorkflowStore
+// uygulamasını içerir. Örnek durumu hash'lerde, kalıcı zamanlayıcılar uyanma
+// zamanına göre sıralı kümede, geçmiş olayları ise her örnek için bir akışta
+// (stream) tutulur.
+//
+// Anahtar düzeni (önek varsayılan olarak "maestro:"):
+//
+//	definitions                    tanım kimlikleri (küme)
+//	definition:<id>                sürüm -> tanım (hash)
+//	instance:<id>                  kayıt, durum, tanım ve idempotency anahtarı (hash)
+//	instances                      tüm örnekler, oluşturulma zamanına göre (sıralı küme)
+//	instances:status:<durum>       durum indeksi (sıralı küme)
+//	instances:workflow:<tanım>     tanım indeksi (sıralı küme)
+//	instances:key:<anahtar>        idempotency anahtarı indeksi (sıralı küme)
+//	history:<id>                   geçmiş olayları, girdi kimliği <sıra>-0 (akış)
+//	timers                         kimlik -> zamanlayıcı (hash)
+//	timers:due                     uyanma zamanına göre zamanlayıcılar (sıralı küme)
+//	events                         engine.EventLog olayları, girdi kimliği <sıra>-0 (akış)
+//
+// Kayıt ile indeksleri ve geçmiş ile kayıt Lua betikleriyle atomik olarak
+// yazılır; depo engine.TransitionStore arayüzünü uygular. Zamanlayıcı kümesi
+// zamanlayıcı adımlarının, sinyal ve geri çağrı zaman aşımlarının ve yeniden
+// deneme beklemelerinin uyanma zamanlarını tutar; bekleme sırasında çöken
+// sürecin örneği herhangi bir düğümün zamanlayıcı servisiyle devam eder.
+//
+// Betikler eski indeks anahtarlarını kayıttaki değerlerden türettiği için
+// anahtarların hepsi KEYS ile bildirilemez; depo bu yüzden Redis Cluster'ı
+// desteklemez ve tek düğümlü bir *redis.Client (doğrudan veya Sentinel
+// üzerinden) kabul eder.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix anahtarların varsayılan önekidir
+const DefaultPrefix = "maestro:"
+
+// conflictReply betiklerin geçmiş çakışmasında döndürdüğü hata önekidir
+const conflictReply = "MAESTRO_CONFLICT "
+
+// Store Redis tabanlı bir WorkflowStore uygulamasıdır
+type Store struct {
+	client *redis.Client
+	prefix string
+}
+
+// Option deponun yapılandırma seçeneğini temsil eder
+type Option func(*Store)
+
+// WithPrefix anahtar önekini belirler; aynı Redis'i paylaşan kurulumları ayırır
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// New verilen istemciyi kullanan bir depo oluşturur. İstemcinin yaşam döngüsü
+// çağırana aittir.
+func New(client *redis.Client, opts ...Option) *Store {
+	s := &Store{client: client, prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// key öneki eklenmiş anahtarı üretir
+func (s *Store) key(parts ...string) string {
+	return s.prefix + strings.Join(parts, ":")
+}
+
+// SaveDefinition tanımı ID ve sürümüyle kaydeder; aynı sürüm üzerine yazılır
+func (s *Store) SaveDefinition(ctx context.Context, definition *engine.WorkflowDefinition) error {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.key("definition", definition.ID), strconv.Itoa(definition.Version), data)
+		pipe.SAdd(ctx, s.key("definitions"), definition.ID)
+		return nil
+	})
+	return err
+}
+
+// DeleteDefinition tanımın tüm sürümlerini siler
+func (s *Store) DeleteDefinition(ctx context.Context, id string) error {
+	var deleted *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, s.key("definition", id))
+		pipe.SRem(ctx, s.key("definitions"), id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if deleted.Val() == 0 {
+		return engine.ErrNotFound
+	}
+	return nil
+}
+
+// GetDefinition tanımı döndürür; version 0 ise en güncel sürüm döner
+func (s *Store) GetDefinition(ctx context.Context, id string, version int) (*engine.WorkflowDefinition, error) {
+	key := s.key("definition", id)
+	if version == 0 {
+		versions, err := s.definitionVersions(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, engine.ErrNotFound
+		}
+		version = versions[len(versions)-1]
+	}
+
+	data, err := s.client.HGet(ctx, key, strconv.Itoa(version)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, engine.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var definition engine.WorkflowDefinition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// definitionVersions tanımın kayıtlı sürümlerini artan sırada döndürür
+func (s *Store) definitionVersions(ctx context.Context, key string) ([]int, error) {
+	fields, err := s.client.HKeys(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(fields))
+	for _, field := range fields {
+		version, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("geçersiz tanım sürümü (%s): %w", key, err)
+		}
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// ListDefinitions tüm tanımları ID ve sürüme göre sıralı döndürür
+func (s *Store) ListDefinitions(ctx context.Context) ([]*engine.WorkflowDefinition, error) {
+	ids, err := s.client.SMembers(ctx, s.key("definitions")).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	result := make([]*engine.WorkflowDefinition, 0)
+	for _, id := range ids {
+		entries, err := s.client.HGetAll(ctx, s.key("definition", id)).Result()
+		if err != nil {
+			return nil, err
+		}
+		definitions := make([]*engine.WorkflowDefinition, 0, len(entries))
+		for _, data := range entries {
+			var definition engine.WorkflowDefinition
+			if err := json.Unmarshal([]byte(data), &definition); err != nil {
+				return nil, err
+			}
+			definitions = append(definitions, &definition)
+		}
+		sort.Slice(definitions, func(i, j int) bool {
+			return definitions[i].Version < definitions[j].Version
+		})
+		result = append(result, definitions...)
+	}
+	return result, nil
+}
+
+// SaveInstance örnek kaydını saklar ve indeksleri aynı betikte günceller
+func (s *Store) SaveInstance(ctx context.Context, record *engine.InstanceRecord) error {
+	args, err := s.instanceArgs(record)
+	if err != nil {
+		return err
+	}
+	return saveInstanceScript.Run(ctx, s.client, []string{s.key("instance", record.ID)}, args...).Err()
+}
+
+// SaveTransition geçmiş olaylarını ekler ve örnek kaydını aynı betikte saklar
+func (s *Store) SaveTransition(ctx context.Context, record *engine.InstanceRecord, events []engine.HistoryEvent) error {
+	if err := engine.CheckHistoryAppend(firstSequence(events)-1, events); err != nil {
+		return err
+	}
+	args, err := s.instanceArgs(record)
+	if err != nil {
+		return err
+	}
+	args, err = appendEventArgs(args, events)
+	if err != nil {
+		return err
+	}
+	keys := []string{s.key("instance", record.ID), s.key("history", record.ID)}
+	return historyError(saveTransitionScript.Run(ctx, s.client, keys, args...).Err(), events)
+}
+
+// instanceArgs kayıt betiklerinin ortak argümanlarını hazırlar
+func (s *Store) instanceArgs(record *engine.InstanceRecord) ([]interface{}, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{
+		s.prefix,
+		record.ID,
+		data,
+		string(record.State.Status),
+		record.WorkflowID,
+		record.CreatedAt.UnixMicro(),
+		record.IdempotencyKey,
+	}, nil
+}
+
+// GetInstance örnek kaydını döndürür
+func (s *Store) GetInstance(ctx context.Context, id string) (*engine.InstanceRecord, error) {
+	data, err := s.client.HGet(ctx, s.key("instance", id), "data").Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, engine.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record engine.InstanceRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListInstances filtreye uyan örnekleri oluşturulma zamanına göre sıralı
+// döndürür. Filtrenin en seçici alanına ait indeks gezilir; kalan alanlar
+// kayıtlar okunduktan sonra uygulanır.
+func (s *Store) ListInstances(ctx context.Context, filter engine.InstanceFilter) ([]*engine.InstanceRecord, error) {
+	var indexes []string
+	switch {
+	case filter.IdempotencyKey != "":
+		indexes = []string{s.key("instances", "key", filter.IdempotencyKey)}
+	case filter.WorkflowID != "":
+		indexes = []string{s.key("instances", "workflow", filter.WorkflowID)}
+	case len(filter.Statuses) > 0:
+		seen := make(map[engine.WorkflowStatus]bool, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			if !seen[status] {
+				seen[status] = true
+				indexes = append(indexes, s.key("instances", "status", string(status)))
+			}
+		}
+	default:
+		indexes = []string{s.key("instances")}
+	}
+
+	var ids []string
+	for _, index := range indexes {
+		members, err := s.client.ZRange(ctx, index, 0, -1).Result()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, members...)
+	}
+
+	pipe := s.client.Pipeline()
+	commands := make([]*redis.StringCmd, len(ids))
+	for i, id := range ids {
+		commands[i] = pipe.HGet(ctx, s.key("instance", id), "data")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	result := make([]*engine.InstanceRecord, 0, len(ids))
+	for _, command := range commands {
+		data, err := command.Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var record engine.InstanceRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, err
+		}
+		if filter.Match(&record) {
+			result = append(result, &record)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// AppendHistory olayları örneğin geçmiş akışının sonuna tek bir betikte ekler
+func (s *Store) AppendHistory(ctx context.Context, instanceID string, events []engine.HistoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := engine.CheckHistoryAppend(firstSequence(events)-1, events); err != nil {
+		return err
+	}
+	args, err := appendEventArgs([]interface{}{s.prefix}, events)
+	if err != nil {
+		return err
+	}
+	err = appendHistoryScript.Run(ctx, s.client, []string{s.key("history", instanceID)}, args...).Err()
+	return historyError(err, events)
+}
+
+// appendEventArgs olay sayısını ve her olayın sıra numarasıyla verisini ekler
+func appendEventArgs(args []interface{}, events []engine.HistoryEvent) ([]interface{}, error) {
+	args = append(args, len(events))
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, event.Sequence, data)
+	}
+	return args, nil
+}
+
+// firstSequence eklenecek ilk olayın sıra numarasını döndürür
+func firstSequence(events []engine.HistoryEvent) int64 {
+	if len(events) == 0 {
+		return 1
+	}
+	return events[0].Sequence
+}
+
+// historyError betiğin çakışma yanıtını engine.ErrHistoryConflict'e çevirir
+func historyError(err error, events []engine.HistoryEvent) error {
+	if err == nil || !strings.HasPrefix(err.Error(), conflictReply) {
+		return err
+	}
+	last, parseErr := strconv.ParseInt(strings.TrimPrefix(err.Error(), conflictReply), 10, 64)
+	if parseErr != nil {
+		return err
+	}
+	return engine.CheckHistoryAppend(last, events)
+}
+
+// LoadHistory örneğin geçmişini sıralı döndürür
+func (s *Store) LoadHistory(ctx context.Context, instanceID string) ([]engine.HistoryEvent, error) {
+	messages, err := s.client.XRange(ctx, s.key("history", instanceID), "-", "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]engine.HistoryEvent, 0, len(messages))
+	for _, message := range messages {
+		data, ok := message.Values["data"].(string)
+		if !ok {
+			return nil, fmt.Errorf("geçersiz geçmiş girdisi: %s", message.ID)
+		}
+		var event engine.HistoryEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+// AppendEvent olayı olay günlüğü akışına ekler. Sıra betikte verilir ve girdi
+// kimliğinde saklanır; birden çok süreç aynı günlüğe güvenle yazabilir.
+func (s *Store) AppendEvent(ctx context.Context, event engine.Event) (int64, error) {
+	event.Sequence = 0
+	data, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	return appendEventScript.Run(ctx, s.client, []string{s.key("events")}, data).Int64()
+}
+
+// LoadEvents sırası after'dan büyük olayları sıralı döndürür
+func (s *Store) LoadEvents(ctx context.Context, after int64, limit int) ([]engine.Event, error) {
+	if after < 0 {
+		after = 0
+	}
+	start := strconv.FormatInt(after+1, 10) + "-0"
+	var messages []redis.XMessage
+	var err error
+	if limit > 0 {
+		messages, err = s.client.XRangeN(ctx, s.key("events"), start, "+", int64(limit)).Result()
+	} else {
+		messages, err = s.client.XRange(ctx, s.key("events"), start, "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]engine.Event, 0, len(messages))
+	for _, message := range messages {
+		data, ok := message.Values["data"].(string)
+		if !ok {
+			return nil, fmt.Errorf("geçersiz olay girdisi: %s", message.ID)
+		}
+		var event engine.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, err
+		}
+		seq, _, _ := strings.Cut(message.ID, "-")
+		if event.Sequence, err = strconv.ParseInt(seq, 10, 64); err != nil {
+			return nil, fmt.Errorf("geçersiz olay girdisi: %s", message.ID)
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+// SaveTimer zamanlayıcıyı kaydeder; aynı ID ile yeniden kayıt üzerine yazar
+func (s *Store) SaveTimer(ctx context.Context, timer engine.Timer) error {
+	data, err := json.Marshal(timer)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.key("timers"), timer.ID, data)
+		pipe.ZAdd(ctx, s.key("timers", "due"), redis.Z{Score: wakeScore(timer.WakeAt), Member: timer.ID})
+		return nil
+	})
+	return err
+}
+
+// DeleteTimer zamanlayıcıyı siler; olmayan zamanlayıcı hata değildir
+func (s *Store) DeleteTimer(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, s.key("timers"), id)
+		pipe.ZRem(ctx, s.key("timers", "due"), id)
+		return nil
+	})
+	return err
+}
+
+// DueTimers vadesi gelmiş zamanlayıcıları uyanma zamanına göre sıralı döndürür
+func (s *Store) DueTimers(ctx context.Context, now time.Time, limit int) ([]engine.Timer, error) {
+	ids, err := s.client.ZRangeByScore(ctx, s.key("timers", "due"), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMicro(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []engine.Timer{}, nil
+	}
+
+	values, err := s.client.HMGet(ctx, s.key("timers"), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]engine.Timer, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var timer engine.Timer
+		if err := json.Unmarshal([]byte(data), &timer); err != nil {
+			return nil, err
+		}
+		result = append(result, timer)
+	}
+	return result, nil
+}
+
+// wakeScore uyanma zamanını mikrosaniye puanına yukarı yuvarlar; böylece puanı
+// now'a eşit olan bir zamanlayıcının vadesi gerçekten gelmiş olur
+func wakeScore(wakeAt time.Time) float64 {
+	score := wakeAt.UnixMicro()
+	if wakeAt.Sub(time.UnixMicro(score)) > 0 {
+		score++
+	}
+	return float64(score)
+}
@@ -0,0 +1,220 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/parevo-lab/maestro/pkg/engine"
+	"github.com/parevo-lab/maestro/pkg/store/storetest"
+	"github.com/redis/go-redis/v9"
+)
+
+// newClient süreç içi bir Redis sunucusuna bağlı istemci döndürür
+func newClient(t *testing.T, server *miniredis.Miniredis) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) engine.WorkflowStore {
+		return New(newClient(t, miniredis.RunT(t)))
+	})
+}
+
+func TestTimerSurvivesProcessRestart(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	clock := engine.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	definition := engine.NewWorkflowDefinition("reminder", "Reminder", "")
+	definition.AddStep(engine.NewStepDefinition("wait", "Wait", engine.StepTypeTimer).
+		WithConfig(map[string]interface{}{engine.TimerConfigDuration: "72h"}).
+		WithNextSteps("remind"))
+	definition.AddStep(engine.NewStepDefinition("remind", "Remind", engine.StepTypeTask))
+
+	// İlk süreç bağlantısını kapatarak sona erer
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	first := engine.NewWorkflowEngine(engine.WithStore(New(client)), engine.WithClock(clock))
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := engine.NewWorkflowRuntime(first, definition)
+	if err := runtime.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	client.Close()
+
+	store := New(newClient(t, server))
+	second := engine.NewWorkflowEngine(engine.WithStore(store), engine.WithClock(clock))
+	reminded := false
+	second.RegisterStep("remind", func(ctx context.Context, data interface{}) (interface{}, error) {
+		reminded = true
+		return nil, nil
+	})
+
+	recovered, err := second.Recover(ctx)
+	if err != nil || len(recovered) != 1 {
+		t.Fatalf("Expected one recovered instance, got %d (%v)", len(recovered), err)
+	}
+
+	// Vadesi gelmeden zamanlayıcı kuyruktan çıkmamalı
+	clock.Advance(71 * time.Hour)
+	if fired, err := second.FireDueTimers(ctx); err != nil || fired != 0 {
+		t.Fatalf("Timer should not fire early, got %d (%v)", fired, err)
+	}
+	clock.Advance(time.Hour)
+	if _, err := second.FireDueTimers(ctx); err != nil {
+		t.Fatalf("FireDueTimers failed: %v", err)
+	}
+	if !reminded {
+		t.Error("Remind step should run after restart")
+	}
+
+	record, err := store.GetInstance(ctx, runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed instance, got %s", record.State.Status)
+	}
+	if server.Exists(DefaultPrefix + "timers:due") {
+		t.Error("Fired timer should leave the due queue")
+	}
+}
+
+func TestRetryBackoffSurvivesProcessRestart(t *testing.T) {
+	server := miniredis.RunT(t)
+	clock := engine.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	definition := engine.NewWorkflowDefinition("charge", "Charge", "")
+	definition.AddStep(engine.NewStepDefinition("charge", "Charge", engine.StepTypeTask).
+		WithRetryPolicy(2, time.Minute, time.Minute, 1))
+
+	// İlk süreç yeniden deneme beklemesindeyken sona erer
+	ctx, crash := context.WithCancel(context.Background())
+	first := engine.NewWorkflowEngine(engine.WithStore(New(newClient(t, server))), engine.WithClock(clock))
+	first.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return nil, errors.New("gateway unavailable")
+	})
+	if err := first.RegisterDefinition(ctx, definition); err != nil {
+		t.Fatalf("RegisterDefinition failed: %v", err)
+	}
+	runtime := engine.NewWorkflowRuntime(first, definition)
+	done := make(chan error, 1)
+	go func() {
+		done <- runtime.Start(ctx)
+	}()
+
+	// Uyanma zamanı Redis'e yazıldıktan sonra süreç durdurulur
+	for deadline := time.Now().Add(time.Second); !server.Exists(DefaultPrefix + "timers:due"); {
+		if time.Now().After(deadline) {
+			t.Fatal("Retry wake-up should be queued in Redis")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	crash()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the backoff to be interrupted, got %v", err)
+	}
+
+	store := New(newClient(t, server))
+	second := engine.NewWorkflowEngine(engine.WithStore(store), engine.WithClock(clock))
+	second.RegisterStep("charge", func(ctx context.Context, data interface{}) (interface{}, error) {
+		return "charged", nil
+	})
+	clock.Advance(time.Minute)
+	if fired, err := second.FireDueTimers(context.Background()); err != nil || fired != 1 {
+		t.Fatalf("Expected the retry timer to fire, got %d (%v)", fired, err)
+	}
+
+	record, err := store.GetInstance(context.Background(), runtime.ID())
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if record.State.Status != engine.StatusCompleted {
+		t.Errorf("Expected completed instance, got %s", record.State.Status)
+	}
+	if server.Exists(DefaultPrefix + "timers:due") {
+		t.Error("Fired retry timer should leave the due queue")
+	}
+}
+
+func TestHistoryIsStoredAsStream(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	store := New(newClient(t, server))
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []engine.HistoryEvent{
+		{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now},
+		{Sequence: 2, Type: engine.HistoryStepScheduled, StepID: "fetch", Timestamp: now},
+	}
+	if err := store.AppendHistory(ctx, "instance", events); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	// Akış girdilerinin kimliği sıra numarasıdır; tüketiciler XREAD ile izleyebilir
+	stream, err := server.Stream(DefaultPrefix + "history:instance")
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if len(stream) != 2 || stream[0].ID != "1-0" || stream[1].ID != "2-0" {
+		t.Errorf("Unexpected stream entries: %+v", stream)
+	}
+
+	// Eski bir yazıcının eklemesi çakışma olarak reddedilir ve akışı değiştirmez
+	stale := []engine.HistoryEvent{{Sequence: 2, Type: engine.HistoryWorkflowCanceled, Timestamp: now}}
+	if err := store.AppendHistory(ctx, "instance", stale); !errors.Is(err, engine.ErrHistoryConflict) {
+		t.Errorf("Expected ErrHistoryConflict, got %v", err)
+	}
+	if stream, _ := server.Stream(DefaultPrefix + "history:instance"); len(stream) != 2 {
+		t.Errorf("Conflicting append should not write, got %d entries", len(stream))
+	}
+}
+
+func TestStatusIndexFollowsTransitions(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	store := New(newClient(t, server))
+
+	record := &engine.InstanceRecord{ID: "instance", WorkflowID: "share", State: engine.WorkflowState{Status: engine.StatusRunning}, CreatedAt: time.Now()}
+	if err := store.SaveInstance(ctx, record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+	record.State.Status = engine.StatusCompleted
+	events := []engine.HistoryEvent{{Sequence: 1, Type: engine.HistoryWorkflowCompleted, Timestamp: time.Now()}}
+	if err := store.SaveTransition(ctx, record, events); err != nil {
+		t.Fatalf("SaveTransition failed: %v", err)
+	}
+
+	// Eski durum indeksinde girdi kalmamalı
+	if server.Exists(DefaultPrefix + "instances:status:running") {
+		t.Error("Running index should be empty after completion")
+	}
+	if members, _ := server.ZMembers(DefaultPrefix + "instances:status:completed"); len(members) != 1 {
+		t.Errorf("Expected one completed instance, got %v", members)
+	}
+}
+
+func TestPrefixIsolatesStores(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t, miniredis.RunT(t))
+	staging := New(client, WithPrefix("staging:"))
+	production := New(client)
+
+	record := &engine.InstanceRecord{ID: "instance", WorkflowID: "share", State: engine.WorkflowState{Status: engine.StatusRunning}, CreatedAt: time.Now()}
+	if err := staging.SaveInstance(ctx, record); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+	if _, err := production.GetInstance(ctx, "instance"); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Stores with different prefixes should not share records, got %v", err)
+	}
+	if records, _ := production.ListInstances(ctx, engine.InstanceFilter{}); len(records) != 0 {
+		t.Errorf("Expected no instances under the default prefix, got %d", len(records))
+	}
+}
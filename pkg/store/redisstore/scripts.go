@@ -0,0 +1,97 @@
+package redisstore
+
+import "github.com/redis/go-redis/v9"
+
+// Betikler örnek kaydını indeksleriyle ve geçmiş olaylarını kayıtla birlikte
+// atomik olarak yazar. Redis bir betiği yarıda geri almadığından tüm denetimler
+// ilk yazmadan önce yapılır.
+//
+// Argüman düzeni:
+//
+//	saveInstance    KEYS: örnek hash'i                ARGV: önek, kayıt alanları
+//	appendHistory   KEYS: geçmiş akışı                ARGV: önek, olay sayısı, olaylar
+//	saveTransition  KEYS: örnek hash'i, geçmiş akışı  ARGV: önek, kayıt alanları, olay sayısı, olaylar
+//	appendEvent     KEYS: olay günlüğü akışı          ARGV: JSON olay
+//
+// Kayıt alanları sırasıyla kimlik, JSON kayıt, durum, tanım, oluşturulma puanı
+// ve idempotency anahtarıdır; her olay sıra numarası ve JSON veriyle geçirilir.
+// Akış girdilerinin kimliği <sıra>-0 olduğundan eski bir sırayla XADD de reddedilir.
+const scriptFunctions = `
+local function check_history(stream, first)
+  local last = 0
+  local tail = redis.call('XREVRANGE', stream, '+', '-', 'COUNT', 1)
+  if #tail > 0 then
+    last = tonumber(string.match(tail[1][1], '^(%d+)'))
+  end
+  if first ~= last + 1 then
+    return redis.error_reply('MAESTRO_CONFLICT ' .. last)
+  end
+  return nil
+end
+
+local function append_history(stream, offset, count)
+  for i = 0, count - 1 do
+    local seq = ARGV[offset + i * 2]
+    redis.call('XADD', stream, seq .. '-0', 'data', ARGV[offset + i * 2 + 1])
+  end
+end
+
+local function save_instance(key, prefix)
+  local id, data, status, workflow, score, ikey = ARGV[2], ARGV[3], ARGV[4], ARGV[5], ARGV[6], ARGV[7]
+  local old = redis.call('HMGET', key, 'status', 'workflow_id', 'idempotency_key')
+  if old[1] and old[1] ~= status then
+    redis.call('ZREM', prefix .. 'instances:status:' .. old[1], id)
+  end
+  if old[2] and old[2] ~= workflow then
+    redis.call('ZREM', prefix .. 'instances:workflow:' .. old[2], id)
+  end
+  if old[3] and old[3] ~= '' and old[3] ~= ikey then
+    redis.call('ZREM', prefix .. 'instances:key:' .. old[3], id)
+  end
+  redis.call('HSET', key, 'data', data, 'status', status, 'workflow_id', workflow, 'idempotency_key', ikey)
+  redis.call('ZADD', prefix .. 'instances', score, id)
+  redis.call('ZADD', prefix .. 'instances:status:' .. status, score, id)
+  redis.call('ZADD', prefix .. 'instances:workflow:' .. workflow, score, id)
+  if ikey ~= '' then
+    redis.call('ZADD', prefix .. 'instances:key:' .. ikey, score, id)
+  end
+end
+`
+
+// saveInstanceScript kaydı yazar ve eski indeks girdilerini yenileriyle değiştirir
+var saveInstanceScript = redis.NewScript(scriptFunctions + `
+save_instance(KEYS[1], ARGV[1])
+return 1
+`)
+
+// appendHistoryScript olayları son sıranın devamıysa akışa ekler
+var appendHistoryScript = redis.NewScript(scriptFunctions + `
+local count = tonumber(ARGV[2])
+local err = check_history(KEYS[1], tonumber(ARGV[3]))
+if err then return err end
+append_history(KEYS[1], 3, count)
+return 1
+`)
+
+// saveTransitionScript olayları ekler ve kaydı aynı betikte yazar
+var saveTransitionScript = redis.NewScript(scriptFunctions + `
+local count = tonumber(ARGV[8])
+if count > 0 then
+  local err = check_history(KEYS[2], tonumber(ARGV[9]))
+  if err then return err end
+  append_history(KEYS[2], 9, count)
+end
+save_instance(KEYS[1], ARGV[1])
+return 1
+`)
+
+// appendEventScript olayı günlüğe son sıranın bir fazlasıyla ekler ve sırayı döndürür
+var appendEventScript = redis.NewScript(`
+local last = 0
+local tail = redis.call('XREVRANGE', KEYS[1], '+', '-', 'COUNT', 1)
+if #tail > 0 then
+  last = tonumber(string.match(tail[1][1], '^(%d+)'))
+end
+redis.call('XADD', KEYS[1], (last + 1) .. '-0', 'data', ARGV[1])
+return last + 1
+`)
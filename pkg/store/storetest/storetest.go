@@ -0,0 +1,395 @@
+// Package storetest engine.WorkflowStore uygulamalarının ortak davranışını
+// doğrulayan uyumluluk testlerini içerir. Her depo paketi kendi testinden Run'ı
+// çağırarak aynı sözleşmeye uyduğunu gösterir.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parevo-lab/maestro/pkg/engine"
+)
+
+// Factory her alt test için boş bir depo döndürür
+type Factory func(t *testing.T) engine.WorkflowStore
+
+// Run tüm uyumluluk testlerini alt test olarak çalıştırır
+func Run(t *testing.T, newStore Factory) {
+	t.Run("Definitions", func(t *testing.T) { testDefinitions(t, newStore(t)) })
+	t.Run("Instances", func(t *testing.T) { testInstances(t, newStore(t)) })
+	t.Run("History", func(t *testing.T) { testHistory(t, newStore(t)) })
+	t.Run("Timers", func(t *testing.T) { testTimers(t, newStore(t)) })
+	t.Run("Transitions", func(t *testing.T) {
+		store, ok := newStore(t).(engine.TransitionStore)
+		if !ok {
+			t.Skip("store does not implement TransitionStore")
+		}
+		testTransitions(t, store)
+	})
+	t.Run("Leases", func(t *testing.T) {
+		store := newStore(t)
+		if _, ok := store.(engine.LeaseStore); !ok {
+			t.Skip("store does not implement LeaseStore")
+		}
+		testLeases(t, store)
+	})
+	t.Run("Events", func(t *testing.T) {
+		store, ok := newStore(t).(engine.EventLog)
+		if !ok {
+			t.Skip("store does not implement EventLog")
+		}
+		testEvents(t, store)
+	})
+}
+
+func testDefinitions(t *testing.T, store engine.WorkflowStore) {
+	ctx := context.Background()
+	if _, err := store.GetDefinition(ctx, "missing", 0); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing definition, got %v", err)
+	}
+
+	for _, version := range []int{1, 2, 10} {
+		definition := engine.NewWorkflowDefinition("share", "Share", "")
+		definition.Version = version
+		definition.AddStep(engine.NewStepDefinition("fetch", "Fetch", engine.StepTypeTask))
+		if err := store.SaveDefinition(ctx, definition); err != nil {
+			t.Fatalf("SaveDefinition failed: %v", err)
+		}
+	}
+	other := engine.NewWorkflowDefinition("archive", "Archive", "")
+	if err := store.SaveDefinition(ctx, other); err != nil {
+		t.Fatalf("SaveDefinition failed: %v", err)
+	}
+
+	latest, err := store.GetDefinition(ctx, "share", 0)
+	if err != nil || latest.Version != 10 || len(latest.Steps) != 1 {
+		t.Errorf("Expected latest version 10 with one step, got %+v (%v)", latest, err)
+	}
+	second, err := store.GetDefinition(ctx, "share", 2)
+	if err != nil || second.Version != 2 {
+		t.Errorf("Expected version 2, got %+v (%v)", second, err)
+	}
+	if _, err := store.GetDefinition(ctx, "share", 3); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing version, got %v", err)
+	}
+
+	all, err := store.ListDefinitions(ctx)
+	if err != nil || len(all) != 4 {
+		t.Fatalf("Expected four definition versions, got %d (%v)", len(all), err)
+	}
+	if all[0].ID != "archive" || all[1].Version != 1 || all[3].Version != 10 {
+		t.Errorf("Definitions should be sorted by ID and version, got %s/%d first", all[0].ID, all[0].Version)
+	}
+
+	// Silme tanımın tüm sürümlerini kaldırır
+	if err := store.DeleteDefinition(ctx, "share"); err != nil {
+		t.Fatalf("DeleteDefinition failed: %v", err)
+	}
+	if _, err := store.GetDefinition(ctx, "share", 2); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if all, err := store.ListDefinitions(ctx); err != nil || len(all) != 1 || all[0].ID != "archive" {
+		t.Errorf("Expected only the archive definition after delete, got %d (%v)", len(all), err)
+	}
+	if err := store.DeleteDefinition(ctx, "share"); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted definition, got %v", err)
+	}
+}
+
+func testInstances(t *testing.T, store engine.WorkflowStore) {
+	ctx := context.Background()
+	if _, err := store.GetInstance(ctx, "missing"); !errors.Is(err, engine.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing instance, got %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	records := []*engine.InstanceRecord{
+		{ID: "b", WorkflowID: "share", Version: 1, State: engine.WorkflowState{Status: engine.StatusRunning}, CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "a", WorkflowID: "share", Version: 1, IdempotencyKey: "req-1", State: engine.WorkflowState{Status: engine.StatusWaiting}, CreatedAt: base.Add(time.Minute)},
+		{ID: "c", WorkflowID: "archive", Version: 1, State: engine.WorkflowState{Status: engine.StatusRunning, Context: map[string]interface{}{"user": "jane"}}, CreatedAt: base},
+	}
+	for _, record := range records {
+		if err := store.SaveInstance(ctx, record); err != nil {
+			t.Fatalf("SaveInstance failed: %v", err)
+		}
+	}
+
+	loaded, err := store.GetInstance(ctx, "c")
+	if err != nil || loaded.State.Context["user"] != "jane" {
+		t.Errorf("Instance should round-trip, got %+v (%v)", loaded, err)
+	}
+
+	expectIDs(t, store, engine.InstanceFilter{}, "c", "a", "b")
+	expectIDs(t, store, engine.InstanceFilter{WorkflowID: "share"}, "a", "b")
+	expectIDs(t, store, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusRunning}}, "c", "b")
+	expectIDs(t, store, engine.InstanceFilter{WorkflowID: "share", Statuses: []engine.WorkflowStatus{engine.StatusRunning}}, "b")
+	expectIDs(t, store, engine.InstanceFilter{WorkflowID: "share", IdempotencyKey: "req-1"}, "a")
+
+	// Durum değişikliği filtreleri güncellemeli
+	records[0].State.Status = engine.StatusCompleted
+	if err := store.SaveInstance(ctx, records[0]); err != nil {
+		t.Fatalf("SaveInstance failed: %v", err)
+	}
+	expectIDs(t, store, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusRunning}}, "c")
+	expectIDs(t, store, engine.InstanceFilter{Statuses: []engine.WorkflowStatus{engine.StatusCompleted, engine.StatusWaiting}}, "a", "b")
+}
+
+// expectIDs filtrenin verilen örnekleri bu sırayla döndürdüğünü doğrular
+func expectIDs(t *testing.T, store engine.WorkflowStore, filter engine.InstanceFilter, ids ...string) {
+	t.Helper()
+	records, err := store.ListInstances(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("ListInstances failed: %v", err)
+	}
+	actual := make([]string, len(records))
+	for i, record := range records {
+		actual[i] = record.ID
+	}
+	if len(actual) != len(ids) {
+		t.Errorf("Filter %+v: expected %v, got %v", filter, ids, actual)
+		return
+	}
+	for i := range ids {
+		if actual[i] != ids[i] {
+			t.Errorf("Filter %+v: expected %v, got %v", filter, ids, actual)
+			return
+		}
+	}
+}
+
+func testHistory(t *testing.T, store engine.WorkflowStore) {
+	ctx := context.Background()
+	empty, err := store.LoadHistory(ctx, "missing")
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Missing history should be empty, got %d (%v)", len(empty), err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []engine.HistoryEvent{
+		{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now, Context: map[string]interface{}{"user": "ada"}},
+		{Sequence: 2, Type: engine.HistoryStepScheduled, StepID: "fetch", Timestamp: now},
+	}
+	if err := store.AppendHistory(ctx, "instance", events); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	// Sırası devam etmeyen eklemeler hiçbir olay yazmadan reddedilir
+	stale := []engine.HistoryEvent{
+		{Sequence: 3, Type: engine.HistoryStepStarted, StepID: "fetch", Attempt: 1, Timestamp: now},
+		{Sequence: 5, Type: engine.HistoryWorkflowCanceled, Timestamp: now},
+	}
+	if err := store.AppendHistory(ctx, "instance", stale); !errors.Is(err, engine.ErrHistoryConflict) {
+		t.Errorf("Expected ErrHistoryConflict, got %v", err)
+	}
+	next := []engine.HistoryEvent{{Sequence: 3, Type: engine.HistoryStepCompleted, StepID: "fetch", Result: "ok", Timestamp: now}}
+	if err := store.AppendHistory(ctx, "instance", next); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+	if err := store.AppendHistory(ctx, "other", events[:1]); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	history, err := store.LoadHistory(ctx, "instance")
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if len(history) != 3 || history[2].Result != "ok" || history[0].Context["user"] != "ada" {
+		t.Fatalf("Unexpected history: %+v", history)
+	}
+	for i, event := range history {
+		if event.Sequence != int64(i+1) {
+			t.Errorf("History should be ordered, got sequence %d at %d", event.Sequence, i)
+		}
+	}
+}
+
+func testEvents(t *testing.T, store engine.EventLog) {
+	ctx := context.Background()
+	empty, err := store.LoadEvents(ctx, 0, 0)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Empty log should have no events, got %d (%v)", len(empty), err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, stepID := range []string{"fetch", "parse", "store"} {
+		// Verilen sıra yok sayılır; günlük kendi sırasını verir
+		event := engine.Event{
+			Type:       engine.EventStepComplete,
+			InstanceID: "instance",
+			WorkflowID: "share",
+			StepID:     stepID,
+			Data:       map[string]interface{}{"step": stepID},
+			Timestamp:  now,
+			Sequence:   99,
+		}
+		seq, err := store.AppendEvent(ctx, event)
+		if err != nil {
+			t.Fatalf("AppendEvent failed: %v", err)
+		}
+		if seq != int64(i+1) {
+			t.Errorf("Expected sequence %d, got %d", i+1, seq)
+		}
+	}
+
+	all, err := store.LoadEvents(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("LoadEvents failed: %v", err)
+	}
+	if len(all) != 3 || all[0].StepID != "fetch" || all[0].WorkflowID != "share" || !all[0].Timestamp.Equal(now) {
+		t.Fatalf("Unexpected events: %+v", all)
+	}
+	if data, ok := all[0].Data.(map[string]interface{}); !ok || data["step"] != "fetch" {
+		t.Errorf("Unexpected event data: %v", all[0].Data)
+	}
+	for i, event := range all {
+		if event.Sequence != int64(i+1) {
+			t.Errorf("Events should be ordered, got sequence %d at %d", event.Sequence, i)
+		}
+	}
+
+	page, err := store.LoadEvents(ctx, 1, 1)
+	if err != nil || len(page) != 1 || page[0].Sequence != 2 || page[0].StepID != "parse" {
+		t.Errorf("Expected only event 2 after 1, got %+v (%v)", page, err)
+	}
+	rest, err := store.LoadEvents(ctx, 3, 0)
+	if err != nil || len(rest) != 0 {
+		t.Errorf("Expected no events after the last one, got %d (%v)", len(rest), err)
+	}
+}
+
+func testTimers(t *testing.T, store engine.WorkflowStore) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	timers := []engine.Timer{
+		{ID: "late", InstanceID: "i1", StepID: "wait", Kind: engine.TimerKindSleep, WakeAt: now.Add(time.Hour)},
+		{ID: "second", InstanceID: "i2", StepID: "wait", Kind: engine.TimerKindSleep, WakeAt: now.Add(-time.Minute)},
+		{ID: "first", InstanceID: "i3", StepID: "wait", Kind: engine.TimerKindSignalTimeout, WakeAt: now.Add(-time.Hour)},
+	}
+	for _, timer := range timers {
+		if err := store.SaveTimer(ctx, timer); err != nil {
+			t.Fatalf("SaveTimer failed: %v", err)
+		}
+	}
+
+	due, err := store.DueTimers(ctx, now, 0)
+	if err != nil || len(due) != 2 || due[0].ID != "first" || due[1].ID != "second" {
+		t.Fatalf("Expected first and second to be due, got %+v (%v)", due, err)
+	}
+	if due[0].Kind != engine.TimerKindSignalTimeout || !due[0].WakeAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Timer should round-trip, got %+v", due[0])
+	}
+	limited, err := store.DueTimers(ctx, now, 1)
+	if err != nil || len(limited) != 1 || limited[0].ID != "first" {
+		t.Errorf("Limit should return the earliest timer, got %+v (%v)", limited, err)
+	}
+
+	// Aynı kimlikle yeniden kayıt uyanma zamanını değiştirir
+	timers[0].WakeAt = now.Add(-30 * time.Minute)
+	if err := store.SaveTimer(ctx, timers[0]); err != nil {
+		t.Fatalf("SaveTimer failed: %v", err)
+	}
+	if err := store.DeleteTimer(ctx, "first"); err != nil {
+		t.Fatalf("DeleteTimer failed: %v", err)
+	}
+	if err := store.DeleteTimer(ctx, "missing"); err != nil {
+		t.Errorf("Deleting a missing timer should not fail, got %v", err)
+	}
+
+	due, err = store.DueTimers(ctx, now, 0)
+	if err != nil || len(due) != 2 || due[0].ID != "late" || due[1].ID != "second" {
+		t.Errorf("Expected late and second to be due, got %+v (%v)", due, err)
+	}
+}
+
+func testTransitions(t *testing.T, store engine.TransitionStore) {
+	ctx := context.Background()
+	workflowStore := store.(engine.WorkflowStore)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := &engine.InstanceRecord{ID: "instance", WorkflowID: "share", Version: 1, State: engine.WorkflowState{Status: engine.StatusRunning}, CreatedAt: now}
+	started := []engine.HistoryEvent{{Sequence: 1, Type: engine.HistoryWorkflowStarted, Timestamp: now}}
+	if err := store.SaveTransition(ctx, record, started); err != nil {
+		t.Fatalf("SaveTransition failed: %v", err)
+	}
+
+	// Çakışan geçiş ne olayları ne de kaydı yazmalı
+	record.State.Status = engine.StatusCompleted
+	stale := []engine.HistoryEvent{{Sequence: 1, Type: engine.HistoryWorkflowCompleted, Timestamp: now}}
+	if err := store.SaveTransition(ctx, record, stale); !errors.Is(err, engine.ErrHistoryConflict) {
+		t.Fatalf("Expected ErrHistoryConflict, got %v", err)
+	}
+	loaded, err := workflowStore.GetInstance(ctx, "instance")
+	if err != nil || loaded.State.Status != engine.StatusRunning {
+		t.Errorf("Conflicting transition must not save the record, got %+v (%v)", loaded, err)
+	}
+
+	completed := []engine.HistoryEvent{{Sequence: 2, Type: engine.HistoryWorkflowCompleted, Timestamp: now}}
+	if err := store.SaveTransition(ctx, record, completed); err != nil {
+		t.Fatalf("SaveTransition failed: %v", err)
+	}
+	loaded, err = workflowStore.GetInstance(ctx, "instance")
+	if err != nil || loaded.State.Status != engine.StatusCompleted {
+		t.Errorf("Transition should save the record, got %+v (%v)", loaded, err)
+	}
+	history, err := workflowStore.LoadHistory(ctx, "instance")
+	if err != nil || len(history) != 2 {
+		t.Errorf("Expected two history events, got %d (%v)", len(history), err)
+	}
+}
+
+func testLeases(t *testing.T, store engine.WorkflowStore) {
+	ctx := context.Background()
+	leases := store.(engine.LeaseStore)
+	const lease = 200 * time.Millisecond
+
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	records := []*engine.InstanceRecord{
+		{ID: "first", WorkflowID: "share", Version: 1, State: engine.WorkflowState{Status: engine.StatusRunning}, CreatedAt: base},
+		{ID: "second", WorkflowID: "share", Version: 1, State: engine.WorkflowState{Status: engine.StatusRunning}, CreatedAt: base.Add(time.Minute)},
+		{ID: "done", WorkflowID: "share", Version: 1, State: engine.WorkflowState{Status: engine.StatusCompleted}, CreatedAt: base},
+	}
+	for _, record := range records {
+		if err := store.SaveInstance(ctx, record); err != nil {
+			t.Fatalf("SaveInstance failed: %v", err)
+		}
+	}
+
+	// Yeni kaydedilen örnekler kaydeden düğüme bir kiralama süresi kadar bırakılır
+	if ids, err := leases.ClaimInstances(ctx, "node-a", lease, 0); err != nil || len(ids) != 0 {
+		t.Errorf("Freshly saved instances should not be claimable, got %v (%v)", ids, err)
+	}
+	time.Sleep(lease + 50*time.Millisecond)
+
+	ids, err := leases.ClaimInstances(ctx, "node-a", lease, 1)
+	if err != nil || len(ids) != 1 || ids[0] != "first" {
+		t.Fatalf("Expected node-a to claim the oldest instance, got %v (%v)", ids, err)
+	}
+	ids, err = leases.ClaimInstances(ctx, "node-b", lease, 0)
+	if err != nil || len(ids) != 1 || ids[0] != "second" {
+		t.Fatalf("Expected node-b to claim only the remaining instance, got %v (%v)", ids, err)
+	}
+
+	if err := leases.AcquireLease(ctx, "node-b", "first", lease); !errors.Is(err, engine.ErrLeaseLost) {
+		t.Errorf("Expected ErrLeaseLost for another owner's lease, got %v", err)
+	}
+	if err := leases.AcquireLease(ctx, "node-a", "first", lease); err != nil {
+		t.Errorf("Owner should renew its lease, got %v", err)
+	}
+
+	// Bırakılan kiralama hemen sahiplenilebilir
+	if err := leases.ReleaseLease(ctx, "node-a", "first"); err != nil {
+		t.Fatalf("ReleaseLease failed: %v", err)
+	}
+	ids, err = leases.ClaimInstances(ctx, "node-b", lease, 0)
+	if err != nil || len(ids) != 1 || ids[0] != "first" {
+		t.Errorf("Expected node-b to claim the released instance, got %v (%v)", ids, err)
+	}
+
+	// Süresi dolan kiralama başka bir düğüme geçer
+	time.Sleep(lease + 50*time.Millisecond)
+	ids, err = leases.ClaimInstances(ctx, "node-a", lease, 0)
+	if err != nil || len(ids) != 2 {
+		t.Errorf("Expected expired leases to be reclaimed, got %v (%v)", ids, err)
+	}
+}